@@ -3,20 +3,29 @@ package main
 import (
 	"context"
 	"fmt"
+	goredis "github.com/redis/go-redis/v9"
+	kafkago "github.com/segmentio/kafka-go"
 	"go.uber.org/zap"
 	"net/http"
 	"order_service/internal/api"
 	"order_service/internal/config"
 	"order_service/internal/handlers/httphandlers"
 	"order_service/internal/models"
+	"order_service/internal/ports"
 	"order_service/internal/ports/adapters/cache"
+	"order_service/internal/ports/adapters/deadletters"
+	"order_service/internal/ports/adapters/events"
 	"order_service/internal/ports/adapters/storage"
 	"order_service/internal/runner"
 	"order_service/internal/service"
 	"order_service/pkg/kafka"
 	"order_service/pkg/logger"
+	"order_service/pkg/pkgports/adapters/backoff"
+	"order_service/pkg/pkgports/adapters/cache/lru"
+	"order_service/pkg/pkgports/adapters/cache/tiered"
 	"order_service/pkg/pkgports/adapters/receiver"
 	"order_service/pkg/postgres"
+	"order_service/pkg/redis"
 	"os"
 	"os/signal"
 	"sync"
@@ -43,6 +52,11 @@ func main() {
 		logger.GetLoggerFromCtx(ctx).Fatal(ctx, "failed to load config", zap.Error(err))
 	}
 
+	if cfg.OrderService.SaveBackoffSeconds > 0 {
+		logger.GetLoggerFromCtx(ctx).Warn(ctx, "ORDER_SERVICE_SAVE_BACKOFF_SECONDS is deprecated, use ORDER_SERVICE_SAVE_BACKOFF_*",
+			zap.String("policy", cfg.OrderService.SaveBackoff.Policy), zap.Int("base_ms", cfg.OrderService.SaveBackoff.BaseMs))
+	}
+
 	pgCfg := cfg.Postgres
 	kafkaCfg := cfg.Kafka
 	serviceCfg := cfg.OrderService
@@ -56,11 +70,51 @@ func main() {
 	}
 	logger.GetLoggerFromCtx(ctx).Info(ctx, "connected to postgres")
 
-	err = kafka.CreateTopicIfNotExists(kafkaCfg, serviceCfg.KafkaTopic, cfg.Kafka.NumPartitions, cfg.Kafka.ReplicationFactor)
+	// redis is connected only if orders are cached there
+	var redisClient *goredis.Client
+	switch serviceCfg.CacheBackend {
+	case cache.BackendMemory:
+	case cache.BackendRedis, cache.BackendTwoTier:
+		redisClient, err = redis.New(ctx, cfg.Redis)
+		if err != nil {
+			logger.GetLoggerFromCtx(ctx).Fatal(ctx, "failed to connect to redis", zap.Error(err))
+		}
+		logger.GetLoggerFromCtx(ctx).Info(ctx, "connected to redis")
+	default:
+		logger.GetLoggerFromCtx(ctx).Fatal(ctx, "failed to choose cache backend",
+			zap.String("backend", serviceCfg.CacheBackend), zap.Error(cache.ErrUnknownBackend))
+	}
+
+	topicCreationBackoff, err := backoff.New(serviceCfg.TopicCreationBackoff)
+	if err != nil {
+		logger.GetLoggerFromCtx(ctx).Fatal(ctx, "failed to create topic creation backoff policy", zap.Error(err))
+	}
+	saveBackoff, err := backoff.New(serviceCfg.SaveBackoff)
+	if err != nil {
+		logger.GetLoggerFromCtx(ctx).Fatal(ctx, "failed to create save backoff policy", zap.Error(err))
+	}
+
+	err = kafka.CreateTopicWithRetry(ctx, kafkaCfg, serviceCfg.KafkaTopic, cfg.Kafka.NumPartitions, cfg.Kafka.ReplicationFactor,
+		serviceCfg.TopicCreationRetries, topicCreationBackoff)
 	if err != nil {
 		logger.GetLoggerFromCtx(ctx).Fatal(ctx, "failed to create topic kafka", zap.Error(err))
 	}
 	kafkaConsumer := kafka.NewReader(ctx, kafkaCfg, serviceCfg.KafkaTopic, serviceCfg.KafkaGroupID)
+
+	err = kafka.CreateTopicWithRetry(ctx, kafkaCfg, serviceCfg.KafkaDLQTopic, cfg.Kafka.NumPartitions, cfg.Kafka.ReplicationFactor,
+		serviceCfg.TopicCreationRetries, topicCreationBackoff)
+	if err != nil {
+		logger.GetLoggerFromCtx(ctx).Fatal(ctx, "failed to create DLQ topic kafka", zap.Error(err))
+	}
+	kafkaDLQWriter := kafka.NewWriter(ctx, kafkaCfg, serviceCfg.KafkaDLQTopic)
+	kafkaDLQConsumer := kafka.NewReader(ctx, kafkaCfg, serviceCfg.KafkaDLQTopic, serviceCfg.KafkaDLQGroupID)
+
+	err = kafka.CreateTopicWithRetry(ctx, kafkaCfg, serviceCfg.KafkaOrderEventsTopic, cfg.Kafka.NumPartitions, cfg.Kafka.ReplicationFactor,
+		serviceCfg.TopicCreationRetries, topicCreationBackoff)
+	if err != nil {
+		logger.GetLoggerFromCtx(ctx).Fatal(ctx, "failed to create order events topic kafka", zap.Error(err))
+	}
+	kafkaOrderEventsWriter := kafka.NewWriter(ctx, kafkaCfg, serviceCfg.KafkaOrderEventsTopic)
 	//endregion
 
 	//region service
@@ -68,16 +122,64 @@ func main() {
 
 	receiverAdapter := receiver.NewKafkaReceiver[models.Order](
 		kafkaConsumer,
+		kafkaDLQWriter,
 		serviceCfg.MaxSaveRetriesAmount,
 		serviceCfg.MaxSaveRetriesCapacity,
-		time.Duration(serviceCfg.SaveBackoffSeconds)*time.Second,
+		saveBackoff,
+		serviceCfg.StrictDecoding,
 	)
-	cacheAdapter := cache.NewOrderCacheAdapterInMemoryLRU(serviceCfg.CacheCapacity)
+	// the in-memory cache has its own expiry loop, redis expires values by itself
+	var cacheAdapter ports.OrderCache
+	var memoryCacheAdapter *lru.CacheLRUInMemory[string, models.Order]
+	var twoTierCacheAdapter *tiered.CacheTwoTier[string, models.Order]
+	switch serviceCfg.CacheBackend {
+	case cache.BackendRedis:
+		cacheAdapter = cache.NewOrderCacheAdapterRedis(redisClient, serviceCfg.CacheRedisKeyPrefix,
+			time.Duration(serviceCfg.CacheTTLMs)*time.Millisecond)
+	case cache.BackendTwoTier:
+		l1TTL := time.Duration(serviceCfg.CacheL1TTLMs) * time.Millisecond
+		memoryCacheAdapter = cache.NewOrderCacheAdapterInMemoryLRU(serviceCfg.CacheCapacity,
+			l1TTL, time.Duration(serviceCfg.CacheExpiryIntervalMs)*time.Millisecond)
+		twoTierCacheAdapter = cache.NewOrderCacheAdapterTwoTier(memoryCacheAdapter,
+			cache.NewOrderCacheAdapterRedis(redisClient, serviceCfg.CacheRedisKeyPrefix,
+				time.Duration(serviceCfg.CacheTTLMs)*time.Millisecond),
+			cache.NewOrderCacheInvalidationsAdapterRedis(redisClient, serviceCfg.CacheInvalidationChannel),
+			l1TTL)
+		cacheAdapter = twoTierCacheAdapter
+	default:
+		memoryCacheAdapter = cache.NewOrderCacheAdapterInMemoryLRU(serviceCfg.CacheCapacity,
+			time.Duration(serviceCfg.CacheTTLMs)*time.Millisecond, time.Duration(serviceCfg.CacheExpiryIntervalMs)*time.Millisecond)
+		cacheAdapter = memoryCacheAdapter
+	}
+
+	notFoundCacheAdapter := cache.NewNotFoundOrderCacheAdapterInMemoryLRU(serviceCfg.NotFoundCacheCapacity,
+		time.Duration(serviceCfg.NotFoundCacheTTLMs)*time.Millisecond)
+
+	orderService := service.NewOrderService(storageAdapter, cacheAdapter, notFoundCacheAdapter)
+	kafkaOrderReceiverService := service.NewOrderReceiverService[*receiver.KafkaMessage[models.Order]](
+		receiverAdapter, orderService.UpsertOrder, serviceCfg.ReceiverWorkers,
+	)
+	if serviceCfg.ReceiverBatchSize > 1 {
+		kafkaOrderReceiverService = service.NewBatchOrderReceiverService[*receiver.KafkaMessage[models.Order]](
+			receiverAdapter, orderService.UpsertOrders, serviceCfg.ReceiverWorkers,
+			serviceCfg.ReceiverBatchSize, time.Duration(serviceCfg.ReceiverBatchWaitMs)*time.Millisecond,
+		)
+	}
 
-	orderService := service.NewOrderService(storageAdapter, cacheAdapter)
-	orderServiceHandler := httphandlers.NewOrderServiceHTTPHandler(orderService)
+	deadLettersStorageAdapter := storage.NewDeadLettersStoragePostgres(pool)
+	deadLettersReceiverAdapter := deadletters.NewKafkaDeadLetterReceiver(kafkaDLQConsumer)
+	deadLetterCollectorService := service.NewDeadLetterCollectorService[kafkago.Message](deadLettersReceiverAdapter, deadLettersStorageAdapter, saveBackoff)
+	// replays go through the same processing as the orders from kafka
+	deadLetterService := service.NewDeadLetterService(deadLettersStorageAdapter, kafkaOrderReceiverService.ProcessOrder)
 
-	kafkaOrderReceiverService := service.NewOrderReceiverService[*receiver.KafkaMessage[models.Order]](receiverAdapter, orderService.SaveOrder)
+	// events are written with the orders by storageAdapter, the relay publishes them
+	orderEventsStorageAdapter := storage.NewOrderEventsStoragePostgres(pool)
+	orderEventsPublisherAdapter := events.NewKafkaOrderEventPublisher(kafkaOrderEventsWriter)
+	orderEventRelayService := service.NewOrderEventRelayService(orderEventsStorageAdapter, orderEventsPublisherAdapter,
+		serviceCfg.OrderEventsBatchSize, time.Duration(serviceCfg.OrderEventsPollIntervalMs)*time.Millisecond,
+		time.Duration(serviceCfg.OrderEventsLeaseMs)*time.Millisecond, saveBackoff)
+
+	orderServiceHandler := httphandlers.NewOrderServiceHTTPHandler(orderService, deadLetterService)
 	//endregion
 
 	//region setup
@@ -102,32 +204,88 @@ func main() {
 	}
 	go runner.RunHTTP(ctx, httpServer)
 	go runner.RunOrderReceiver(ctx, kafkaOrderReceiverService)
+	go runner.RunDeadLetterCollector(ctx, deadLetterCollectorService)
+	go runner.RunOrderEventRelay(ctx, orderEventRelayService)
+	if memoryCacheAdapter != nil {
+		go runner.RunCacheExpiry(ctx, memoryCacheAdapter)
+	}
+	// it's stopped with ctx, before the redis client is closed
+	if twoTierCacheAdapter != nil {
+		go runner.RunCacheInvalidations(ctx, twoTierCacheAdapter)
+	}
 
 	<-ctx.Done()
 
 	//region shutdown
 	var shutdownWg sync.WaitGroup
-	shutdownWg.Add(3)
+	shutdownWg.Add(6)
+	// the pool is closed after everything that queries it is stopped
+	var poolUsersWg sync.WaitGroup
+	poolUsersWg.Add(4)
 
 	// shutdowns don't include wg itself, so I wrap them in unnamed goroutines
 	go func() {
 		defer shutdownWg.Done()
+		defer poolUsersWg.Done()
 		runner.ShutdownHTTP(ctx, httpServer)
 		logger.GetLoggerFromCtx(ctx).Info(ctx, "server stopped")
 	}()
 	go func() {
 		defer shutdownWg.Done()
+		poolUsersWg.Wait()
 		pool.Close()
 		logger.GetLoggerFromCtx(ctx).Info(ctx, "postgres pool stopped")
 	}()
 	go func() {
 		defer shutdownWg.Done()
+		defer poolUsersWg.Done()
 		runner.ShutdownOrderReceiver(ctx, kafkaOrderReceiverService)
 		err = kafkaConsumer.Close()
 		if err != nil {
 			logger.GetLoggerFromCtx(ctx).Error(ctx, "error while closing kafka consumer", zap.Error(err))
 		}
 		logger.GetLoggerFromCtx(ctx).Info(ctx, "kafka consumer stopped")
+
+		// DLQ writer is closed after the receiver, there might be failed messages till the end
+		err = kafkaDLQWriter.Close()
+		if err != nil {
+			logger.GetLoggerFromCtx(ctx).Error(ctx, "error while closing kafka DLQ writer", zap.Error(err))
+		}
+		logger.GetLoggerFromCtx(ctx).Info(ctx, "kafka DLQ writer stopped")
+	}()
+	go func() {
+		defer shutdownWg.Done()
+		defer poolUsersWg.Done()
+		runner.ShutdownDeadLetterCollector(ctx, deadLetterCollectorService)
+		err = kafkaDLQConsumer.Close()
+		if err != nil {
+			logger.GetLoggerFromCtx(ctx).Error(ctx, "error while closing kafka DLQ consumer", zap.Error(err))
+		}
+		logger.GetLoggerFromCtx(ctx).Info(ctx, "kafka DLQ consumer stopped")
+	}()
+	go func() {
+		defer shutdownWg.Done()
+		defer poolUsersWg.Done()
+		runner.ShutdownOrderEventRelay(ctx, orderEventRelayService)
+		err = kafkaOrderEventsWriter.Close()
+		if err != nil {
+			logger.GetLoggerFromCtx(ctx).Error(ctx, "error while closing kafka order events writer", zap.Error(err))
+		}
+		logger.GetLoggerFromCtx(ctx).Info(ctx, "kafka order events writer stopped")
+	}()
+	go func() {
+		defer shutdownWg.Done()
+		if memoryCacheAdapter != nil {
+			runner.ShutdownCacheExpiry(ctx, memoryCacheAdapter)
+			logger.GetLoggerFromCtx(ctx).Info(ctx, "cache expiry stopped")
+		}
+		if redisClient != nil {
+			err = redisClient.Close()
+			if err != nil {
+				logger.GetLoggerFromCtx(ctx).Error(ctx, "error while closing redis client", zap.Error(err))
+			}
+			logger.GetLoggerFromCtx(ctx).Info(ctx, "redis client stopped")
+		}
 	}()
 
 	shutdownWg.Wait()
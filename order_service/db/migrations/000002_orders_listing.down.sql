@@ -0,0 +1,8 @@
+BEGIN;
+
+DROP INDEX IF EXISTS order_service.idx_orders_created_at_order_uid;
+DROP INDEX IF EXISTS order_service.idx_orders_customer_id;
+DROP INDEX IF EXISTS order_service.idx_orders_track_number;
+DROP INDEX IF EXISTS order_service.idx_orders_date_created;
+
+COMMIT;
@@ -0,0 +1,11 @@
+BEGIN;
+
+-- Keyset pagination of GET /orders goes by (created_at, order_uid) descending
+CREATE INDEX IF NOT EXISTS idx_orders_created_at_order_uid ON order_service.orders (created_at DESC, order_uid DESC);
+
+-- Indexes for the filters of GET /orders
+CREATE INDEX IF NOT EXISTS idx_orders_customer_id ON order_service.orders (customer_id);
+CREATE INDEX IF NOT EXISTS idx_orders_track_number ON order_service.orders (track_number);
+CREATE INDEX IF NOT EXISTS idx_orders_date_created ON order_service.orders (date_created);
+
+COMMIT;
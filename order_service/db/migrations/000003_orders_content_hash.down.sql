@@ -0,0 +1,6 @@
+BEGIN;
+
+ALTER TABLE order_service.orders
+    DROP COLUMN IF EXISTS content_hash;
+
+COMMIT;
@@ -0,0 +1,8 @@
+BEGIN;
+
+-- sha256 of the order content, used to tell a redelivery of the same order from a conflicting one
+-- orders saved before this migration have NULL
+ALTER TABLE order_service.orders
+    ADD COLUMN IF NOT EXISTS content_hash CHAR(64);
+
+COMMIT;
@@ -0,0 +1,8 @@
+BEGIN;
+
+DROP TABLE IF EXISTS order_service.order_history;
+
+ALTER TABLE order_service.orders
+    DROP COLUMN IF EXISTS version;
+
+COMMIT;
@@ -0,0 +1,21 @@
+BEGIN;
+
+-- Orders change after creation, a message with a bigger version replaces the stored order
+ALTER TABLE order_service.orders
+    ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 0;
+
+-- Previous versions of every updated order, for audit
+-- snapshot has the same format as the order JSON (delivery, payment and items included)
+CREATE TABLE IF NOT EXISTS order_service.order_history
+(
+    id           BIGSERIAL PRIMARY KEY,
+    order_uid    VARCHAR(50)              NOT NULL REFERENCES order_service.orders (order_uid) ON DELETE CASCADE,
+    version      INTEGER                  NOT NULL,
+    content_hash CHAR(64),
+    snapshot     JSONB                    NOT NULL,
+    archived_at  TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_order_history_order_uid_version ON order_service.order_history (order_uid, version);
+
+COMMIT;
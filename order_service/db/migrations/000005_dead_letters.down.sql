@@ -0,0 +1,5 @@
+BEGIN;
+
+DROP TABLE IF EXISTS order_service.dead_letters;
+
+COMMIT;
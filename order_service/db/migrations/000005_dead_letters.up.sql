@@ -0,0 +1,26 @@
+BEGIN;
+
+-- Messages from the DLQ topic, stored to be inspected, replayed and purged
+CREATE TABLE IF NOT EXISTS order_service.dead_letters
+(
+    id                 BIGSERIAL PRIMARY KEY,
+    order_uid          VARCHAR(50)              NOT NULL DEFAULT '', -- empty if payload isn't an order
+    reason             VARCHAR(50)              NOT NULL,
+    error              TEXT                     NOT NULL,
+    attempts           INTEGER                  NOT NULL,
+    original_topic     VARCHAR(255)             NOT NULL,
+    original_partition INTEGER                  NOT NULL,
+    original_offset    BIGINT                   NOT NULL,
+    failed_at          TIMESTAMP WITH TIME ZONE NOT NULL,
+    payload            BYTEA                    NOT NULL,
+    created_at         TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    replayed_at        TIMESTAMP WITH TIME ZONE,
+
+    -- a DLQ message might be delivered twice, it's stored once
+    UNIQUE (original_topic, original_partition, original_offset)
+);
+
+CREATE INDEX IF NOT EXISTS idx_dead_letters_order_uid ON order_service.dead_letters (order_uid);
+CREATE INDEX IF NOT EXISTS idx_dead_letters_reason ON order_service.dead_letters (reason);
+
+COMMIT;
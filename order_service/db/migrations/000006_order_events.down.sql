@@ -0,0 +1,5 @@
+BEGIN;
+
+DROP TABLE IF EXISTS order_service.order_events;
+
+COMMIT;
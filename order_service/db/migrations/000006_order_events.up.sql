@@ -0,0 +1,18 @@
+BEGIN;
+
+-- Transactional outbox: events are written in the same transaction as the order
+-- and published to kafka by the relay, sent_at is set after the publish
+CREATE TABLE IF NOT EXISTS order_service.order_events
+(
+    id         BIGSERIAL PRIMARY KEY,
+    order_uid  VARCHAR(50)              NOT NULL,
+    event_type VARCHAR(50)              NOT NULL,
+    payload    JSONB                    NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    sent_at    TIMESTAMP WITH TIME ZONE
+);
+
+-- the relay reads unsent events only
+CREATE INDEX IF NOT EXISTS idx_order_events_unsent ON order_service.order_events (id) WHERE sent_at IS NULL;
+
+COMMIT;
@@ -0,0 +1,24 @@
+BEGIN;
+
+-- Lifecycle of orders, the current status is the one of the last event
+-- allowed transitions are checked by the service, see models.OrderStatus
+CREATE TABLE IF NOT EXISTS order_service.order_status_events
+(
+    id         BIGSERIAL PRIMARY KEY,
+    order_uid  VARCHAR(50)              NOT NULL REFERENCES order_service.orders (order_uid) ON DELETE CASCADE,
+    status     VARCHAR(20)              NOT NULL CHECK (status IN
+                                                        ('created', 'paid', 'assembled', 'shipped', 'delivered',
+                                                         'cancelled', 'returned')),
+    comment    VARCHAR(200)             NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_order_status_events_order_uid_id ON order_service.order_status_events (order_uid, id);
+
+-- orders saved before have been created at least
+INSERT INTO order_service.order_status_events (order_uid, status, created_at)
+SELECT o.order_uid, 'created', COALESCE(o.created_at, CURRENT_TIMESTAMP)
+FROM order_service.orders o
+WHERE NOT EXISTS (SELECT 1 FROM order_service.order_status_events e WHERE e.order_uid = o.order_uid);
+
+COMMIT;
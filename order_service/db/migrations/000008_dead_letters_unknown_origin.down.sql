@@ -0,0 +1,15 @@
+BEGIN;
+
+-- the IDs keep unknown origins unique
+UPDATE order_service.dead_letters
+SET original_topic     = '',
+    original_partition = 0,
+    original_offset    = -id
+WHERE original_topic IS NULL;
+
+ALTER TABLE order_service.dead_letters
+    ALTER COLUMN original_topic SET NOT NULL,
+    ALTER COLUMN original_partition SET NOT NULL,
+    ALTER COLUMN original_offset SET NOT NULL;
+
+COMMIT;
@@ -0,0 +1,16 @@
+BEGIN;
+
+-- DLQ messages without origin headers have no original topic/partition/offset,
+-- NULLs don't collide in the UNIQUE constraint, so they aren't deduplicated with each other
+ALTER TABLE order_service.dead_letters
+    ALTER COLUMN original_topic DROP NOT NULL,
+    ALTER COLUMN original_partition DROP NOT NULL,
+    ALTER COLUMN original_offset DROP NOT NULL;
+
+UPDATE order_service.dead_letters
+SET original_topic     = NULL,
+    original_partition = NULL,
+    original_offset    = NULL
+WHERE original_topic = '';
+
+COMMIT;
@@ -0,0 +1,8 @@
+BEGIN;
+
+DROP INDEX IF EXISTS order_service.idx_order_events_unsent_by_order;
+
+ALTER TABLE order_service.order_events
+    DROP COLUMN IF EXISTS locked_until;
+
+COMMIT;
@@ -0,0 +1,11 @@
+BEGIN;
+
+-- relays of all the replicas read the outbox, an event is leased by one of them until locked_until
+ALTER TABLE order_service.order_events
+    ADD COLUMN IF NOT EXISTS locked_until TIMESTAMP WITH TIME ZONE;
+
+-- an event isn't claimed while an earlier unsent event of its order is leased
+CREATE INDEX IF NOT EXISTS idx_order_events_unsent_by_order
+    ON order_service.order_events (order_uid, id) WHERE sent_at IS NULL;
+
+COMMIT;
@@ -0,0 +1,8 @@
+BEGIN;
+
+DELETE FROM order_service.order_status_events WHERE nm_id IS NOT NULL;
+
+ALTER TABLE order_service.order_status_events
+    DROP COLUMN IF EXISTS nm_id;
+
+COMMIT;
@@ -0,0 +1,8 @@
+BEGIN;
+
+-- items have their own lifecycle, an event with nm_id is a change of such item of the order,
+-- NULL is the order itself. Items are replaced by newer versions of the order, so it isn't a foreign key
+ALTER TABLE order_service.order_status_events
+    ADD COLUMN IF NOT EXISTS nm_id BIGINT;
+
+COMMIT;
@@ -0,0 +1,10 @@
+BEGIN;
+
+DELETE FROM order_service.order_history
+WHERE NOT EXISTS (SELECT 1 FROM order_service.orders WHERE orders.order_uid = order_history.order_uid);
+
+ALTER TABLE order_service.order_history
+    ADD CONSTRAINT order_history_order_uid_fkey
+        FOREIGN KEY (order_uid) REFERENCES order_service.orders (order_uid) ON DELETE CASCADE;
+
+COMMIT;
@@ -0,0 +1,8 @@
+BEGIN;
+
+-- order_history is the audit trail, it must outlive the deleted orders, so it isn't a foreign key anymore.
+-- idx_order_history_order_uid_version still serves lookups by order_uid
+ALTER TABLE order_service.order_history
+    DROP CONSTRAINT IF EXISTS order_history_order_uid_fkey;
+
+COMMIT;
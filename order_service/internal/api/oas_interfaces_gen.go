@@ -1,6 +1,46 @@
 // Code generated by ogen, DO NOT EDIT.
 package api
 
+type AdminDlqDeleteRes interface {
+	adminDlqDeleteRes()
+}
+
+type AdminDlqGetRes interface {
+	adminDlqGetRes()
+}
+
+type AdminDlqIDDeleteRes interface {
+	adminDlqIDDeleteRes()
+}
+
+type AdminDlqIDGetRes interface {
+	adminDlqIDGetRes()
+}
+
+type AdminDlqIDReplayPostRes interface {
+	adminDlqIDReplayPostRes()
+}
+
+type AdminDlqReplayPostRes interface {
+	adminDlqReplayPostRes()
+}
+
+type OrderIDDeleteRes interface {
+	orderIDDeleteRes()
+}
+
 type OrderIDGetRes interface {
 	orderIDGetRes()
 }
+
+type OrderIDStatusPostRes interface {
+	orderIDStatusPostRes()
+}
+
+type OrdersGetRes interface {
+	ordersGetRes()
+}
+
+type OrdersPostRes interface {
+	ordersPostRes()
+}
@@ -6,5 +6,16 @@ package api
 type OperationName = string
 
 const (
-	OrderIDGetOperation OperationName = "OrderIDGet"
+	AdminCacheGetOperation        OperationName = "AdminCacheGet"
+	AdminDlqDeleteOperation       OperationName = "AdminDlqDelete"
+	AdminDlqGetOperation          OperationName = "AdminDlqGet"
+	AdminDlqIDDeleteOperation     OperationName = "AdminDlqIDDelete"
+	AdminDlqIDGetOperation        OperationName = "AdminDlqIDGet"
+	AdminDlqIDReplayPostOperation OperationName = "AdminDlqIDReplayPost"
+	AdminDlqReplayPostOperation   OperationName = "AdminDlqReplayPost"
+	OrderIDDeleteOperation        OperationName = "OrderIDDelete"
+	OrderIDGetOperation           OperationName = "OrderIDGet"
+	OrderIDStatusPostOperation    OperationName = "OrderIDStatusPost"
+	OrdersGetOperation            OperationName = "OrdersGet"
+	OrdersPostOperation           OperationName = "OrdersPost"
 )
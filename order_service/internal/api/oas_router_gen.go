@@ -49,35 +49,253 @@ func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 			break
 		}
 		switch elem[0] {
-		case '/': // Prefix: "/order/"
+		case '/': // Prefix: "/"
 
-			if l := len("/order/"); len(elem) >= l && elem[0:l] == "/order/" {
+			if l := len("/"); len(elem) >= l && elem[0:l] == "/" {
 				elem = elem[l:]
 			} else {
 				break
 			}
 
-			// Param: "id"
-			// Leaf parameter, slashes are prohibited
-			idx := strings.IndexByte(elem, '/')
-			if idx >= 0 {
+			if len(elem) == 0 {
 				break
 			}
-			args[0] = elem
-			elem = ""
+			switch elem[0] {
+			case 'a': // Prefix: "admin/"
+
+				if l := len("admin/"); len(elem) >= l && elem[0:l] == "admin/" {
+					elem = elem[l:]
+				} else {
+					break
+				}
+
+				if len(elem) == 0 {
+					break
+				}
+				switch elem[0] {
+				case 'c': // Prefix: "cache"
+
+					if l := len("cache"); len(elem) >= l && elem[0:l] == "cache" {
+						elem = elem[l:]
+					} else {
+						break
+					}
+
+					if len(elem) == 0 {
+						// Leaf node.
+						switch r.Method {
+						case "GET":
+							s.handleAdminCacheGetRequest([0]string{}, elemIsEscaped, w, r)
+						default:
+							s.notAllowed(w, r, "GET")
+						}
+
+						return
+					}
+
+				case 'd': // Prefix: "dlq"
+
+					if l := len("dlq"); len(elem) >= l && elem[0:l] == "dlq" {
+						elem = elem[l:]
+					} else {
+						break
+					}
+
+					if len(elem) == 0 {
+						switch r.Method {
+						case "DELETE":
+							s.handleAdminDlqDeleteRequest([0]string{}, elemIsEscaped, w, r)
+						case "GET":
+							s.handleAdminDlqGetRequest([0]string{}, elemIsEscaped, w, r)
+						default:
+							s.notAllowed(w, r, "DELETE,GET")
+						}
+
+						return
+					}
+					switch elem[0] {
+					case '/': // Prefix: "/"
+
+						if l := len("/"); len(elem) >= l && elem[0:l] == "/" {
+							elem = elem[l:]
+						} else {
+							break
+						}
+
+						if len(elem) == 0 {
+							break
+						}
+						switch elem[0] {
+						case 'r': // Prefix: "replay"
+							origElem := elem
+							if l := len("replay"); len(elem) >= l && elem[0:l] == "replay" {
+								elem = elem[l:]
+							} else {
+								break
+							}
+
+							if len(elem) == 0 {
+								// Leaf node.
+								switch r.Method {
+								case "POST":
+									s.handleAdminDlqReplayPostRequest([0]string{}, elemIsEscaped, w, r)
+								default:
+									s.notAllowed(w, r, "POST")
+								}
+
+								return
+							}
+
+							elem = origElem
+						}
+						// Param: "id"
+						// Match until "/"
+						idx := strings.IndexByte(elem, '/')
+						if idx < 0 {
+							idx = len(elem)
+						}
+						args[0] = elem[:idx]
+						elem = elem[idx:]
+
+						if len(elem) == 0 {
+							switch r.Method {
+							case "DELETE":
+								s.handleAdminDlqIDDeleteRequest([1]string{
+									args[0],
+								}, elemIsEscaped, w, r)
+							case "GET":
+								s.handleAdminDlqIDGetRequest([1]string{
+									args[0],
+								}, elemIsEscaped, w, r)
+							default:
+								s.notAllowed(w, r, "DELETE,GET")
+							}
+
+							return
+						}
+						switch elem[0] {
+						case '/': // Prefix: "/replay"
+
+							if l := len("/replay"); len(elem) >= l && elem[0:l] == "/replay" {
+								elem = elem[l:]
+							} else {
+								break
+							}
+
+							if len(elem) == 0 {
+								// Leaf node.
+								switch r.Method {
+								case "POST":
+									s.handleAdminDlqIDReplayPostRequest([1]string{
+										args[0],
+									}, elemIsEscaped, w, r)
+								default:
+									s.notAllowed(w, r, "POST")
+								}
+
+								return
+							}
+
+						}
+
+					}
+
+				}
+
+			case 'o': // Prefix: "order"
+
+				if l := len("order"); len(elem) >= l && elem[0:l] == "order" {
+					elem = elem[l:]
+				} else {
+					break
+				}
+
+				if len(elem) == 0 {
+					break
+				}
+				switch elem[0] {
+				case '/': // Prefix: "/"
+
+					if l := len("/"); len(elem) >= l && elem[0:l] == "/" {
+						elem = elem[l:]
+					} else {
+						break
+					}
+
+					// Param: "id"
+					// Match until "/"
+					idx := strings.IndexByte(elem, '/')
+					if idx < 0 {
+						idx = len(elem)
+					}
+					args[0] = elem[:idx]
+					elem = elem[idx:]
+
+					if len(elem) == 0 {
+						switch r.Method {
+						case "DELETE":
+							s.handleOrderIDDeleteRequest([1]string{
+								args[0],
+							}, elemIsEscaped, w, r)
+						case "GET":
+							s.handleOrderIDGetRequest([1]string{
+								args[0],
+							}, elemIsEscaped, w, r)
+						default:
+							s.notAllowed(w, r, "DELETE,GET")
+						}
+
+						return
+					}
+					switch elem[0] {
+					case '/': // Prefix: "/status"
+
+						if l := len("/status"); len(elem) >= l && elem[0:l] == "/status" {
+							elem = elem[l:]
+						} else {
+							break
+						}
+
+						if len(elem) == 0 {
+							// Leaf node.
+							switch r.Method {
+							case "POST":
+								s.handleOrderIDStatusPostRequest([1]string{
+									args[0],
+								}, elemIsEscaped, w, r)
+							default:
+								s.notAllowed(w, r, "POST")
+							}
+
+							return
+						}
+
+					}
+
+				case 's': // Prefix: "s"
+
+					if l := len("s"); len(elem) >= l && elem[0:l] == "s" {
+						elem = elem[l:]
+					} else {
+						break
+					}
+
+					if len(elem) == 0 {
+						// Leaf node.
+						switch r.Method {
+						case "GET":
+							s.handleOrdersGetRequest([0]string{}, elemIsEscaped, w, r)
+						case "POST":
+							s.handleOrdersPostRequest([0]string{}, elemIsEscaped, w, r)
+						default:
+							s.notAllowed(w, r, "GET,POST")
+						}
+
+						return
+					}
 
-			if len(elem) == 0 {
-				// Leaf node.
-				switch r.Method {
-				case "GET":
-					s.handleOrderIDGetRequest([1]string{
-						args[0],
-					}, elemIsEscaped, w, r)
-				default:
-					s.notAllowed(w, r, "GET")
 				}
 
-				return
 			}
 
 		}
@@ -160,37 +378,297 @@ func (s *Server) FindPath(method string, u *url.URL) (r Route, _ bool) {
 			break
 		}
 		switch elem[0] {
-		case '/': // Prefix: "/order/"
+		case '/': // Prefix: "/"
 
-			if l := len("/order/"); len(elem) >= l && elem[0:l] == "/order/" {
+			if l := len("/"); len(elem) >= l && elem[0:l] == "/" {
 				elem = elem[l:]
 			} else {
 				break
 			}
 
-			// Param: "id"
-			// Leaf parameter, slashes are prohibited
-			idx := strings.IndexByte(elem, '/')
-			if idx >= 0 {
+			if len(elem) == 0 {
 				break
 			}
-			args[0] = elem
-			elem = ""
+			switch elem[0] {
+			case 'a': // Prefix: "admin/"
+
+				if l := len("admin/"); len(elem) >= l && elem[0:l] == "admin/" {
+					elem = elem[l:]
+				} else {
+					break
+				}
+
+				if len(elem) == 0 {
+					break
+				}
+				switch elem[0] {
+				case 'c': // Prefix: "cache"
+
+					if l := len("cache"); len(elem) >= l && elem[0:l] == "cache" {
+						elem = elem[l:]
+					} else {
+						break
+					}
+
+					if len(elem) == 0 {
+						// Leaf node.
+						switch method {
+						case "GET":
+							r.name = AdminCacheGetOperation
+							r.summary = "Get orders cache statistics"
+							r.operationID = ""
+							r.pathPattern = "/admin/cache"
+							r.args = args
+							r.count = 0
+							return r, true
+						default:
+							return
+						}
+					}
+
+				case 'd': // Prefix: "dlq"
+
+					if l := len("dlq"); len(elem) >= l && elem[0:l] == "dlq" {
+						elem = elem[l:]
+					} else {
+						break
+					}
+
+					if len(elem) == 0 {
+						switch method {
+						case "DELETE":
+							r.name = AdminDlqDeleteOperation
+							r.summary = "Purge dead-lettered orders"
+							r.operationID = ""
+							r.pathPattern = "/admin/dlq"
+							r.args = args
+							r.count = 0
+							return r, true
+						case "GET":
+							r.name = AdminDlqGetOperation
+							r.summary = "List dead-lettered orders"
+							r.operationID = ""
+							r.pathPattern = "/admin/dlq"
+							r.args = args
+							r.count = 0
+							return r, true
+						default:
+							return
+						}
+					}
+					switch elem[0] {
+					case '/': // Prefix: "/"
+
+						if l := len("/"); len(elem) >= l && elem[0:l] == "/" {
+							elem = elem[l:]
+						} else {
+							break
+						}
+
+						if len(elem) == 0 {
+							break
+						}
+						switch elem[0] {
+						case 'r': // Prefix: "replay"
+							origElem := elem
+							if l := len("replay"); len(elem) >= l && elem[0:l] == "replay" {
+								elem = elem[l:]
+							} else {
+								break
+							}
+
+							if len(elem) == 0 {
+								// Leaf node.
+								switch method {
+								case "POST":
+									r.name = AdminDlqReplayPostOperation
+									r.summary = "Replay a batch of dead-lettered orders"
+									r.operationID = ""
+									r.pathPattern = "/admin/dlq/replay"
+									r.args = args
+									r.count = 0
+									return r, true
+								default:
+									return
+								}
+							}
+
+							elem = origElem
+						}
+						// Param: "id"
+						// Match until "/"
+						idx := strings.IndexByte(elem, '/')
+						if idx < 0 {
+							idx = len(elem)
+						}
+						args[0] = elem[:idx]
+						elem = elem[idx:]
+
+						if len(elem) == 0 {
+							switch method {
+							case "DELETE":
+								r.name = AdminDlqIDDeleteOperation
+								r.summary = "Delete dead-lettered order by ID"
+								r.operationID = ""
+								r.pathPattern = "/admin/dlq/{id}"
+								r.args = args
+								r.count = 1
+								return r, true
+							case "GET":
+								r.name = AdminDlqIDGetOperation
+								r.summary = "Get dead-lettered order by ID"
+								r.operationID = ""
+								r.pathPattern = "/admin/dlq/{id}"
+								r.args = args
+								r.count = 1
+								return r, true
+							default:
+								return
+							}
+						}
+						switch elem[0] {
+						case '/': // Prefix: "/replay"
+
+							if l := len("/replay"); len(elem) >= l && elem[0:l] == "/replay" {
+								elem = elem[l:]
+							} else {
+								break
+							}
+
+							if len(elem) == 0 {
+								// Leaf node.
+								switch method {
+								case "POST":
+									r.name = AdminDlqIDReplayPostOperation
+									r.summary = "Replay dead-lettered order by ID"
+									r.operationID = ""
+									r.pathPattern = "/admin/dlq/{id}/replay"
+									r.args = args
+									r.count = 1
+									return r, true
+								default:
+									return
+								}
+							}
+
+						}
+
+					}
+
+				}
+
+			case 'o': // Prefix: "order"
+
+				if l := len("order"); len(elem) >= l && elem[0:l] == "order" {
+					elem = elem[l:]
+				} else {
+					break
+				}
+
+				if len(elem) == 0 {
+					break
+				}
+				switch elem[0] {
+				case '/': // Prefix: "/"
+
+					if l := len("/"); len(elem) >= l && elem[0:l] == "/" {
+						elem = elem[l:]
+					} else {
+						break
+					}
+
+					// Param: "id"
+					// Match until "/"
+					idx := strings.IndexByte(elem, '/')
+					if idx < 0 {
+						idx = len(elem)
+					}
+					args[0] = elem[:idx]
+					elem = elem[idx:]
+
+					if len(elem) == 0 {
+						switch method {
+						case "DELETE":
+							r.name = OrderIDDeleteOperation
+							r.summary = "Delete order by ID"
+							r.operationID = ""
+							r.pathPattern = "/order/{id}"
+							r.args = args
+							r.count = 1
+							return r, true
+						case "GET":
+							r.name = OrderIDGetOperation
+							r.summary = "Get order by ID"
+							r.operationID = ""
+							r.pathPattern = "/order/{id}"
+							r.args = args
+							r.count = 1
+							return r, true
+						default:
+							return
+						}
+					}
+					switch elem[0] {
+					case '/': // Prefix: "/status"
+
+						if l := len("/status"); len(elem) >= l && elem[0:l] == "/status" {
+							elem = elem[l:]
+						} else {
+							break
+						}
+
+						if len(elem) == 0 {
+							// Leaf node.
+							switch method {
+							case "POST":
+								r.name = OrderIDStatusPostOperation
+								r.summary = "Change order status"
+								r.operationID = ""
+								r.pathPattern = "/order/{id}/status"
+								r.args = args
+								r.count = 1
+								return r, true
+							default:
+								return
+							}
+						}
+
+					}
+
+				case 's': // Prefix: "s"
+
+					if l := len("s"); len(elem) >= l && elem[0:l] == "s" {
+						elem = elem[l:]
+					} else {
+						break
+					}
+
+					if len(elem) == 0 {
+						// Leaf node.
+						switch method {
+						case "GET":
+							r.name = OrdersGetOperation
+							r.summary = "List orders"
+							r.operationID = ""
+							r.pathPattern = "/orders"
+							r.args = args
+							r.count = 0
+							return r, true
+						case "POST":
+							r.name = OrdersPostOperation
+							r.summary = "Create order"
+							r.operationID = ""
+							r.pathPattern = "/orders"
+							r.args = args
+							r.count = 0
+							return r, true
+						default:
+							return
+						}
+					}
 
-			if len(elem) == 0 {
-				// Leaf node.
-				switch method {
-				case "GET":
-					r.name = OrderIDGetOperation
-					r.summary = "Get order by ID"
-					r.operationID = ""
-					r.pathPattern = "/order/{id}"
-					r.args = args
-					r.count = 1
-					return r, true
-				default:
-					return
 				}
+
 			}
 
 		}
@@ -5,12 +5,19 @@ package api
 import (
 	"fmt"
 	"time"
+
+	"github.com/go-faster/errors"
 )
 
 func (s *ErrorResponseStatusCode) Error() string {
 	return fmt.Sprintf("code %d: %+v", s.StatusCode, s.Response)
 }
 
+// AdminDlqIDDeleteNoContent is response for AdminDlqIDDelete operation.
+type AdminDlqIDDeleteNoContent struct{}
+
+func (*AdminDlqIDDeleteNoContent) adminDlqIDDeleteRes() {}
+
 // Merged schema.
 // Ref: #/components/schemas/BadRequestErrorResponse
 type BadRequestErrorResponse struct {
@@ -28,7 +35,605 @@ func (s *BadRequestErrorResponse) SetMessage(val string) {
 	s.Message = val
 }
 
-func (*BadRequestErrorResponse) orderIDGetRes() {}
+func (*BadRequestErrorResponse) orderIDGetRes()        {}
+func (*BadRequestErrorResponse) orderIDStatusPostRes() {}
+func (*BadRequestErrorResponse) ordersGetRes()         {}
+
+// Ref: #/components/schemas/CacheStatsResponse
+type CacheStatsResponse struct {
+	Hits   int64 `json:"hits"`
+	Misses int64 `json:"misses"`
+	// Hits / (hits + misses), 0 before the first read.
+	HitRatio float64 `json:"hit_ratio"`
+	Sets     int64   `json:"sets"`
+	// Values removed to free space for new ones.
+	Evictions int64 `json:"evictions"`
+	// Values removed after their TTL.
+	Expired int64 `json:"expired"`
+	// Reads on cache misses that shared the storage read of a concurrent read of the same order.
+	Coalesced int64 `json:"coalesced"`
+	// 0 if the cache doesn't count its values, e.g. redis.
+	Size     int `json:"size"`
+	Capacity int `json:"capacity"`
+	// ID of the most recently used order, absent if the cache is empty.
+	MostUsedKey OptString `json:"most_used_key"`
+	// ID of the order that is evicted next, absent if the cache is empty.
+	LeastUsedKey OptString `json:"least_used_key"`
+	// Statistics of every tier of a two-tier cache from L1 to L2, absent for a single cache.
+	Tiers []CacheTierStats `json:"tiers"`
+}
+
+// GetHits returns the value of Hits.
+func (s *CacheStatsResponse) GetHits() int64 {
+	return s.Hits
+}
+
+// GetMisses returns the value of Misses.
+func (s *CacheStatsResponse) GetMisses() int64 {
+	return s.Misses
+}
+
+// GetHitRatio returns the value of HitRatio.
+func (s *CacheStatsResponse) GetHitRatio() float64 {
+	return s.HitRatio
+}
+
+// GetSets returns the value of Sets.
+func (s *CacheStatsResponse) GetSets() int64 {
+	return s.Sets
+}
+
+// GetEvictions returns the value of Evictions.
+func (s *CacheStatsResponse) GetEvictions() int64 {
+	return s.Evictions
+}
+
+// GetExpired returns the value of Expired.
+func (s *CacheStatsResponse) GetExpired() int64 {
+	return s.Expired
+}
+
+// GetCoalesced returns the value of Coalesced.
+func (s *CacheStatsResponse) GetCoalesced() int64 {
+	return s.Coalesced
+}
+
+// GetSize returns the value of Size.
+func (s *CacheStatsResponse) GetSize() int {
+	return s.Size
+}
+
+// GetCapacity returns the value of Capacity.
+func (s *CacheStatsResponse) GetCapacity() int {
+	return s.Capacity
+}
+
+// GetMostUsedKey returns the value of MostUsedKey.
+func (s *CacheStatsResponse) GetMostUsedKey() OptString {
+	return s.MostUsedKey
+}
+
+// GetLeastUsedKey returns the value of LeastUsedKey.
+func (s *CacheStatsResponse) GetLeastUsedKey() OptString {
+	return s.LeastUsedKey
+}
+
+// GetTiers returns the value of Tiers.
+func (s *CacheStatsResponse) GetTiers() []CacheTierStats {
+	return s.Tiers
+}
+
+// SetHits sets the value of Hits.
+func (s *CacheStatsResponse) SetHits(val int64) {
+	s.Hits = val
+}
+
+// SetMisses sets the value of Misses.
+func (s *CacheStatsResponse) SetMisses(val int64) {
+	s.Misses = val
+}
+
+// SetHitRatio sets the value of HitRatio.
+func (s *CacheStatsResponse) SetHitRatio(val float64) {
+	s.HitRatio = val
+}
+
+// SetSets sets the value of Sets.
+func (s *CacheStatsResponse) SetSets(val int64) {
+	s.Sets = val
+}
+
+// SetEvictions sets the value of Evictions.
+func (s *CacheStatsResponse) SetEvictions(val int64) {
+	s.Evictions = val
+}
+
+// SetExpired sets the value of Expired.
+func (s *CacheStatsResponse) SetExpired(val int64) {
+	s.Expired = val
+}
+
+// SetCoalesced sets the value of Coalesced.
+func (s *CacheStatsResponse) SetCoalesced(val int64) {
+	s.Coalesced = val
+}
+
+// SetSize sets the value of Size.
+func (s *CacheStatsResponse) SetSize(val int) {
+	s.Size = val
+}
+
+// SetCapacity sets the value of Capacity.
+func (s *CacheStatsResponse) SetCapacity(val int) {
+	s.Capacity = val
+}
+
+// SetMostUsedKey sets the value of MostUsedKey.
+func (s *CacheStatsResponse) SetMostUsedKey(val OptString) {
+	s.MostUsedKey = val
+}
+
+// SetLeastUsedKey sets the value of LeastUsedKey.
+func (s *CacheStatsResponse) SetLeastUsedKey(val OptString) {
+	s.LeastUsedKey = val
+}
+
+// SetTiers sets the value of Tiers.
+func (s *CacheStatsResponse) SetTiers(val []CacheTierStats) {
+	s.Tiers = val
+}
+
+// Ref: #/components/schemas/CacheTierStats
+type CacheTierStats struct {
+	Hits int64 `json:"hits"`
+	// For L1 it includes the reads served by L2.
+	Misses int64 `json:"misses"`
+	// Hits / (hits + misses), 0 before the first read.
+	HitRatio float64 `json:"hit_ratio"`
+	// For L1 it includes the values promoted from L2.
+	Sets      int64 `json:"sets"`
+	Evictions int64 `json:"evictions"`
+	Expired   int64 `json:"expired"`
+	// 0 if the cache doesn't count its values, e.g. redis.
+	Size int `json:"size"`
+	// 0 if the tier has no capacity of its own.
+	Capacity int `json:"capacity"`
+}
+
+// GetHits returns the value of Hits.
+func (s *CacheTierStats) GetHits() int64 {
+	return s.Hits
+}
+
+// GetMisses returns the value of Misses.
+func (s *CacheTierStats) GetMisses() int64 {
+	return s.Misses
+}
+
+// GetHitRatio returns the value of HitRatio.
+func (s *CacheTierStats) GetHitRatio() float64 {
+	return s.HitRatio
+}
+
+// GetSets returns the value of Sets.
+func (s *CacheTierStats) GetSets() int64 {
+	return s.Sets
+}
+
+// GetEvictions returns the value of Evictions.
+func (s *CacheTierStats) GetEvictions() int64 {
+	return s.Evictions
+}
+
+// GetExpired returns the value of Expired.
+func (s *CacheTierStats) GetExpired() int64 {
+	return s.Expired
+}
+
+// GetSize returns the value of Size.
+func (s *CacheTierStats) GetSize() int {
+	return s.Size
+}
+
+// GetCapacity returns the value of Capacity.
+func (s *CacheTierStats) GetCapacity() int {
+	return s.Capacity
+}
+
+// SetHits sets the value of Hits.
+func (s *CacheTierStats) SetHits(val int64) {
+	s.Hits = val
+}
+
+// SetMisses sets the value of Misses.
+func (s *CacheTierStats) SetMisses(val int64) {
+	s.Misses = val
+}
+
+// SetHitRatio sets the value of HitRatio.
+func (s *CacheTierStats) SetHitRatio(val float64) {
+	s.HitRatio = val
+}
+
+// SetSets sets the value of Sets.
+func (s *CacheTierStats) SetSets(val int64) {
+	s.Sets = val
+}
+
+// SetEvictions sets the value of Evictions.
+func (s *CacheTierStats) SetEvictions(val int64) {
+	s.Evictions = val
+}
+
+// SetExpired sets the value of Expired.
+func (s *CacheTierStats) SetExpired(val int64) {
+	s.Expired = val
+}
+
+// SetSize sets the value of Size.
+func (s *CacheTierStats) SetSize(val int) {
+	s.Size = val
+}
+
+// SetCapacity sets the value of Capacity.
+func (s *CacheTierStats) SetCapacity(val int) {
+	s.Capacity = val
+}
+
+// Merged schema.
+// Ref: #/components/schemas/ConflictErrorResponse
+type ConflictErrorResponse struct {
+	// Merged property.
+	Message string `json:"message"`
+}
+
+// GetMessage returns the value of Message.
+func (s *ConflictErrorResponse) GetMessage() string {
+	return s.Message
+}
+
+// SetMessage sets the value of Message.
+func (s *ConflictErrorResponse) SetMessage(val string) {
+	s.Message = val
+}
+
+func (*ConflictErrorResponse) orderIDStatusPostRes() {}
+func (*ConflictErrorResponse) ordersPostRes()        {}
+
+// Ref: #/components/schemas/DeadLetter
+type DeadLetter struct {
+	ID int64 `json:"id"`
+	// Empty if the payload couldn't be decoded.
+	OrderUID string `json:"order_uid"`
+	Reason   string `json:"reason"`
+	Error    string `json:"error"`
+	Attempts int    `json:"attempts"`
+	// The origin fields are absent if the DLQ message had no origin headers.
+	OriginalTopic     OptString   `json:"original_topic"`
+	OriginalPartition OptInt      `json:"original_partition"`
+	OriginalOffset    OptInt64    `json:"original_offset"`
+	FailedAt          time.Time   `json:"failed_at"`
+	ReplayedAt        OptDateTime `json:"replayed_at"`
+	// Original message value.
+	Payload string `json:"payload"`
+}
+
+// GetID returns the value of ID.
+func (s *DeadLetter) GetID() int64 {
+	return s.ID
+}
+
+// GetOrderUID returns the value of OrderUID.
+func (s *DeadLetter) GetOrderUID() string {
+	return s.OrderUID
+}
+
+// GetReason returns the value of Reason.
+func (s *DeadLetter) GetReason() string {
+	return s.Reason
+}
+
+// GetError returns the value of Error.
+func (s *DeadLetter) GetError() string {
+	return s.Error
+}
+
+// GetAttempts returns the value of Attempts.
+func (s *DeadLetter) GetAttempts() int {
+	return s.Attempts
+}
+
+// GetOriginalTopic returns the value of OriginalTopic.
+func (s *DeadLetter) GetOriginalTopic() OptString {
+	return s.OriginalTopic
+}
+
+// GetOriginalPartition returns the value of OriginalPartition.
+func (s *DeadLetter) GetOriginalPartition() OptInt {
+	return s.OriginalPartition
+}
+
+// GetOriginalOffset returns the value of OriginalOffset.
+func (s *DeadLetter) GetOriginalOffset() OptInt64 {
+	return s.OriginalOffset
+}
+
+// GetFailedAt returns the value of FailedAt.
+func (s *DeadLetter) GetFailedAt() time.Time {
+	return s.FailedAt
+}
+
+// GetReplayedAt returns the value of ReplayedAt.
+func (s *DeadLetter) GetReplayedAt() OptDateTime {
+	return s.ReplayedAt
+}
+
+// GetPayload returns the value of Payload.
+func (s *DeadLetter) GetPayload() string {
+	return s.Payload
+}
+
+// SetID sets the value of ID.
+func (s *DeadLetter) SetID(val int64) {
+	s.ID = val
+}
+
+// SetOrderUID sets the value of OrderUID.
+func (s *DeadLetter) SetOrderUID(val string) {
+	s.OrderUID = val
+}
+
+// SetReason sets the value of Reason.
+func (s *DeadLetter) SetReason(val string) {
+	s.Reason = val
+}
+
+// SetError sets the value of Error.
+func (s *DeadLetter) SetError(val string) {
+	s.Error = val
+}
+
+// SetAttempts sets the value of Attempts.
+func (s *DeadLetter) SetAttempts(val int) {
+	s.Attempts = val
+}
+
+// SetOriginalTopic sets the value of OriginalTopic.
+func (s *DeadLetter) SetOriginalTopic(val OptString) {
+	s.OriginalTopic = val
+}
+
+// SetOriginalPartition sets the value of OriginalPartition.
+func (s *DeadLetter) SetOriginalPartition(val OptInt) {
+	s.OriginalPartition = val
+}
+
+// SetOriginalOffset sets the value of OriginalOffset.
+func (s *DeadLetter) SetOriginalOffset(val OptInt64) {
+	s.OriginalOffset = val
+}
+
+// SetFailedAt sets the value of FailedAt.
+func (s *DeadLetter) SetFailedAt(val time.Time) {
+	s.FailedAt = val
+}
+
+// SetReplayedAt sets the value of ReplayedAt.
+func (s *DeadLetter) SetReplayedAt(val OptDateTime) {
+	s.ReplayedAt = val
+}
+
+// SetPayload sets the value of Payload.
+func (s *DeadLetter) SetPayload(val string) {
+	s.Payload = val
+}
+
+func (*DeadLetter) adminDlqIDGetRes() {}
+
+// Ref: #/components/schemas/DeadLetterListResponse
+type DeadLetterListResponse struct {
+	DeadLetters []DeadLetter `json:"dead_letters"`
+	// After_id of the next page, absent on the last page.
+	NextAfterID OptInt64 `json:"next_after_id"`
+}
+
+// GetDeadLetters returns the value of DeadLetters.
+func (s *DeadLetterListResponse) GetDeadLetters() []DeadLetter {
+	return s.DeadLetters
+}
+
+// GetNextAfterID returns the value of NextAfterID.
+func (s *DeadLetterListResponse) GetNextAfterID() OptInt64 {
+	return s.NextAfterID
+}
+
+// SetDeadLetters sets the value of DeadLetters.
+func (s *DeadLetterListResponse) SetDeadLetters(val []DeadLetter) {
+	s.DeadLetters = val
+}
+
+// SetNextAfterID sets the value of NextAfterID.
+func (s *DeadLetterListResponse) SetNextAfterID(val OptInt64) {
+	s.NextAfterID = val
+}
+
+func (*DeadLetterListResponse) adminDlqGetRes() {}
+
+// Ref: #/components/schemas/DeadLetterPurgeResponse
+type DeadLetterPurgeResponse struct {
+	Deleted int64 `json:"deleted"`
+}
+
+// GetDeleted returns the value of Deleted.
+func (s *DeadLetterPurgeResponse) GetDeleted() int64 {
+	return s.Deleted
+}
+
+// SetDeleted sets the value of Deleted.
+func (s *DeadLetterPurgeResponse) SetDeleted(val int64) {
+	s.Deleted = val
+}
+
+func (*DeadLetterPurgeResponse) adminDlqDeleteRes() {}
+
+// Ref: #/components/schemas/DeadLetterReplayBatchResponse
+type DeadLetterReplayBatchResponse struct {
+	Results []DeadLetterReplayResult `json:"results"`
+}
+
+// GetResults returns the value of Results.
+func (s *DeadLetterReplayBatchResponse) GetResults() []DeadLetterReplayResult {
+	return s.Results
+}
+
+// SetResults sets the value of Results.
+func (s *DeadLetterReplayBatchResponse) SetResults(val []DeadLetterReplayResult) {
+	s.Results = val
+}
+
+func (*DeadLetterReplayBatchResponse) adminDlqReplayPostRes() {}
+
+// Ref: #/components/schemas/DeadLetterReplayRequest
+type DeadLetterReplayRequest struct {
+	Reason   OptString `json:"reason"`
+	OrderUID OptString `json:"order_uid"`
+	Limit    OptInt    `json:"limit"`
+}
+
+// GetReason returns the value of Reason.
+func (s *DeadLetterReplayRequest) GetReason() OptString {
+	return s.Reason
+}
+
+// GetOrderUID returns the value of OrderUID.
+func (s *DeadLetterReplayRequest) GetOrderUID() OptString {
+	return s.OrderUID
+}
+
+// GetLimit returns the value of Limit.
+func (s *DeadLetterReplayRequest) GetLimit() OptInt {
+	return s.Limit
+}
+
+// SetReason sets the value of Reason.
+func (s *DeadLetterReplayRequest) SetReason(val OptString) {
+	s.Reason = val
+}
+
+// SetOrderUID sets the value of OrderUID.
+func (s *DeadLetterReplayRequest) SetOrderUID(val OptString) {
+	s.OrderUID = val
+}
+
+// SetLimit sets the value of Limit.
+func (s *DeadLetterReplayRequest) SetLimit(val OptInt) {
+	s.Limit = val
+}
+
+// Ref: #/components/schemas/DeadLetterReplayResult
+type DeadLetterReplayResult struct {
+	ID       int64                         `json:"id"`
+	OrderUID string                        `json:"order_uid"`
+	Outcome  DeadLetterReplayResultOutcome `json:"outcome"`
+	Error    OptString                     `json:"error"`
+}
+
+// GetID returns the value of ID.
+func (s *DeadLetterReplayResult) GetID() int64 {
+	return s.ID
+}
+
+// GetOrderUID returns the value of OrderUID.
+func (s *DeadLetterReplayResult) GetOrderUID() string {
+	return s.OrderUID
+}
+
+// GetOutcome returns the value of Outcome.
+func (s *DeadLetterReplayResult) GetOutcome() DeadLetterReplayResultOutcome {
+	return s.Outcome
+}
+
+// GetError returns the value of Error.
+func (s *DeadLetterReplayResult) GetError() OptString {
+	return s.Error
+}
+
+// SetID sets the value of ID.
+func (s *DeadLetterReplayResult) SetID(val int64) {
+	s.ID = val
+}
+
+// SetOrderUID sets the value of OrderUID.
+func (s *DeadLetterReplayResult) SetOrderUID(val string) {
+	s.OrderUID = val
+}
+
+// SetOutcome sets the value of Outcome.
+func (s *DeadLetterReplayResult) SetOutcome(val DeadLetterReplayResultOutcome) {
+	s.Outcome = val
+}
+
+// SetError sets the value of Error.
+func (s *DeadLetterReplayResult) SetError(val OptString) {
+	s.Error = val
+}
+
+func (*DeadLetterReplayResult) adminDlqIDReplayPostRes() {}
+
+type DeadLetterReplayResultOutcome string
+
+const (
+	DeadLetterReplayResultOutcomeReplayed        DeadLetterReplayResultOutcome = "replayed"
+	DeadLetterReplayResultOutcomeAlreadyReplayed DeadLetterReplayResultOutcome = "already_replayed"
+	DeadLetterReplayResultOutcomeInvalid         DeadLetterReplayResultOutcome = "invalid"
+	DeadLetterReplayResultOutcomeFailed          DeadLetterReplayResultOutcome = "failed"
+)
+
+// AllValues returns all DeadLetterReplayResultOutcome values.
+func (DeadLetterReplayResultOutcome) AllValues() []DeadLetterReplayResultOutcome {
+	return []DeadLetterReplayResultOutcome{
+		DeadLetterReplayResultOutcomeReplayed,
+		DeadLetterReplayResultOutcomeAlreadyReplayed,
+		DeadLetterReplayResultOutcomeInvalid,
+		DeadLetterReplayResultOutcomeFailed,
+	}
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (s DeadLetterReplayResultOutcome) MarshalText() ([]byte, error) {
+	switch s {
+	case DeadLetterReplayResultOutcomeReplayed:
+		return []byte(s), nil
+	case DeadLetterReplayResultOutcomeAlreadyReplayed:
+		return []byte(s), nil
+	case DeadLetterReplayResultOutcomeInvalid:
+		return []byte(s), nil
+	case DeadLetterReplayResultOutcomeFailed:
+		return []byte(s), nil
+	default:
+		return nil, errors.Errorf("invalid value: %q", s)
+	}
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (s *DeadLetterReplayResultOutcome) UnmarshalText(data []byte) error {
+	switch DeadLetterReplayResultOutcome(data) {
+	case DeadLetterReplayResultOutcomeReplayed:
+		*s = DeadLetterReplayResultOutcomeReplayed
+		return nil
+	case DeadLetterReplayResultOutcomeAlreadyReplayed:
+		*s = DeadLetterReplayResultOutcomeAlreadyReplayed
+		return nil
+	case DeadLetterReplayResultOutcomeInvalid:
+		*s = DeadLetterReplayResultOutcomeInvalid
+		return nil
+	case DeadLetterReplayResultOutcomeFailed:
+		*s = DeadLetterReplayResultOutcomeFailed
+		return nil
+	default:
+		return errors.Errorf("invalid value: %q", data)
+	}
+}
 
 // Ref: #/components/schemas/Delivery
 type Delivery struct {
@@ -127,7 +732,17 @@ func (s *ErrorResponse) SetMessage(val string) {
 	s.Message = val
 }
 
-func (*ErrorResponse) orderIDGetRes() {}
+func (*ErrorResponse) adminDlqDeleteRes()       {}
+func (*ErrorResponse) adminDlqGetRes()          {}
+func (*ErrorResponse) adminDlqIDDeleteRes()     {}
+func (*ErrorResponse) adminDlqIDGetRes()        {}
+func (*ErrorResponse) adminDlqIDReplayPostRes() {}
+func (*ErrorResponse) adminDlqReplayPostRes()   {}
+func (*ErrorResponse) orderIDDeleteRes()        {}
+func (*ErrorResponse) orderIDGetRes()           {}
+func (*ErrorResponse) orderIDStatusPostRes()    {}
+func (*ErrorResponse) ordersGetRes()            {}
+func (*ErrorResponse) ordersPostRes()           {}
 
 // ErrorResponseStatusCode wraps ErrorResponse with StatusCode.
 type ErrorResponseStatusCode struct {
@@ -172,7 +787,196 @@ func (s *NotFoundErrorResponse) SetMessage(val string) {
 	s.Message = val
 }
 
-func (*NotFoundErrorResponse) orderIDGetRes() {}
+func (*NotFoundErrorResponse) adminDlqIDDeleteRes()     {}
+func (*NotFoundErrorResponse) adminDlqIDGetRes()        {}
+func (*NotFoundErrorResponse) adminDlqIDReplayPostRes() {}
+func (*NotFoundErrorResponse) orderIDDeleteRes()        {}
+func (*NotFoundErrorResponse) orderIDGetRes()           {}
+func (*NotFoundErrorResponse) orderIDStatusPostRes()    {}
+
+// NewOptBool returns new OptBool with value set to v.
+func NewOptBool(v bool) OptBool {
+	return OptBool{
+		Value: v,
+		Set:   true,
+	}
+}
+
+// OptBool is optional bool.
+type OptBool struct {
+	Value bool
+	Set   bool
+}
+
+// IsSet returns true if OptBool was set.
+func (o OptBool) IsSet() bool { return o.Set }
+
+// Reset unsets value.
+func (o *OptBool) Reset() {
+	var v bool
+	o.Value = v
+	o.Set = false
+}
+
+// SetTo sets value to v.
+func (o *OptBool) SetTo(v bool) {
+	o.Set = true
+	o.Value = v
+}
+
+// Get returns value and boolean that denotes whether value was set.
+func (o OptBool) Get() (v bool, ok bool) {
+	if !o.Set {
+		return v, false
+	}
+	return o.Value, true
+}
+
+// Or returns value if set, or given parameter if does not.
+func (o OptBool) Or(d bool) bool {
+	if v, ok := o.Get(); ok {
+		return v
+	}
+	return d
+}
+
+// NewOptDateTime returns new OptDateTime with value set to v.
+func NewOptDateTime(v time.Time) OptDateTime {
+	return OptDateTime{
+		Value: v,
+		Set:   true,
+	}
+}
+
+// OptDateTime is optional time.Time.
+type OptDateTime struct {
+	Value time.Time
+	Set   bool
+}
+
+// IsSet returns true if OptDateTime was set.
+func (o OptDateTime) IsSet() bool { return o.Set }
+
+// Reset unsets value.
+func (o *OptDateTime) Reset() {
+	var v time.Time
+	o.Value = v
+	o.Set = false
+}
+
+// SetTo sets value to v.
+func (o *OptDateTime) SetTo(v time.Time) {
+	o.Set = true
+	o.Value = v
+}
+
+// Get returns value and boolean that denotes whether value was set.
+func (o OptDateTime) Get() (v time.Time, ok bool) {
+	if !o.Set {
+		return v, false
+	}
+	return o.Value, true
+}
+
+// Or returns value if set, or given parameter if does not.
+func (o OptDateTime) Or(d time.Time) time.Time {
+	if v, ok := o.Get(); ok {
+		return v
+	}
+	return d
+}
+
+// NewOptInt returns new OptInt with value set to v.
+func NewOptInt(v int) OptInt {
+	return OptInt{
+		Value: v,
+		Set:   true,
+	}
+}
+
+// OptInt is optional int.
+type OptInt struct {
+	Value int
+	Set   bool
+}
+
+// IsSet returns true if OptInt was set.
+func (o OptInt) IsSet() bool { return o.Set }
+
+// Reset unsets value.
+func (o *OptInt) Reset() {
+	var v int
+	o.Value = v
+	o.Set = false
+}
+
+// SetTo sets value to v.
+func (o *OptInt) SetTo(v int) {
+	o.Set = true
+	o.Value = v
+}
+
+// Get returns value and boolean that denotes whether value was set.
+func (o OptInt) Get() (v int, ok bool) {
+	if !o.Set {
+		return v, false
+	}
+	return o.Value, true
+}
+
+// Or returns value if set, or given parameter if does not.
+func (o OptInt) Or(d int) int {
+	if v, ok := o.Get(); ok {
+		return v
+	}
+	return d
+}
+
+// NewOptInt64 returns new OptInt64 with value set to v.
+func NewOptInt64(v int64) OptInt64 {
+	return OptInt64{
+		Value: v,
+		Set:   true,
+	}
+}
+
+// OptInt64 is optional int64.
+type OptInt64 struct {
+	Value int64
+	Set   bool
+}
+
+// IsSet returns true if OptInt64 was set.
+func (o OptInt64) IsSet() bool { return o.Set }
+
+// Reset unsets value.
+func (o *OptInt64) Reset() {
+	var v int64
+	o.Value = v
+	o.Set = false
+}
+
+// SetTo sets value to v.
+func (o *OptInt64) SetTo(v int64) {
+	o.Set = true
+	o.Value = v
+}
+
+// Get returns value and boolean that denotes whether value was set.
+func (o OptInt64) Get() (v int64, ok bool) {
+	if !o.Set {
+		return v, false
+	}
+	return o.Value, true
+}
+
+// Or returns value if set, or given parameter if does not.
+func (o OptInt64) Or(d int64) int64 {
+	if v, ok := o.Get(); ok {
+		return v
+	}
+	return d
+}
 
 // NewOptString returns new OptString with value set to v.
 func NewOptString(v string) OptString {
@@ -220,6 +1024,11 @@ func (o OptString) Or(d string) string {
 	return d
 }
 
+// OrderIDDeleteNoContent is response for OrderIDDelete operation.
+type OrderIDDeleteNoContent struct{}
+
+func (*OrderIDDeleteNoContent) orderIDDeleteRes() {}
+
 // Ref: #/components/schemas/OrderItem
 type OrderItem struct {
 	ChrtID      int64  `json:"chrt_id"`
@@ -256,97 +1065,275 @@ func (s *OrderItem) GetRid() string {
 }
 
 // GetName returns the value of Name.
-func (s *OrderItem) GetName() string {
+func (s *OrderItem) GetName() string {
+	return s.Name
+}
+
+// GetSale returns the value of Sale.
+func (s *OrderItem) GetSale() int {
+	return s.Sale
+}
+
+// GetSize returns the value of Size.
+func (s *OrderItem) GetSize() string {
+	return s.Size
+}
+
+// GetTotalPrice returns the value of TotalPrice.
+func (s *OrderItem) GetTotalPrice() int {
+	return s.TotalPrice
+}
+
+// GetNmID returns the value of NmID.
+func (s *OrderItem) GetNmID() int64 {
+	return s.NmID
+}
+
+// GetBrand returns the value of Brand.
+func (s *OrderItem) GetBrand() string {
+	return s.Brand
+}
+
+// GetStatus returns the value of Status.
+func (s *OrderItem) GetStatus() int {
+	return s.Status
+}
+
+// SetChrtID sets the value of ChrtID.
+func (s *OrderItem) SetChrtID(val int64) {
+	s.ChrtID = val
+}
+
+// SetTrackNumber sets the value of TrackNumber.
+func (s *OrderItem) SetTrackNumber(val string) {
+	s.TrackNumber = val
+}
+
+// SetPrice sets the value of Price.
+func (s *OrderItem) SetPrice(val int) {
+	s.Price = val
+}
+
+// SetRid sets the value of Rid.
+func (s *OrderItem) SetRid(val string) {
+	s.Rid = val
+}
+
+// SetName sets the value of Name.
+func (s *OrderItem) SetName(val string) {
+	s.Name = val
+}
+
+// SetSale sets the value of Sale.
+func (s *OrderItem) SetSale(val int) {
+	s.Sale = val
+}
+
+// SetSize sets the value of Size.
+func (s *OrderItem) SetSize(val string) {
+	s.Size = val
+}
+
+// SetTotalPrice sets the value of TotalPrice.
+func (s *OrderItem) SetTotalPrice(val int) {
+	s.TotalPrice = val
+}
+
+// SetNmID sets the value of NmID.
+func (s *OrderItem) SetNmID(val int64) {
+	s.NmID = val
+}
+
+// SetBrand sets the value of Brand.
+func (s *OrderItem) SetBrand(val string) {
+	s.Brand = val
+}
+
+// SetStatus sets the value of Status.
+func (s *OrderItem) SetStatus(val int) {
+	s.Status = val
+}
+
+// Merged schema.
+// Ref: #/components/schemas/OrderItemResponse
+type OrderItemResponse struct {
+	ChrtID          int64       `json:"chrt_id"`
+	TrackNumber     string      `json:"track_number"`
+	Price           int         `json:"price"`
+	Rid             string      `json:"rid"`
+	Name            string      `json:"name"`
+	Sale            int         `json:"sale"`
+	Size            string      `json:"size"`
+	TotalPrice      int         `json:"total_price"`
+	NmID            int64       `json:"nm_id"`
+	Brand           string      `json:"brand"`
+	Status          int         `json:"status"`
+	LifecycleStatus OrderStatus `json:"lifecycle_status"`
+	// Status changes of the item, oldest first, the last one is the current lifecycle_status.
+	StatusTimeline []OrderStatusEvent `json:"status_timeline"`
+}
+
+// GetChrtID returns the value of ChrtID.
+func (s *OrderItemResponse) GetChrtID() int64 {
+	return s.ChrtID
+}
+
+// GetTrackNumber returns the value of TrackNumber.
+func (s *OrderItemResponse) GetTrackNumber() string {
+	return s.TrackNumber
+}
+
+// GetPrice returns the value of Price.
+func (s *OrderItemResponse) GetPrice() int {
+	return s.Price
+}
+
+// GetRid returns the value of Rid.
+func (s *OrderItemResponse) GetRid() string {
+	return s.Rid
+}
+
+// GetName returns the value of Name.
+func (s *OrderItemResponse) GetName() string {
 	return s.Name
 }
 
 // GetSale returns the value of Sale.
-func (s *OrderItem) GetSale() int {
+func (s *OrderItemResponse) GetSale() int {
 	return s.Sale
 }
 
 // GetSize returns the value of Size.
-func (s *OrderItem) GetSize() string {
+func (s *OrderItemResponse) GetSize() string {
 	return s.Size
 }
 
 // GetTotalPrice returns the value of TotalPrice.
-func (s *OrderItem) GetTotalPrice() int {
+func (s *OrderItemResponse) GetTotalPrice() int {
 	return s.TotalPrice
 }
 
 // GetNmID returns the value of NmID.
-func (s *OrderItem) GetNmID() int64 {
+func (s *OrderItemResponse) GetNmID() int64 {
 	return s.NmID
 }
 
 // GetBrand returns the value of Brand.
-func (s *OrderItem) GetBrand() string {
+func (s *OrderItemResponse) GetBrand() string {
 	return s.Brand
 }
 
 // GetStatus returns the value of Status.
-func (s *OrderItem) GetStatus() int {
+func (s *OrderItemResponse) GetStatus() int {
 	return s.Status
 }
 
+// GetLifecycleStatus returns the value of LifecycleStatus.
+func (s *OrderItemResponse) GetLifecycleStatus() OrderStatus {
+	return s.LifecycleStatus
+}
+
+// GetStatusTimeline returns the value of StatusTimeline.
+func (s *OrderItemResponse) GetStatusTimeline() []OrderStatusEvent {
+	return s.StatusTimeline
+}
+
 // SetChrtID sets the value of ChrtID.
-func (s *OrderItem) SetChrtID(val int64) {
+func (s *OrderItemResponse) SetChrtID(val int64) {
 	s.ChrtID = val
 }
 
 // SetTrackNumber sets the value of TrackNumber.
-func (s *OrderItem) SetTrackNumber(val string) {
+func (s *OrderItemResponse) SetTrackNumber(val string) {
 	s.TrackNumber = val
 }
 
 // SetPrice sets the value of Price.
-func (s *OrderItem) SetPrice(val int) {
+func (s *OrderItemResponse) SetPrice(val int) {
 	s.Price = val
 }
 
 // SetRid sets the value of Rid.
-func (s *OrderItem) SetRid(val string) {
+func (s *OrderItemResponse) SetRid(val string) {
 	s.Rid = val
 }
 
 // SetName sets the value of Name.
-func (s *OrderItem) SetName(val string) {
+func (s *OrderItemResponse) SetName(val string) {
 	s.Name = val
 }
 
 // SetSale sets the value of Sale.
-func (s *OrderItem) SetSale(val int) {
+func (s *OrderItemResponse) SetSale(val int) {
 	s.Sale = val
 }
 
 // SetSize sets the value of Size.
-func (s *OrderItem) SetSize(val string) {
+func (s *OrderItemResponse) SetSize(val string) {
 	s.Size = val
 }
 
 // SetTotalPrice sets the value of TotalPrice.
-func (s *OrderItem) SetTotalPrice(val int) {
+func (s *OrderItemResponse) SetTotalPrice(val int) {
 	s.TotalPrice = val
 }
 
 // SetNmID sets the value of NmID.
-func (s *OrderItem) SetNmID(val int64) {
+func (s *OrderItemResponse) SetNmID(val int64) {
 	s.NmID = val
 }
 
 // SetBrand sets the value of Brand.
-func (s *OrderItem) SetBrand(val string) {
+func (s *OrderItemResponse) SetBrand(val string) {
 	s.Brand = val
 }
 
 // SetStatus sets the value of Status.
-func (s *OrderItem) SetStatus(val int) {
+func (s *OrderItemResponse) SetStatus(val int) {
 	s.Status = val
 }
 
-// Ref: #/components/schemas/OrderResponse
-type OrderResponse struct {
+// SetLifecycleStatus sets the value of LifecycleStatus.
+func (s *OrderItemResponse) SetLifecycleStatus(val OrderStatus) {
+	s.LifecycleStatus = val
+}
+
+// SetStatusTimeline sets the value of StatusTimeline.
+func (s *OrderItemResponse) SetStatusTimeline(val []OrderStatusEvent) {
+	s.StatusTimeline = val
+}
+
+// Ref: #/components/schemas/OrderListResponse
+type OrderListResponse struct {
+	Orders []OrderResponse `json:"orders"`
+	// Cursor of the next page, absent on the last page.
+	NextCursor OptString `json:"next_cursor"`
+}
+
+// GetOrders returns the value of Orders.
+func (s *OrderListResponse) GetOrders() []OrderResponse {
+	return s.Orders
+}
+
+// GetNextCursor returns the value of NextCursor.
+func (s *OrderListResponse) GetNextCursor() OptString {
+	return s.NextCursor
+}
+
+// SetOrders sets the value of Orders.
+func (s *OrderListResponse) SetOrders(val []OrderResponse) {
+	s.Orders = val
+}
+
+// SetNextCursor sets the value of NextCursor.
+func (s *OrderListResponse) SetNextCursor(val OptString) {
+	s.NextCursor = val
+}
+
+func (*OrderListResponse) ordersGetRes() {}
+
+// Ref: #/components/schemas/OrderRequest
+type OrderRequest struct {
 	OrderUID          string      `json:"order_uid"`
 	TrackNumber       string      `json:"track_number"`
 	Entry             string      `json:"entry"`
@@ -361,6 +1348,184 @@ type OrderResponse struct {
 	SmID              int         `json:"sm_id"`
 	DateCreated       time.Time   `json:"date_created"`
 	OofShard          string      `json:"oof_shard"`
+	// Version of the order, a message with a bigger version replaces the stored order.
+	Version OptInt `json:"version"`
+}
+
+// GetOrderUID returns the value of OrderUID.
+func (s *OrderRequest) GetOrderUID() string {
+	return s.OrderUID
+}
+
+// GetTrackNumber returns the value of TrackNumber.
+func (s *OrderRequest) GetTrackNumber() string {
+	return s.TrackNumber
+}
+
+// GetEntry returns the value of Entry.
+func (s *OrderRequest) GetEntry() string {
+	return s.Entry
+}
+
+// GetDelivery returns the value of Delivery.
+func (s *OrderRequest) GetDelivery() Delivery {
+	return s.Delivery
+}
+
+// GetPayment returns the value of Payment.
+func (s *OrderRequest) GetPayment() Payment {
+	return s.Payment
+}
+
+// GetItems returns the value of Items.
+func (s *OrderRequest) GetItems() []OrderItem {
+	return s.Items
+}
+
+// GetLocale returns the value of Locale.
+func (s *OrderRequest) GetLocale() string {
+	return s.Locale
+}
+
+// GetInternalSignature returns the value of InternalSignature.
+func (s *OrderRequest) GetInternalSignature() OptString {
+	return s.InternalSignature
+}
+
+// GetCustomerID returns the value of CustomerID.
+func (s *OrderRequest) GetCustomerID() string {
+	return s.CustomerID
+}
+
+// GetDeliveryService returns the value of DeliveryService.
+func (s *OrderRequest) GetDeliveryService() string {
+	return s.DeliveryService
+}
+
+// GetShardkey returns the value of Shardkey.
+func (s *OrderRequest) GetShardkey() string {
+	return s.Shardkey
+}
+
+// GetSmID returns the value of SmID.
+func (s *OrderRequest) GetSmID() int {
+	return s.SmID
+}
+
+// GetDateCreated returns the value of DateCreated.
+func (s *OrderRequest) GetDateCreated() time.Time {
+	return s.DateCreated
+}
+
+// GetOofShard returns the value of OofShard.
+func (s *OrderRequest) GetOofShard() string {
+	return s.OofShard
+}
+
+// GetVersion returns the value of Version.
+func (s *OrderRequest) GetVersion() OptInt {
+	return s.Version
+}
+
+// SetOrderUID sets the value of OrderUID.
+func (s *OrderRequest) SetOrderUID(val string) {
+	s.OrderUID = val
+}
+
+// SetTrackNumber sets the value of TrackNumber.
+func (s *OrderRequest) SetTrackNumber(val string) {
+	s.TrackNumber = val
+}
+
+// SetEntry sets the value of Entry.
+func (s *OrderRequest) SetEntry(val string) {
+	s.Entry = val
+}
+
+// SetDelivery sets the value of Delivery.
+func (s *OrderRequest) SetDelivery(val Delivery) {
+	s.Delivery = val
+}
+
+// SetPayment sets the value of Payment.
+func (s *OrderRequest) SetPayment(val Payment) {
+	s.Payment = val
+}
+
+// SetItems sets the value of Items.
+func (s *OrderRequest) SetItems(val []OrderItem) {
+	s.Items = val
+}
+
+// SetLocale sets the value of Locale.
+func (s *OrderRequest) SetLocale(val string) {
+	s.Locale = val
+}
+
+// SetInternalSignature sets the value of InternalSignature.
+func (s *OrderRequest) SetInternalSignature(val OptString) {
+	s.InternalSignature = val
+}
+
+// SetCustomerID sets the value of CustomerID.
+func (s *OrderRequest) SetCustomerID(val string) {
+	s.CustomerID = val
+}
+
+// SetDeliveryService sets the value of DeliveryService.
+func (s *OrderRequest) SetDeliveryService(val string) {
+	s.DeliveryService = val
+}
+
+// SetShardkey sets the value of Shardkey.
+func (s *OrderRequest) SetShardkey(val string) {
+	s.Shardkey = val
+}
+
+// SetSmID sets the value of SmID.
+func (s *OrderRequest) SetSmID(val int) {
+	s.SmID = val
+}
+
+// SetDateCreated sets the value of DateCreated.
+func (s *OrderRequest) SetDateCreated(val time.Time) {
+	s.DateCreated = val
+}
+
+// SetOofShard sets the value of OofShard.
+func (s *OrderRequest) SetOofShard(val string) {
+	s.OofShard = val
+}
+
+// SetVersion sets the value of Version.
+func (s *OrderRequest) SetVersion(val OptInt) {
+	s.Version = val
+}
+
+// Ref: #/components/schemas/OrderResponse
+type OrderResponse struct {
+	OrderUID          string              `json:"order_uid"`
+	TrackNumber       string              `json:"track_number"`
+	Entry             string              `json:"entry"`
+	Delivery          Delivery            `json:"delivery"`
+	Payment           Payment             `json:"payment"`
+	Items             []OrderItemResponse `json:"items"`
+	Locale            string              `json:"locale"`
+	InternalSignature OptString           `json:"internal_signature"`
+	CustomerID        string              `json:"customer_id"`
+	DeliveryService   string              `json:"delivery_service"`
+	Shardkey          string              `json:"shardkey"`
+	SmID              int                 `json:"sm_id"`
+	DateCreated       time.Time           `json:"date_created"`
+	OofShard          string              `json:"oof_shard"`
+	Version           int                 `json:"version"`
+	// When the order was first stored.
+	CreatedAt time.Time `json:"created_at"`
+	// When the stored order was last replaced by a bigger version.
+	UpdatedAt time.Time   `json:"updated_at"`
+	Status    OrderStatus `json:"status"`
+	// Status changes, oldest first, the last one is the current status.
+	StatusTimeline []OrderStatusEvent `json:"status_timeline"`
 }
 
 // GetOrderUID returns the value of OrderUID.
@@ -389,7 +1554,7 @@ func (s *OrderResponse) GetPayment() Payment {
 }
 
 // GetItems returns the value of Items.
-func (s *OrderResponse) GetItems() []OrderItem {
+func (s *OrderResponse) GetItems() []OrderItemResponse {
 	return s.Items
 }
 
@@ -433,6 +1598,31 @@ func (s *OrderResponse) GetOofShard() string {
 	return s.OofShard
 }
 
+// GetVersion returns the value of Version.
+func (s *OrderResponse) GetVersion() int {
+	return s.Version
+}
+
+// GetCreatedAt returns the value of CreatedAt.
+func (s *OrderResponse) GetCreatedAt() time.Time {
+	return s.CreatedAt
+}
+
+// GetUpdatedAt returns the value of UpdatedAt.
+func (s *OrderResponse) GetUpdatedAt() time.Time {
+	return s.UpdatedAt
+}
+
+// GetStatus returns the value of Status.
+func (s *OrderResponse) GetStatus() OrderStatus {
+	return s.Status
+}
+
+// GetStatusTimeline returns the value of StatusTimeline.
+func (s *OrderResponse) GetStatusTimeline() []OrderStatusEvent {
+	return s.StatusTimeline
+}
+
 // SetOrderUID sets the value of OrderUID.
 func (s *OrderResponse) SetOrderUID(val string) {
 	s.OrderUID = val
@@ -459,7 +1649,7 @@ func (s *OrderResponse) SetPayment(val Payment) {
 }
 
 // SetItems sets the value of Items.
-func (s *OrderResponse) SetItems(val []OrderItem) {
+func (s *OrderResponse) SetItems(val []OrderItemResponse) {
 	s.Items = val
 }
 
@@ -503,7 +1693,200 @@ func (s *OrderResponse) SetOofShard(val string) {
 	s.OofShard = val
 }
 
+// SetVersion sets the value of Version.
+func (s *OrderResponse) SetVersion(val int) {
+	s.Version = val
+}
+
+// SetCreatedAt sets the value of CreatedAt.
+func (s *OrderResponse) SetCreatedAt(val time.Time) {
+	s.CreatedAt = val
+}
+
+// SetUpdatedAt sets the value of UpdatedAt.
+func (s *OrderResponse) SetUpdatedAt(val time.Time) {
+	s.UpdatedAt = val
+}
+
+// SetStatus sets the value of Status.
+func (s *OrderResponse) SetStatus(val OrderStatus) {
+	s.Status = val
+}
+
+// SetStatusTimeline sets the value of StatusTimeline.
+func (s *OrderResponse) SetStatusTimeline(val []OrderStatusEvent) {
+	s.StatusTimeline = val
+}
+
 func (*OrderResponse) orderIDGetRes() {}
+func (*OrderResponse) ordersPostRes() {}
+
+// Lifecycle stage of the order.
+// Ref: #/components/schemas/OrderStatus
+type OrderStatus string
+
+const (
+	OrderStatusCreated   OrderStatus = "created"
+	OrderStatusPaid      OrderStatus = "paid"
+	OrderStatusAssembled OrderStatus = "assembled"
+	OrderStatusShipped   OrderStatus = "shipped"
+	OrderStatusDelivered OrderStatus = "delivered"
+	OrderStatusCancelled OrderStatus = "cancelled"
+	OrderStatusReturned  OrderStatus = "returned"
+)
+
+// AllValues returns all OrderStatus values.
+func (OrderStatus) AllValues() []OrderStatus {
+	return []OrderStatus{
+		OrderStatusCreated,
+		OrderStatusPaid,
+		OrderStatusAssembled,
+		OrderStatusShipped,
+		OrderStatusDelivered,
+		OrderStatusCancelled,
+		OrderStatusReturned,
+	}
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (s OrderStatus) MarshalText() ([]byte, error) {
+	switch s {
+	case OrderStatusCreated:
+		return []byte(s), nil
+	case OrderStatusPaid:
+		return []byte(s), nil
+	case OrderStatusAssembled:
+		return []byte(s), nil
+	case OrderStatusShipped:
+		return []byte(s), nil
+	case OrderStatusDelivered:
+		return []byte(s), nil
+	case OrderStatusCancelled:
+		return []byte(s), nil
+	case OrderStatusReturned:
+		return []byte(s), nil
+	default:
+		return nil, errors.Errorf("invalid value: %q", s)
+	}
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (s *OrderStatus) UnmarshalText(data []byte) error {
+	switch OrderStatus(data) {
+	case OrderStatusCreated:
+		*s = OrderStatusCreated
+		return nil
+	case OrderStatusPaid:
+		*s = OrderStatusPaid
+		return nil
+	case OrderStatusAssembled:
+		*s = OrderStatusAssembled
+		return nil
+	case OrderStatusShipped:
+		*s = OrderStatusShipped
+		return nil
+	case OrderStatusDelivered:
+		*s = OrderStatusDelivered
+		return nil
+	case OrderStatusCancelled:
+		*s = OrderStatusCancelled
+		return nil
+	case OrderStatusReturned:
+		*s = OrderStatusReturned
+		return nil
+	default:
+		return errors.Errorf("invalid value: %q", data)
+	}
+}
+
+// Ref: #/components/schemas/OrderStatusChangeRequest
+type OrderStatusChangeRequest struct {
+	// Changes the status of the item with this nm_id instead of the order.
+	NmID    OptInt64    `json:"nm_id"`
+	Status  OrderStatus `json:"status"`
+	Comment OptString   `json:"comment"`
+}
+
+// GetNmID returns the value of NmID.
+func (s *OrderStatusChangeRequest) GetNmID() OptInt64 {
+	return s.NmID
+}
+
+// GetStatus returns the value of Status.
+func (s *OrderStatusChangeRequest) GetStatus() OrderStatus {
+	return s.Status
+}
+
+// GetComment returns the value of Comment.
+func (s *OrderStatusChangeRequest) GetComment() OptString {
+	return s.Comment
+}
+
+// SetNmID sets the value of NmID.
+func (s *OrderStatusChangeRequest) SetNmID(val OptInt64) {
+	s.NmID = val
+}
+
+// SetStatus sets the value of Status.
+func (s *OrderStatusChangeRequest) SetStatus(val OrderStatus) {
+	s.Status = val
+}
+
+// SetComment sets the value of Comment.
+func (s *OrderStatusChangeRequest) SetComment(val OptString) {
+	s.Comment = val
+}
+
+// Ref: #/components/schemas/OrderStatusEvent
+type OrderStatusEvent struct {
+	// The item whose status is changed, absent for the order itself.
+	NmID      OptInt64    `json:"nm_id"`
+	Status    OrderStatus `json:"status"`
+	Comment   string      `json:"comment"`
+	CreatedAt time.Time   `json:"created_at"`
+}
+
+// GetNmID returns the value of NmID.
+func (s *OrderStatusEvent) GetNmID() OptInt64 {
+	return s.NmID
+}
+
+// GetStatus returns the value of Status.
+func (s *OrderStatusEvent) GetStatus() OrderStatus {
+	return s.Status
+}
+
+// GetComment returns the value of Comment.
+func (s *OrderStatusEvent) GetComment() string {
+	return s.Comment
+}
+
+// GetCreatedAt returns the value of CreatedAt.
+func (s *OrderStatusEvent) GetCreatedAt() time.Time {
+	return s.CreatedAt
+}
+
+// SetNmID sets the value of NmID.
+func (s *OrderStatusEvent) SetNmID(val OptInt64) {
+	s.NmID = val
+}
+
+// SetStatus sets the value of Status.
+func (s *OrderStatusEvent) SetStatus(val OrderStatus) {
+	s.Status = val
+}
+
+// SetComment sets the value of Comment.
+func (s *OrderStatusEvent) SetComment(val string) {
+	s.Comment = val
+}
+
+// SetCreatedAt sets the value of CreatedAt.
+func (s *OrderStatusEvent) SetCreatedAt(val time.Time) {
+	s.CreatedAt = val
+}
+
+func (*OrderStatusEvent) orderIDStatusPostRes() {}
 
 // Ref: #/components/schemas/Payment
 type Payment struct {
@@ -618,3 +2001,22 @@ func (s *Payment) SetGoodsTotal(val int) {
 func (s *Payment) SetCustomFee(val int) {
 	s.CustomFee = val
 }
+
+// Merged schema.
+// Ref: #/components/schemas/ValidationErrorResponse
+type ValidationErrorResponse struct {
+	// Merged property.
+	Message string `json:"message"`
+}
+
+// GetMessage returns the value of Message.
+func (s *ValidationErrorResponse) GetMessage() string {
+	return s.Message
+}
+
+// SetMessage sets the value of Message.
+func (s *ValidationErrorResponse) SetMessage(val string) {
+	s.Message = val
+}
+
+func (*ValidationErrorResponse) ordersPostRes() {}
@@ -8,12 +8,88 @@ import (
 
 // Handler handles operations described by OpenAPI v3 specification.
 type Handler interface {
+	// AdminCacheGet implements GET /admin/cache operation.
+	//
+	// Returns hit, miss, set, eviction and expiry counters of the orders cache since the start, the
+	// amount of coalesced reads on misses, its size and the most and least recently used order IDs.
+	//
+	// GET /admin/cache
+	AdminCacheGet(ctx context.Context) (*CacheStatsResponse, error)
+	// AdminDlqDelete implements DELETE /admin/dlq operation.
+	//
+	// Deletes every dead-lettered order that matches the filters.
+	//
+	// DELETE /admin/dlq
+	AdminDlqDelete(ctx context.Context, params AdminDlqDeleteParams) (AdminDlqDeleteRes, error)
+	// AdminDlqGet implements GET /admin/dlq operation.
+	//
+	// Returns dead-lettered orders with their failure reason, oldest first. Pass next_after_id of the
+	// previous page as after_id to get the next one.
+	//
+	// GET /admin/dlq
+	AdminDlqGet(ctx context.Context, params AdminDlqGetParams) (AdminDlqGetRes, error)
+	// AdminDlqIDDelete implements DELETE /admin/dlq/{id} operation.
+	//
+	// Delete dead-lettered order by ID.
+	//
+	// DELETE /admin/dlq/{id}
+	AdminDlqIDDelete(ctx context.Context, params AdminDlqIDDeleteParams) (AdminDlqIDDeleteRes, error)
+	// AdminDlqIDGet implements GET /admin/dlq/{id} operation.
+	//
+	// Get dead-lettered order by ID.
+	//
+	// GET /admin/dlq/{id}
+	AdminDlqIDGet(ctx context.Context, params AdminDlqIDGetParams) (AdminDlqIDGetRes, error)
+	// AdminDlqIDReplayPost implements POST /admin/dlq/{id}/replay operation.
+	//
+	// Replays the entry through order validation and processing. Replaying is idempotent.
+	//
+	// POST /admin/dlq/{id}/replay
+	AdminDlqIDReplayPost(ctx context.Context, params AdminDlqIDReplayPostParams) (AdminDlqIDReplayPostRes, error)
+	// AdminDlqReplayPost implements POST /admin/dlq/replay operation.
+	//
+	// Replays not yet replayed entries that match the filter through order validation and processing.
+	// Replaying is idempotent, the outcome is reported for every entry.
+	//
+	// POST /admin/dlq/replay
+	AdminDlqReplayPost(ctx context.Context, req *DeadLetterReplayRequest) (AdminDlqReplayPostRes, error)
+	// OrderIDDelete implements DELETE /order/{id} operation.
+	//
+	// Deletes the order with its items, versions history and status timeline. The cached order is
+	// invalidated, an order.deleted event is published.
+	//
+	// DELETE /order/{id}
+	OrderIDDelete(ctx context.Context, params OrderIDDeleteParams) (OrderIDDeleteRes, error)
 	// OrderIDGet implements GET /order/{id} operation.
 	//
 	// Returns the order details for the given order ID.
 	//
 	// GET /order/{id}
 	OrderIDGet(ctx context.Context, params OrderIDGetParams) (OrderIDGetRes, error)
+	// OrderIDStatusPost implements POST /order/{id}/status operation.
+	//
+	// Appends a status to the order timeline, or to the timeline of its item if nm_id is given. Items
+	// have the same lifecycle as orders, but their own. Allowed transitions are created -> paid |
+	// cancelled, paid -> assembled | cancelled, assembled -> shipped | cancelled, shipped -> delivered,
+	// delivered -> returned. Cancelled and returned orders are final.
+	//
+	// POST /order/{id}/status
+	OrderIDStatusPost(ctx context.Context, req *OrderStatusChangeRequest, params OrderIDStatusPostParams) (OrderIDStatusPostRes, error)
+	// OrdersGet implements GET /orders operation.
+	//
+	// Returns a page of orders, newest first, filtered by the given fields. Pagination is keyset-based,
+	// pass next_cursor of the previous page to get the next one.
+	//
+	// GET /orders
+	OrdersGet(ctx context.Context, params OrdersGetParams) (OrdersGetRes, error)
+	// OrdersPost implements POST /orders operation.
+	//
+	// Validates and saves the order synchronously, same way as orders received from Kafka. Saving is
+	// idempotent, sending exactly the same order again is a success. An order with a known order_uid and
+	// a bigger version replaces the stored one, a smaller version is ignored.
+	//
+	// POST /orders
+	OrdersPost(ctx context.Context, req *OrderRequest) (OrdersPostRes, error)
 	// NewError creates *ErrorResponseStatusCode from error returned by handler.
 	//
 	// Used for common default response.
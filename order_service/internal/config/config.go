@@ -4,7 +4,10 @@ import (
 	"fmt"
 	"github.com/ilyakaznacheev/cleanenv"
 	"order_service/pkg/kafka"
+	"order_service/pkg/pkgports/adapters/backoff"
 	"order_service/pkg/postgres"
+	"order_service/pkg/redis"
+	"os"
 )
 
 // OrderServiceConfig is named after the microservice, not the service struct!
@@ -15,12 +18,65 @@ type OrderServiceConfig struct {
 	KafkaGroupID string `yaml:"kafka_group_id" env:"KAFKA_GROUP_ID"`
 	HTTPPort     int    `yaml:"http_port" env:"HTTP_PORT"`
 
+	// ReceiverWorkers is the amount of orders from kafka processed concurrently
+	ReceiverWorkers int `yaml:"receiver_workers" env:"RECEIVER_WORKERS" env-default:"8"`
+	// ReceiverBatchSize > 1 turns on batch mode: orders are saved in batches of up to this size,
+	// collected no longer than ReceiverBatchWaitMs
+	ReceiverBatchSize   int `yaml:"receiver_batch_size" env:"RECEIVER_BATCH_SIZE" env-default:"1"`
+	ReceiverBatchWaitMs int `yaml:"receiver_batch_wait_ms" env:"RECEIVER_BATCH_WAIT_MS" env-default:"100"`
+	// StrictDecoding rejects orders from kafka with unknown fields, they're sent to the DLQ
+	StrictDecoding bool `yaml:"strict_decoding" env:"STRICT_DECODING" env-default:"false"`
+
+	// CacheBackend is where orders are cached: "memory" is an LRU of every instance,
+	// "redis" is shared by all the instances, CacheCapacity and CacheExpiryIntervalMs aren't used then,
+	// "two_tier" is an LRU of CacheCapacity orders kept for CacheL1TTLMs in front of redis
+	CacheBackend string `yaml:"cache_backend" env:"CACHE_BACKEND" env-default:"memory"`
+	// CacheL1TTLMs is how long an order stays in the LRU of "two_tier", changes made by other instances
+	// are seen after it at most if their invalidation is lost
+	CacheL1TTLMs int `yaml:"cache_l1_ttl_ms" env:"CACHE_L1_TTL_MS" env-default:"10000"`
+	// CacheRedisKeyPrefix is prepended to order_uids in redis
+	CacheRedisKeyPrefix string `yaml:"cache_redis_key_prefix" env:"CACHE_REDIS_KEY_PREFIX" env-default:"order_service:orders:"`
+	// CacheInvalidationChannel is the redis pub/sub channel of "two_tier", changed order_uids are published there,
+	// so the other instances drop them from their LRU
+	CacheInvalidationChannel string `yaml:"cache_invalidation_channel" env:"CACHE_INVALIDATION_CHANNEL" env-default:"order_service:orders:invalidations"`
+
 	CacheCapacity              int `yaml:"cache_capacity" env:"CACHE_CAPACITY"`
 	CachedOrdersOnStartupCount int `yaml:"CACHED_ORDERS_ON_STARTUP_LIMIT" env:"CACHED_ORDERS_ON_STARTUP_LIMIT"`
+	// CacheTTLMs is how long an order stays cached, 0 means forever.
+	// Expired orders are removed every CacheExpiryIntervalMs, 0 means only when they're read
+	CacheTTLMs            int `yaml:"cache_ttl_ms" env:"CACHE_TTL_MS" env-default:"600000"`
+	CacheExpiryIntervalMs int `yaml:"cache_expiry_interval_ms" env:"CACHE_EXPIRY_INTERVAL_MS" env-default:"60000"`
+	// Up to NotFoundCacheCapacity order_uids that aren't stored are remembered for NotFoundCacheTTLMs,
+	// GET of them doesn't query the storage meanwhile
+	NotFoundCacheCapacity int `yaml:"not_found_cache_capacity" env:"NOT_FOUND_CACHE_CAPACITY" env-default:"10000"`
+	NotFoundCacheTTLMs    int `yaml:"not_found_cache_ttl_ms" env:"NOT_FOUND_CACHE_TTL_MS" env-default:"5000"`
 
 	MaxSaveRetriesAmount   int `yaml:"max_save_retries_amount" env:"MAX_SAVE_RETRIES_AMOUNT"`
 	MaxSaveRetriesCapacity int `yaml:"max_save_retries_capacity" env:"MAX_SAVE_RETRIES_CAPACITY"`
-	SaveBackoffSeconds     int `yaml:"save_backoff_seconds" env:"SAVE_BACKOFF_SECONDS"`
+	// SaveBackoff is the delay policy between retries of saving orders and dead letters
+	SaveBackoff backoff.Config `yaml:"save_backoff" env-prefix:"SAVE_BACKOFF_"`
+	// SaveBackoffSeconds is the fixed delay of SaveBackoff in older versions, see applyDeprecated
+	//
+	// Deprecated: use SaveBackoff
+	SaveBackoffSeconds int `yaml:"save_backoff_seconds" env:"SAVE_BACKOFF_SECONDS"`
+
+	// TopicCreationRetries and TopicCreationBackoff are used to create kafka topics on startup
+	TopicCreationRetries int            `yaml:"topic_creation_retries" env:"TOPIC_CREATION_RETRIES" env-default:"5"`
+	TopicCreationBackoff backoff.Config `yaml:"topic_creation_backoff" env-prefix:"TOPIC_CREATION_BACKOFF_"`
+
+	// KafkaDLQTopic receives invalid orders and orders that ran out of retries
+	KafkaDLQTopic string `yaml:"kafka_dlq_topic" env:"KAFKA_DLQ_TOPIC" env-default:"orders-dlq"`
+	// KafkaDLQGroupID is used by the collector that stores DLQ messages for the admin API
+	KafkaDLQGroupID string `yaml:"kafka_dlq_group_id" env:"KAFKA_DLQ_GROUP_ID" env-default:"order-service-dlq-collector"`
+
+	// KafkaOrderEventsTopic receives the events of the order_events outbox, e.g. a new order is saved
+	KafkaOrderEventsTopic string `yaml:"kafka_order_events_topic" env:"KAFKA_ORDER_EVENTS_TOPIC" env-default:"order-events"`
+	// OrderEventsBatchSize events are published at once, the outbox is polled every OrderEventsPollIntervalMs when empty
+	OrderEventsBatchSize      int `yaml:"order_events_batch_size" env:"ORDER_EVENTS_BATCH_SIZE" env-default:"100"`
+	OrderEventsPollIntervalMs int `yaml:"order_events_poll_interval_ms" env:"ORDER_EVENTS_POLL_INTERVAL_MS" env-default:"1000"`
+	// OrderEventsLeaseMs is how long claimed events aren't published by other replicas,
+	// events that aren't marked sent by then are published again
+	OrderEventsLeaseMs int `yaml:"order_events_lease_ms" env:"ORDER_EVENTS_LEASE_MS" env-default:"30000"`
 }
 
 // Config is the main, assembled config type
@@ -28,8 +84,16 @@ type Config struct {
 	OrderService OrderServiceConfig `yaml:"order_service" env-prefix:"ORDER_SERVICE_"`
 	Kafka        kafka.Config       `yaml:"kafka" env-prefix:"KAFKA_"`
 	Postgres     postgres.Config    `yaml:"postgres" env-prefix:"POSTGRES_"`
+	// Redis is used only if OrderService.CacheBackend is "redis" or "two_tier"
+	Redis redis.Config `yaml:"redis" env-prefix:"REDIS_"`
 }
 
+// Env keys of SaveBackoff that replace the deprecated ORDER_SERVICE_SAVE_BACKOFF_SECONDS
+const (
+	envSaveBackoffPolicy = "ORDER_SERVICE_SAVE_BACKOFF_POLICY"
+	envSaveBackoffBaseMs = "ORDER_SERVICE_SAVE_BACKOFF_BASE_MS"
+)
+
 // TryRead tries to read config from ENV and returns it on success
 func TryRead() (Config, error) {
 	var cfg Config
@@ -37,5 +101,22 @@ func TryRead() (Config, error) {
 		return Config{},
 			fmt.Errorf("failed to read env variables after accessing .env: %w", err)
 	}
+	cfg.OrderService.applyDeprecated()
 	return cfg, nil
 }
+
+// applyDeprecated keeps deprecated settings working
+//
+// SaveBackoffSeconds is a fixed SaveBackoff, it's used only if the policy and its base aren't set explicitly
+func (c *OrderServiceConfig) applyDeprecated() {
+	if c.SaveBackoffSeconds <= 0 {
+		return
+	}
+	_, policySet := os.LookupEnv(envSaveBackoffPolicy)
+	_, baseSet := os.LookupEnv(envSaveBackoffBaseMs)
+	if policySet || baseSet {
+		return
+	}
+	c.SaveBackoff.Policy = backoff.PolicyFixed
+	c.SaveBackoff.BaseMs = c.SaveBackoffSeconds * 1000
+}
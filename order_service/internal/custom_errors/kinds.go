@@ -0,0 +1,85 @@
+package customerrors
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Kind describes whether a failed operation is worth another try
+type Kind int
+
+const (
+	// KindUnknown is an error nobody has classified, it's safer to retry it
+	KindUnknown Kind = iota
+	// KindTransient is an error that might go away by itself, e.g. connection loss or a deadlock
+	KindTransient
+	// KindPermanent is an error that won't ever go away for the same input, e.g. a CHECK violation
+	KindPermanent
+	// KindConflict is an error caused by data that's already stored, e.g. a duplicate key
+	KindConflict
+)
+
+// String returns a name of the kind, e.g. for logs
+func (k Kind) String() string {
+	switch k {
+	case KindTransient:
+		return "transient"
+	case KindPermanent:
+		return "permanent"
+	case KindConflict:
+		return "conflict"
+	default:
+		return "unknown"
+	}
+}
+
+// ClassifiedError is an error with a Kind, Code is an implementation specific code (e.g. SQLSTATE)
+type ClassifiedError struct {
+	Kind Kind
+	Code string
+	Err  error
+}
+
+// NewClassifiedError wraps err with given kind and code
+func NewClassifiedError(kind Kind, code string, err error) *ClassifiedError {
+	return &ClassifiedError{Kind: kind, Code: code, Err: err}
+}
+
+// Error returns the wrapped error text with the kind and the code
+func (e *ClassifiedError) Error() string {
+	if e.Code == "" {
+		return fmt.Sprintf("%s error: %v", e.Kind, e.Err)
+	}
+	return fmt.Sprintf("%s error (code %s): %v", e.Kind, e.Code, e.Err)
+}
+
+// Unwrap returns the wrapped error
+func (e *ClassifiedError) Unwrap() error {
+	return e.Err
+}
+
+// KindOf returns the Kind of err
+//
+// ErrOrderConflict is always KindConflict, errors that aren't classified are KindUnknown
+func KindOf(err error) Kind {
+	if errors.Is(err, ErrOrderConflict) {
+		return KindConflict
+	}
+	var classified *ClassifiedError
+	if errors.As(err, &classified) {
+		return classified.Kind
+	}
+	return KindUnknown
+}
+
+// IsRetryable tells whether err might go away on another try
+//
+// Permanent errors and conflicts never go away, transient and unknown ones might
+func IsRetryable(err error) bool {
+	switch KindOf(err) {
+	case KindPermanent, KindConflict:
+		return false
+	default:
+		return true
+	}
+}
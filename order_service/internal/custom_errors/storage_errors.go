@@ -5,3 +5,33 @@ import "errors"
 // ErrOrderNotFound describes an error when the storage
 // was successfully checked but no order with given data was found
 var ErrOrderNotFound = errors.New("order not found")
+
+// ErrOrderAlreadySaved describes a situation when the storage
+// already has exactly the same order, e.g. a redelivered message
+//
+// It's not a failure: the order is stored, so callers should acknowledge it
+var ErrOrderAlreadySaved = errors.New("order already saved")
+
+// ErrOrderConflict describes an error when the storage
+// already has a different order with the same order_uid
+//
+// Saving it again won't ever succeed, so it mustn't be retried
+var ErrOrderConflict = errors.New("order conflicts with an existing one")
+
+// ErrOrderOutdated describes a situation when the storage
+// already has a newer version of the order
+//
+// It's not a failure: a late message mustn't overwrite fresh data, so callers should acknowledge it
+var ErrOrderOutdated = errors.New("order version is outdated")
+
+// ErrDeadLetterNotFound describes an error when the storage
+// was successfully checked but no dead letter with given ID was found
+var ErrDeadLetterNotFound = errors.New("dead letter not found")
+
+// ErrIllegalStatusTransition describes an error when the order status
+// can't be changed to the requested one, e.g. a delivered order can't be cancelled
+var ErrIllegalStatusTransition = errors.New("illegal order status transition")
+
+// ErrOrderItemNotFound describes an error when the order
+// was found but it has no item with given nm_id
+var ErrOrderItemNotFound = errors.New("order item not found")
@@ -0,0 +1,47 @@
+package httphandlers
+
+import (
+	"context"
+	"order_service/internal/api"
+	"order_service/internal/models"
+)
+
+// AdminCacheGet is the implementation of GET orders cache statistics endpoint
+func (s *OrderServiceHTTPHandler) AdminCacheGet(ctx context.Context) (*api.CacheStatsResponse, error) {
+	response := cacheStatsToResponse(s.service.GetCacheStats(ctx))
+	return &response, nil
+}
+
+// cacheStatsToResponse maps models.OrderCacheStats into the openapi response model, empty keys and tiers are omitted
+func cacheStatsToResponse(stats models.OrderCacheStats) api.CacheStatsResponse {
+	response := api.CacheStatsResponse{
+		Hits:      int64(stats.Hits),
+		Misses:    int64(stats.Misses),
+		HitRatio:  stats.HitRatio(),
+		Sets:      int64(stats.Sets),
+		Evictions: int64(stats.Evictions),
+		Expired:   int64(stats.Expired),
+		Coalesced: int64(stats.Coalesced),
+		Size:      stats.Size,
+		Capacity:  stats.Capacity,
+	}
+	for _, tier := range stats.Tiers {
+		response.Tiers = append(response.Tiers, api.CacheTierStats{
+			Hits:      int64(tier.Hits),
+			Misses:    int64(tier.Misses),
+			HitRatio:  tier.HitRatio(),
+			Sets:      int64(tier.Sets),
+			Evictions: int64(tier.Evictions),
+			Expired:   int64(tier.Expired),
+			Size:      tier.Size,
+			Capacity:  tier.Capacity,
+		})
+	}
+	if stats.MostUsedKey != "" {
+		response.MostUsedKey = api.NewOptString(stats.MostUsedKey)
+	}
+	if stats.LeastUsedKey != "" {
+		response.LeastUsedKey = api.NewOptString(stats.LeastUsedKey)
+	}
+	return response
+}
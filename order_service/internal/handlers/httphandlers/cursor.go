@@ -0,0 +1,44 @@
+package httphandlers
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"order_service/internal/models"
+	"strings"
+	"time"
+)
+
+// errInvalidCursor describes an error when a client passes a cursor that we haven't issued
+var errInvalidCursor = errors.New("invalid cursor")
+
+// encodeOrdersCursor makes an opaque string from models.OrdersCursor
+//
+// openapi date-time is only precise to seconds, but created_at isn't, so we pack it ourselves
+func encodeOrdersCursor(cursor models.OrdersCursor) string {
+	raw := cursor.CreatedAt.UTC().Format(time.RFC3339Nano) + "|" + cursor.OrderUID
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// decodeOrdersCursor is the reverse of encodeOrdersCursor
+func decodeOrdersCursor(cursor string) (models.OrdersCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return models.OrdersCursor{}, fmt.Errorf("%w: %v", errInvalidCursor, err)
+	}
+
+	createdAtString, orderUID, found := strings.Cut(string(raw), "|")
+	if !found || orderUID == "" {
+		return models.OrdersCursor{}, errInvalidCursor
+	}
+
+	createdAt, err := time.Parse(time.RFC3339Nano, createdAtString)
+	if err != nil {
+		return models.OrdersCursor{}, fmt.Errorf("%w: %v", errInvalidCursor, err)
+	}
+
+	return models.OrdersCursor{
+		CreatedAt: createdAt,
+		OrderUID:  orderUID,
+	}, nil
+}
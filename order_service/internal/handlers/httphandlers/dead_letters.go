@@ -0,0 +1,196 @@
+package httphandlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go.uber.org/zap"
+	"order_service/internal/api"
+	"order_service/internal/custom_errors"
+	"order_service/internal/models"
+	"order_service/pkg/logger"
+)
+
+// AdminDlqGet is the implementation of GET dead letters list endpoint
+func (s *OrderServiceHTTPHandler) AdminDlqGet(ctx context.Context, params api.AdminDlqGetParams) (api.AdminDlqGetRes, error) {
+	filter := models.DeadLettersFilter{
+		Limit:    params.Limit.Or(20),
+		AfterID:  params.AfterID.Or(0),
+		Reason:   params.Reason.Or(""),
+		OrderUID: params.OrderUID.Or(""),
+	}
+	if replayed, ok := params.Replayed.Get(); ok {
+		filter.Replayed = &replayed
+	}
+
+	// query service
+	result, err := s.deadLettersService.ListDeadLetters(ctx, filter)
+	if err != nil {
+		return &api.ErrorResponse{
+			Message: fmt.Errorf("couldn't list dead letters: %w", err).Error(),
+		}, nil
+	}
+
+	deadLetters := make([]api.DeadLetter, len(result))
+	for i, deadLetter := range result {
+		deadLetters[i] = deadLetterToResponse(deadLetter)
+	}
+
+	response := api.DeadLetterListResponse{
+		DeadLetters: deadLetters,
+	}
+	// a full page means there might be more
+	if len(result) == filter.Limit {
+		response.NextAfterID = api.NewOptInt64(result[len(result)-1].ID)
+	}
+
+	return &response, nil
+}
+
+// AdminDlqIDGet is the implementation of GET dead letter by id endpoint
+func (s *OrderServiceHTTPHandler) AdminDlqIDGet(ctx context.Context, params api.AdminDlqIDGetParams) (api.AdminDlqIDGetRes, error) {
+	// query service
+	result, err := s.deadLettersService.GetDeadLetter(ctx, params.ID)
+	if err != nil {
+		if errors.Is(err, customerrors.ErrDeadLetterNotFound) {
+			return &api.NotFoundErrorResponse{
+				Message: err.Error(),
+			}, nil
+		}
+
+		// unknown error
+		return &api.ErrorResponse{
+			Message: fmt.Errorf("couldn't get dead letter: %w", err).Error(),
+		}, nil
+	}
+
+	response := deadLetterToResponse(result)
+	return &response, nil
+}
+
+// AdminDlqIDDelete is the implementation of DELETE dead letter by id endpoint
+func (s *OrderServiceHTTPHandler) AdminDlqIDDelete(ctx context.Context, params api.AdminDlqIDDeleteParams) (api.AdminDlqIDDeleteRes, error) {
+	err := s.deadLettersService.DeleteDeadLetter(ctx, params.ID)
+	if err != nil {
+		if errors.Is(err, customerrors.ErrDeadLetterNotFound) {
+			return &api.NotFoundErrorResponse{
+				Message: err.Error(),
+			}, nil
+		}
+
+		// unknown error
+		return &api.ErrorResponse{
+			Message: fmt.Errorf("couldn't delete dead letter: %w", err).Error(),
+		}, nil
+	}
+
+	return &api.AdminDlqIDDeleteNoContent{}, nil
+}
+
+// AdminDlqDelete is the implementation of DELETE (purge) dead letters endpoint
+func (s *OrderServiceHTTPHandler) AdminDlqDelete(ctx context.Context, params api.AdminDlqDeleteParams) (api.AdminDlqDeleteRes, error) {
+	filter := models.DeadLettersFilter{
+		Reason:   params.Reason.Or(""),
+		OrderUID: params.OrderUID.Or(""),
+	}
+	if replayed, ok := params.Replayed.Get(); ok {
+		filter.Replayed = &replayed
+	}
+	if failedBefore, ok := params.FailedBefore.Get(); ok {
+		filter.FailedBefore = &failedBefore
+	}
+
+	deleted, err := s.deadLettersService.PurgeDeadLetters(ctx, filter)
+	if err != nil {
+		return &api.ErrorResponse{
+			Message: fmt.Errorf("couldn't purge dead letters: %w", err).Error(),
+		}, nil
+	}
+
+	return &api.DeadLetterPurgeResponse{
+		Deleted: deleted,
+	}, nil
+}
+
+// AdminDlqIDReplayPost is the implementation of POST replay dead letter by id endpoint
+func (s *OrderServiceHTTPHandler) AdminDlqIDReplayPost(ctx context.Context, params api.AdminDlqIDReplayPostParams) (api.AdminDlqIDReplayPostRes, error) {
+	result, err := s.deadLettersService.ReplayDeadLetter(ctx, params.ID)
+	if err != nil {
+		if errors.Is(err, customerrors.ErrDeadLetterNotFound) {
+			return &api.NotFoundErrorResponse{
+				Message: err.Error(),
+			}, nil
+		}
+
+		// unknown error
+		return &api.ErrorResponse{
+			Message: fmt.Errorf("couldn't replay dead letter: %w", err).Error(),
+		}, nil
+	}
+
+	logger.GetOrCreateLoggerFromCtx(ctx).Info(ctx, "replayed dead letter",
+		zap.Int64("id", result.ID), zap.String("outcome", string(result.Outcome)))
+
+	response := replayResultToResponse(result)
+	return &response, nil
+}
+
+// AdminDlqReplayPost is the implementation of POST replay dead letters batch endpoint
+func (s *OrderServiceHTTPHandler) AdminDlqReplayPost(ctx context.Context, req *api.DeadLetterReplayRequest) (api.AdminDlqReplayPostRes, error) {
+	filter := models.DeadLettersFilter{
+		Limit:    req.Limit.Or(100),
+		Reason:   req.Reason.Or(""),
+		OrderUID: req.OrderUID.Or(""),
+	}
+
+	result, err := s.deadLettersService.ReplayDeadLetters(ctx, filter)
+	if err != nil {
+		return &api.ErrorResponse{
+			Message: fmt.Errorf("couldn't replay dead letters: %w", err).Error(),
+		}, nil
+	}
+
+	results := make([]api.DeadLetterReplayResult, len(result))
+	for i, replayResult := range result {
+		results[i] = replayResultToResponse(replayResult)
+	}
+
+	return &api.DeadLetterReplayBatchResponse{
+		Results: results,
+	}, nil
+}
+
+// deadLetterToResponse maps models.DeadLetter into the openapi response model, an unknown origin is omitted
+func deadLetterToResponse(deadLetter models.DeadLetter) api.DeadLetter {
+	response := api.DeadLetter{
+		ID:       deadLetter.ID,
+		OrderUID: deadLetter.OrderUID,
+		Reason:   deadLetter.Reason,
+		Error:    deadLetter.Error,
+		Attempts: deadLetter.Attempts,
+		FailedAt: deadLetter.FailedAt,
+		Payload:  string(deadLetter.Payload),
+	}
+	if deadLetter.OriginalTopic != "" {
+		response.OriginalTopic = api.NewOptString(deadLetter.OriginalTopic)
+		response.OriginalPartition = api.NewOptInt(deadLetter.OriginalPartition)
+		response.OriginalOffset = api.NewOptInt64(deadLetter.OriginalOffset)
+	}
+	if deadLetter.ReplayedAt != nil {
+		response.ReplayedAt = api.NewOptDateTime(*deadLetter.ReplayedAt)
+	}
+	return response
+}
+
+// replayResultToResponse maps models.ReplayResult into the openapi response model
+func replayResultToResponse(result models.ReplayResult) api.DeadLetterReplayResult {
+	response := api.DeadLetterReplayResult{
+		ID:       result.ID,
+		OrderUID: result.OrderUID,
+		Outcome:  api.DeadLetterReplayResultOutcome(result.Outcome),
+	}
+	if result.Error != "" {
+		response.Error = api.NewOptString(result.Error)
+	}
+	return response
+}
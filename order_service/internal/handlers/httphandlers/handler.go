@@ -7,21 +7,26 @@ import (
 	"go.uber.org/zap"
 	"order_service/internal/api"
 	"order_service/internal/custom_errors"
+	"order_service/internal/models"
 	"order_service/internal/service"
+	"order_service/internal/validators"
 	"order_service/pkg/logger"
 )
 
 // OrderServiceHTTPHandler implements generated openapi handler
 //
-// Basically, is a wrapper around service.OrderService
+// Basically, is a wrapper around service.OrderService.
+// Admin endpoints of the DLQ are a wrapper around service.DeadLetterService
 type OrderServiceHTTPHandler struct {
-	service *service.OrderService
+	service            *service.OrderService
+	deadLettersService *service.DeadLetterService
 }
 
-// NewOrderServiceHTTPHandler creates a new OrderServiceHTTPHandler that uses given service
-func NewOrderServiceHTTPHandler(service *service.OrderService) *OrderServiceHTTPHandler {
+// NewOrderServiceHTTPHandler creates a new OrderServiceHTTPHandler that uses given services
+func NewOrderServiceHTTPHandler(service *service.OrderService, deadLettersService *service.DeadLetterService) *OrderServiceHTTPHandler {
 	return &OrderServiceHTTPHandler{
-		service: service,
+		service:            service,
+		deadLettersService: deadLettersService,
 	}
 }
 
@@ -45,9 +50,144 @@ func (s *OrderServiceHTTPHandler) OrderIDGet(ctx context.Context, params api.Ord
 		}, nil
 	}
 
-	items := make([]api.OrderItem, len(result.Items))
+	response := orderToResponse(result)
+
+	logger.GetOrCreateLoggerFromCtx(ctx).Info(ctx, "read order by id", zap.String("order_uid", orderUID))
+
+	return &response, nil
+}
+
+// OrderIDDelete is the implementation of DELETE order by id endpoint
+func (s *OrderServiceHTTPHandler) OrderIDDelete(ctx context.Context, params api.OrderIDDeleteParams) (api.OrderIDDeleteRes, error) {
+	err := s.service.DeleteOrder(ctx, params.ID)
+	if err != nil {
+		if errors.Is(err, customerrors.ErrOrderNotFound) {
+			return &api.NotFoundErrorResponse{
+				Message: err.Error(),
+			}, nil
+		}
+
+		// unknown error
+		return &api.ErrorResponse{
+			Message: fmt.Errorf("couldn't delete order: %w", err).Error(),
+		}, nil
+	}
+
+	logger.GetOrCreateLoggerFromCtx(ctx).Info(ctx, "deleted order by id", zap.String("order_uid", params.ID))
+
+	return &api.OrderIDDeleteNoContent{}, nil
+}
+
+// OrdersGet is the implementation of GET orders list endpoint
+//
+// Filters are passed to the service as is, the cursor must be the one we've issued
+func (s *OrderServiceHTTPHandler) OrdersGet(ctx context.Context, params api.OrdersGetParams) (api.OrdersGetRes, error) {
+	filter := models.OrdersFilter{
+		Limit:           params.Limit.Or(20),
+		CustomerID:      params.CustomerID.Or(""),
+		TrackNumber:     params.TrackNumber.Or(""),
+		DeliveryService: params.DeliveryService.Or(""),
+		Locale:          params.Locale.Or(""),
+	}
+
+	if cursorString, ok := params.Cursor.Get(); ok {
+		cursor, err := decodeOrdersCursor(cursorString)
+		if err != nil {
+			return &api.BadRequestErrorResponse{
+				Message: err.Error(),
+			}, nil
+		}
+		filter.After = &cursor
+	}
+
+	if dateFrom, ok := params.DateCreatedFrom.Get(); ok {
+		filter.DateCreatedFrom = &dateFrom
+	}
+	if dateTo, ok := params.DateCreatedTo.Get(); ok {
+		filter.DateCreatedTo = &dateTo
+	}
+	if filter.DateCreatedFrom != nil && filter.DateCreatedTo != nil && !filter.DateCreatedFrom.Before(*filter.DateCreatedTo) {
+		return &api.BadRequestErrorResponse{
+			Message: "date_created_from must be before date_created_to",
+		}, nil
+	}
+
+	// query service
+	result, err := s.service.ListOrders(ctx, filter)
+	if err != nil {
+		return &api.ErrorResponse{
+			Message: fmt.Errorf("couldn't list orders: %w", err).Error(),
+		}, nil
+	}
+
+	orders := make([]api.OrderResponse, len(result.Orders))
+	for i, order := range result.Orders {
+		orders[i] = orderToResponse(order)
+	}
+
+	response := api.OrderListResponse{
+		Orders: orders,
+	}
+	if result.NextCursor != nil {
+		response.NextCursor = api.NewOptString(encodeOrdersCursor(*result.NextCursor))
+	}
+
+	logger.GetOrCreateLoggerFromCtx(ctx).Info(ctx, "listed orders", zap.Int("count", len(orders)))
+
+	return &response, nil
+}
+
+// OrdersPost is the implementation of POST order endpoint
+//
+// The order goes through validators.ValidateOrder and service.OrderService UpsertOrder,
+// exactly as orders received from Kafka do: a known order_uid with a bigger version updates the order
+func (s *OrderServiceHTTPHandler) OrdersPost(ctx context.Context, req *api.OrderRequest) (api.OrdersPostRes, error) {
+	order := orderFromRequest(req)
+
+	// step 1: validate
+	err := validators.ValidateOrder(order)
+	if err != nil {
+		return &api.ValidationErrorResponse{
+			Message: fmt.Errorf("invalid order: %w", err).Error(),
+		}, nil
+	}
+
+	// step 2: save
+	err = s.service.UpsertOrder(ctx, order)
+	if err != nil {
+		if errors.Is(err, customerrors.ErrOrderConflict) {
+			return &api.ConflictErrorResponse{
+				Message: err.Error(),
+			}, nil
+		}
+
+		// unknown error
+		return &api.ErrorResponse{
+			Message: fmt.Errorf("couldn't save order: %w", err).Error(),
+		}, nil
+	}
+
+	// step 3: read it back
+	//   the storage sets the timestamps and statuses, and an outdated order is acknowledged with a bigger version stored
+	stored, err := s.service.GetOrder(ctx, order.OrderUID)
+	if err != nil {
+		return &api.ErrorResponse{
+			Message: fmt.Errorf("couldn't get saved order: %w", err).Error(),
+		}, nil
+	}
+
+	response := orderToResponse(stored)
+
+	logger.GetOrCreateLoggerFromCtx(ctx).Info(ctx, "created order", zap.String("order_uid", order.OrderUID))
+
+	return &response, nil
+}
+
+// orderToResponse maps models.Order with its status timelines into the openapi response model
+func orderToResponse(result models.Order) api.OrderResponse {
+	items := make([]api.OrderItemResponse, len(result.Items))
 	for i, item := range result.Items {
-		items[i] = api.OrderItem{
+		items[i] = api.OrderItemResponse{
 			ChrtID:      int64(item.ChrtID),
 			TrackNumber: item.TrackNumber,
 			Price:       item.Price,
@@ -59,10 +199,13 @@ func (s *OrderServiceHTTPHandler) OrderIDGet(ctx context.Context, params api.Ord
 			NmID:        int64(item.NmID),
 			Brand:       item.Brand,
 			Status:      item.Status,
+
+			LifecycleStatus: api.OrderStatus(models.CurrentOrderStatus(item.StatusTimeline)),
+			StatusTimeline:  timelineToResponse(item.StatusTimeline),
 		}
 	}
 
-	response := api.OrderResponse{
+	return api.OrderResponse{
 		OrderUID:    result.OrderUID,
 		TrackNumber: result.TrackNumber,
 		Entry:       result.Entry,
@@ -102,17 +245,78 @@ func (s *OrderServiceHTTPHandler) OrderIDGet(ctx context.Context, params api.Ord
 		SmID:            result.SmID,
 		DateCreated:     result.DateCreated,
 		OofShard:        result.OofShard,
+		Version:         result.Version,
+		CreatedAt:       result.CreatedAt,
+		UpdatedAt:       result.UpdatedAt,
+		Status:          api.OrderStatus(models.CurrentOrderStatus(result.StatusTimeline)),
+		StatusTimeline:  timelineToResponse(result.StatusTimeline),
 	}
+}
 
-	logger.GetOrCreateLoggerFromCtx(ctx).Info(ctx, "read order by id", zap.String("order_uid", orderUID))
+// orderFromRequest maps the openapi request model into models.Order
+func orderFromRequest(req *api.OrderRequest) models.Order {
+	items := make([]models.OrderItem, len(req.Items))
+	for i, item := range req.Items {
+		items[i] = models.OrderItem{
+			OrderID:     req.OrderUID,
+			ChrtID:      int(item.ChrtID),
+			TrackNumber: item.TrackNumber,
+			Price:       item.Price,
+			RID:         item.Rid,
+			Name:        item.Name,
+			Sale:        item.Sale,
+			Size:        item.Size,
+			TotalPrice:  item.TotalPrice,
+			NmID:        int(item.NmID),
+			Brand:       item.Brand,
+			Status:      item.Status,
+		}
+	}
 
-	return &response, nil
+	return models.Order{
+		OrderUID:    req.OrderUID,
+		TrackNumber: req.TrackNumber,
+		Entry:       req.Entry,
+		Delivery: models.Delivery{
+			OrderID: req.OrderUID,
+			Name:    req.Delivery.Name,
+			Phone:   req.Delivery.Phone,
+			Zip:     req.Delivery.Zip,
+			City:    req.Delivery.City,
+			Address: req.Delivery.Address,
+			Region:  req.Delivery.Region,
+			Email:   req.Delivery.Email,
+		},
+		Payment: models.Payment{
+			OrderID:      req.OrderUID,
+			Transaction:  req.Payment.Transaction,
+			RequestID:    req.Payment.RequestID.Or(""),
+			Currency:     req.Payment.Currency,
+			Provider:     req.Payment.Provider,
+			Amount:       req.Payment.Amount,
+			PaymentDt:    req.Payment.PaymentDt,
+			Bank:         req.Payment.Bank,
+			DeliveryCost: req.Payment.DeliveryCost,
+			GoodsTotal:   req.Payment.GoodsTotal,
+			CustomFee:    req.Payment.CustomFee,
+		},
+		Items:             items,
+		Locale:            req.Locale,
+		InternalSignature: req.InternalSignature.Or(""),
+		CustomerID:        req.CustomerID,
+		DeliveryService:   req.DeliveryService,
+		ShardKey:          req.Shardkey,
+		SmID:              req.SmID,
+		DateCreated:       req.DateCreated,
+		OofShard:          req.OofShard,
+		Version:           req.Version.Or(0),
+	}
 }
 
 // NewError is the required method that returns an openapi error from given basic error
 func (s *OrderServiceHTTPHandler) NewError(ctx context.Context, err error) *api.ErrorResponseStatusCode {
 	// handle custom errors whose status codes we know
-	if errors.Is(err, customerrors.ErrOrderNotFound) {
+	if errors.Is(err, customerrors.ErrOrderNotFound) || errors.Is(err, customerrors.ErrDeadLetterNotFound) {
 		return &api.ErrorResponseStatusCode{
 			StatusCode: 404,
 			Response: api.ErrorResponse{
@@ -121,6 +325,15 @@ func (s *OrderServiceHTTPHandler) NewError(ctx context.Context, err error) *api.
 		}
 	}
 
+	if errors.Is(err, customerrors.ErrOrderConflict) || errors.Is(err, customerrors.ErrIllegalStatusTransition) {
+		return &api.ErrorResponseStatusCode{
+			StatusCode: 409,
+			Response: api.ErrorResponse{
+				Message: err.Error(),
+			},
+		}
+	}
+
 	return &api.ErrorResponseStatusCode{
 		StatusCode: 500,
 		Response: api.ErrorResponse{
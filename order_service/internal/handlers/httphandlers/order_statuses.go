@@ -0,0 +1,70 @@
+package httphandlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go.uber.org/zap"
+	"order_service/internal/api"
+	"order_service/internal/custom_errors"
+	"order_service/internal/models"
+	"order_service/pkg/logger"
+)
+
+// OrderIDStatusPost is the implementation of POST order status endpoint
+//
+// The status of the item with nm_id is changed if it's given. Illegal transitions are rejected with 409,
+// the current status is left as is then
+func (s *OrderServiceHTTPHandler) OrderIDStatusPost(
+	ctx context.Context, req *api.OrderStatusChangeRequest, params api.OrderIDStatusPostParams,
+) (api.OrderIDStatusPostRes, error) {
+	event, err := s.service.ChangeOrderStatus(
+		ctx, params.ID, int(req.NmID.Or(0)), models.OrderStatus(req.Status), req.Comment.Or(""),
+	)
+	if err != nil {
+		if errors.Is(err, customerrors.ErrOrderNotFound) || errors.Is(err, customerrors.ErrOrderItemNotFound) {
+			return &api.NotFoundErrorResponse{
+				Message: err.Error(),
+			}, nil
+		}
+		if errors.Is(err, customerrors.ErrIllegalStatusTransition) {
+			return &api.ConflictErrorResponse{
+				Message: err.Error(),
+			}, nil
+		}
+
+		// unknown error
+		return &api.ErrorResponse{
+			Message: fmt.Errorf("couldn't change order status: %w", err).Error(),
+		}, nil
+	}
+
+	response := statusEventToResponse(event)
+
+	logger.GetOrCreateLoggerFromCtx(ctx).Info(ctx, "changed order status",
+		zap.String("order_uid", params.ID), zap.Int("nm_id", event.NmID), zap.String("status", string(event.Status)))
+
+	return &response, nil
+}
+
+// statusEventToResponse maps models.OrderStatusEvent into the openapi response model
+func statusEventToResponse(event models.OrderStatusEvent) api.OrderStatusEvent {
+	response := api.OrderStatusEvent{
+		Status:    api.OrderStatus(event.Status),
+		Comment:   event.Comment,
+		CreatedAt: event.CreatedAt,
+	}
+	if event.NmID != 0 {
+		response.NmID = api.NewOptInt64(int64(event.NmID))
+	}
+	return response
+}
+
+// timelineToResponse maps a status timeline into the openapi response models, never nil
+func timelineToResponse(timeline []models.OrderStatusEvent) []api.OrderStatusEvent {
+	result := make([]api.OrderStatusEvent, len(timeline))
+	for i, event := range timeline {
+		result[i] = statusEventToResponse(event)
+	}
+	return result
+}
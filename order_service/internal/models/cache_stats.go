@@ -0,0 +1,35 @@
+package models
+
+// OrderCacheTierStats are the counters of the orders cache or of one of its tiers
+type OrderCacheTierStats struct {
+	Hits      uint64
+	Misses    uint64
+	Sets      uint64
+	Evictions uint64
+	Expired   uint64
+	Size      int
+	Capacity  int
+}
+
+// HitRatio returns the share of hits among all the reads, 0 if there were none
+func (s OrderCacheTierStats) HitRatio() float64 {
+	reads := s.Hits + s.Misses
+	if reads == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(reads)
+}
+
+// OrderCacheStats are the counters of the orders cache with its most and least used order_uids
+//
+// The keys are empty if the cache is empty or doesn't know the order of its values
+type OrderCacheStats struct {
+	OrderCacheTierStats
+	// Coalesced is the amount of reads on cache misses that shared a storage read of another one
+	Coalesced uint64
+	// Tiers are the counters of every tier from L1 to L2, nil if the cache has a single one
+	Tiers []OrderCacheTierStats
+
+	MostUsedKey  string
+	LeastUsedKey string
+}
@@ -0,0 +1,59 @@
+package models
+
+import (
+	"time"
+)
+
+// DeadLetter is a message that couldn't be processed and was sent to the DLQ
+//
+// Payload is the original message value, OrderUID is empty if it isn't a decodable order.
+// OriginalTopic is empty if the DLQ message had no origin headers, the partition and the offset are 0 then
+type DeadLetter struct {
+	ID                int64
+	OrderUID          string
+	Reason            string
+	Error             string
+	Attempts          int
+	OriginalTopic     string
+	OriginalPartition int
+	OriginalOffset    int64
+	FailedAt          time.Time
+	Payload           []byte
+	CreatedAt         time.Time
+	ReplayedAt        *time.Time
+}
+
+// DeadLettersFilter describes which dead letters to list, replay or purge
+//
+// Empty string fields, zero numbers and nil pointers mean "no filter"
+type DeadLettersFilter struct {
+	Limit   int
+	AfterID int64
+
+	Reason       string
+	OrderUID     string
+	Replayed     *bool
+	FailedBefore *time.Time
+}
+
+// ReplayOutcome is a result of replaying 1 DeadLetter
+type ReplayOutcome string
+
+const (
+	// ReplayOutcomeReplayed means that the order was processed successfully
+	ReplayOutcomeReplayed ReplayOutcome = "replayed"
+	// ReplayOutcomeAlreadyReplayed means that the dead letter had been replayed before, nothing was done
+	ReplayOutcomeAlreadyReplayed ReplayOutcome = "already_replayed"
+	// ReplayOutcomeInvalid means that the payload couldn't be decoded or validated
+	ReplayOutcomeInvalid ReplayOutcome = "invalid"
+	// ReplayOutcomeFailed means that the order processing failed
+	ReplayOutcomeFailed ReplayOutcome = "failed"
+)
+
+// ReplayResult describes what happened to 1 DeadLetter on replay
+type ReplayResult struct {
+	ID       int64
+	OrderUID string
+	Outcome  ReplayOutcome
+	Error    string
+}
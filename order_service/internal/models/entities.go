@@ -19,12 +19,16 @@ type Order struct {
 	SmID              int       `json:"sm_id"`
 	DateCreated       time.Time `json:"date_created"`
 	OofShard          string    `json:"oof_shard"`
+	Version           int       `json:"version"` // an Order with the same OrderUID and a bigger Version replaces the stored one
 	CreatedAt         time.Time `json:"created_at"`
 	UpdatedAt         time.Time `json:"updated_at"`
 
 	Delivery Delivery    `json:"delivery"`
 	Payment  Payment     `json:"payment"`
 	Items    []OrderItem `json:"items"`
+
+	// StatusTimeline is set by the storage, it's cached with the order, see AttachStatusTimeline
+	StatusTimeline []OrderStatusEvent `json:"status_timeline,omitempty"`
 }
 
 // Delivery is an entity that linked with Order 1:1
@@ -68,4 +72,7 @@ type OrderItem struct {
 	NmID        int    `json:"nm_id"`
 	Brand       string `json:"brand"`
 	Status      int    `json:"status"`
+
+	// StatusTimeline is set by the storage as Order.StatusTimeline
+	StatusTimeline []OrderStatusEvent `json:"status_timeline,omitempty"`
 }
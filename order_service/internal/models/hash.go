@@ -0,0 +1,40 @@
+package models
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// ContentHash returns a hex sha256 of the order content as it was sent to the service
+//
+// Fields set by the storage (timestamps, nested order IDs, status timelines) and the Version are ignored,
+// so the same order received twice has the same hash
+func (o Order) ContentHash() (string, error) {
+	normalized := o
+	normalized.CreatedAt = time.Time{}
+	normalized.UpdatedAt = time.Time{}
+	normalized.Version = 0
+	normalized.StatusTimeline = nil
+	// postgres keeps microseconds and doesn't keep the time zone
+	normalized.DateCreated = o.DateCreated.UTC().Truncate(time.Microsecond)
+
+	normalized.Delivery.OrderID = ""
+	normalized.Payment.OrderID = ""
+	normalized.Items = make([]OrderItem, len(o.Items))
+	for i, item := range o.Items {
+		item.OrderID = ""
+		item.StatusTimeline = nil
+		normalized.Items[i] = item
+	}
+
+	content, err := json.Marshal(normalized)
+	if err != nil {
+		return "", fmt.Errorf("couldn't marshal order to hash it: %w", err)
+	}
+
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:]), nil
+}
@@ -0,0 +1,39 @@
+package models
+
+import (
+	"time"
+)
+
+// OrdersCursor is a keyset pagination cursor: position of the last Order on the previous page
+//
+// Orders are listed by (CreatedAt, OrderUID) descending, so the next page starts right after this pair
+type OrdersCursor struct {
+	CreatedAt time.Time
+	OrderUID  string
+}
+
+// OrdersFilter describes which orders to list and which page to return
+//
+// Empty string fields and nil pointers mean "no filter"
+type OrdersFilter struct {
+	Limit int
+	After *OrdersCursor
+
+	CustomerID      string
+	TrackNumber     string
+	DeliveryService string
+	Locale          string
+
+	// DateCreatedFrom is inclusive
+	DateCreatedFrom *time.Time
+	// DateCreatedTo is exclusive
+	DateCreatedTo *time.Time
+}
+
+// OrdersPage is a result of listing orders with OrdersFilter
+//
+// NextCursor is nil if there are no more orders
+type OrdersPage struct {
+	Orders     []Order
+	NextCursor *OrdersCursor
+}
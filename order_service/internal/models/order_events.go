@@ -0,0 +1,65 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// OrderEventSchemaVersion is the version of OrderEventPayload format, bumped on breaking changes
+const OrderEventSchemaVersion = 1
+
+// Types of OrderEvent
+const (
+	// OrderEventTypeSaved means that a new order was saved
+	OrderEventTypeSaved = "order.saved"
+	// OrderEventTypeUpdated means that a stored order was replaced with its newer version
+	OrderEventTypeUpdated = "order.updated"
+	// OrderEventTypeDeleted means that an order was deleted, the snapshot is the order before deletion
+	OrderEventTypeDeleted = "order.deleted"
+	// OrderEventTypeStatusChanged means that a status of an order or its item was changed, see Order.StatusTimeline
+	OrderEventTypeStatusChanged = "order.status_changed"
+)
+
+// OrderEvent is an outbox record, Payload is an OrderEventPayload JSON as it's published
+//
+// SentAt is nil until the event is published
+type OrderEvent struct {
+	ID        int64
+	OrderUID  string
+	Type      string
+	Payload   []byte
+	CreatedAt time.Time
+	SentAt    *time.Time
+}
+
+// OrderEventPayload is the published JSON of an OrderEvent, Order is the snapshot of the order after the event
+//
+// For OrderEventTypeDeleted it's the snapshot before the event, there's nothing after
+type OrderEventPayload struct {
+	SchemaVersion int       `json:"schema_version"`
+	Type          string    `json:"event_type"`
+	OrderUID      string    `json:"order_uid"`
+	OccurredAt    time.Time `json:"occurred_at"`
+	Order         Order     `json:"order"`
+}
+
+// NewOrderEvent creates an OrderEvent of given type with a snapshot of the order
+func NewOrderEvent(eventType string, order Order) (OrderEvent, error) {
+	payload, err := json.Marshal(OrderEventPayload{
+		SchemaVersion: OrderEventSchemaVersion,
+		Type:          eventType,
+		OrderUID:      order.OrderUID,
+		OccurredAt:    time.Now().UTC(),
+		Order:         order,
+	})
+	if err != nil {
+		return OrderEvent{}, fmt.Errorf("couldn't marshal order event payload: %w", err)
+	}
+
+	return OrderEvent{
+		OrderUID: order.OrderUID,
+		Type:     eventType,
+		Payload:  payload,
+	}, nil
+}
@@ -0,0 +1,92 @@
+package models
+
+import (
+	"time"
+)
+
+// OrderStatus is a lifecycle stage of an Order or an OrderItem, unlike the opaque OrderItem.Status
+//
+// Items have the same lifecycle as orders, but their own, e.g. one item of a delivered order might be returned
+type OrderStatus string
+
+// Statuses of an Order, every order starts as OrderStatusCreated
+const (
+	OrderStatusCreated   OrderStatus = "created"
+	OrderStatusPaid      OrderStatus = "paid"
+	OrderStatusAssembled OrderStatus = "assembled"
+	OrderStatusShipped   OrderStatus = "shipped"
+	OrderStatusDelivered OrderStatus = "delivered"
+	OrderStatusCancelled OrderStatus = "cancelled"
+	OrderStatusReturned  OrderStatus = "returned"
+)
+
+// orderStatusTransitions are the statuses every status can be changed to
+//
+// An order can be cancelled until it's shipped and returned once it's delivered,
+// cancelled and returned orders are final
+var orderStatusTransitions = map[OrderStatus][]OrderStatus{
+	OrderStatusCreated:   {OrderStatusPaid, OrderStatusCancelled},
+	OrderStatusPaid:      {OrderStatusAssembled, OrderStatusCancelled},
+	OrderStatusAssembled: {OrderStatusShipped, OrderStatusCancelled},
+	OrderStatusShipped:   {OrderStatusDelivered},
+	OrderStatusDelivered: {OrderStatusReturned},
+	OrderStatusCancelled: {},
+	OrderStatusReturned:  {},
+}
+
+// IsValid tells whether s is a known OrderStatus
+func (s OrderStatus) IsValid() bool {
+	_, ok := orderStatusTransitions[s]
+	return ok
+}
+
+// CanTransitionTo tells whether an order with status s can be changed to next
+func (s OrderStatus) CanTransitionTo(next OrderStatus) bool {
+	for _, allowed := range orderStatusTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+// OrderStatusEvent is a single change of an Order status, the timeline of an order is its events ordered by ID
+//
+// An event with NmID is a change of the OrderItem with such NmID, 0 means the order itself
+type OrderStatusEvent struct {
+	ID        int64       `json:"id"`
+	OrderUID  string      `json:"order_uid"`
+	NmID      int         `json:"nm_id,omitempty"`
+	Status    OrderStatus `json:"status"`
+	Comment   string      `json:"comment"`
+	CreatedAt time.Time   `json:"created_at"`
+}
+
+// AttachStatusTimeline puts the events of an order timeline into the order and its items, by NmID
+func (o *Order) AttachStatusTimeline(timeline []OrderStatusEvent) {
+	o.StatusTimeline = nil
+	for i := range o.Items {
+		o.Items[i].StatusTimeline = nil
+	}
+
+	for _, event := range timeline {
+		if event.NmID == 0 {
+			o.StatusTimeline = append(o.StatusTimeline, event)
+			continue
+		}
+		// events of items that a newer version of the order doesn't have are dropped
+		for i := range o.Items {
+			if o.Items[i].NmID == event.NmID {
+				o.Items[i].StatusTimeline = append(o.Items[i].StatusTimeline, event)
+			}
+		}
+	}
+}
+
+// CurrentOrderStatus returns the status of the last event of a timeline, OrderStatusCreated if it's empty
+func CurrentOrderStatus(timeline []OrderStatusEvent) OrderStatus {
+	if len(timeline) == 0 {
+		return OrderStatusCreated
+	}
+	return timeline[len(timeline)-1].Status
+}
@@ -1,14 +1,69 @@
 package cache
 
 import (
+	"fmt"
+	goredis "github.com/redis/go-redis/v9"
 	"order_service/internal/models"
 	"order_service/internal/ports"
+	"order_service/pkg/pkgports"
 	"order_service/pkg/pkgports/adapters/cache/lru"
+	"order_service/pkg/pkgports/adapters/cache/rediscache"
+	"order_service/pkg/pkgports/adapters/cache/tiered"
+	"time"
 )
 
+// Backends of the orders cache, see config.OrderServiceConfig.CacheBackend
+const (
+	BackendMemory  = "memory"
+	BackendRedis   = "redis"
+	BackendTwoTier = "two_tier"
+)
+
+// ErrUnknownBackend describes a cache backend that isn't BackendMemory, BackendRedis or BackendTwoTier
+var ErrUnknownBackend = fmt.Errorf("unknown cache backend, expected %q, %q or %q",
+	BackendMemory, BackendRedis, BackendTwoTier)
+
 // NewOrderCacheAdapterInMemoryLRU creates a new lru.CacheLRUInMemory
 //
-// Adapter for service: string as KeyType and models.Order as ValueType
-func NewOrderCacheAdapterInMemoryLRU(capacity int) ports.OrderCache {
-	return lru.NewCacheLRUInMemory[string, models.Order](capacity)
+// Adapter for service: string as KeyType and models.Order as ValueType.
+// It's returned as is, the caller runs its expiry loop
+func NewOrderCacheAdapterInMemoryLRU(capacity int, ttl, expiryInterval time.Duration) *lru.CacheLRUInMemory[string, models.Order] {
+	return lru.NewCacheLRUInMemoryWithTTL[string, models.Order](capacity, ttl, expiryInterval)
+}
+
+// NewOrderCacheAdapterRedis creates a new rediscache.CacheRedis
+//
+// Adapter for service: order_uids with given prefix are the keys, orders are saved as JSON
+func NewOrderCacheAdapterRedis(
+	client goredis.UniversalClient, prefix string, ttl time.Duration,
+) *rediscache.CacheRedis[string, models.Order] {
+	return rediscache.NewCacheRedis[string, models.Order](
+		client, rediscache.StringKeyCodec{}, rediscache.JSONCodec[models.Order]{}, prefix, ttl,
+	)
+}
+
+// NewOrderCacheInvalidationsAdapterRedis creates a new rediscache.Invalidations of order_uids on given pub/sub channel
+func NewOrderCacheInvalidationsAdapterRedis(
+	client goredis.UniversalClient, channel string,
+) pkgports.CacheInvalidations[string] {
+	return rediscache.NewInvalidations[string](client, rediscache.StringKeyCodec{}, channel)
+}
+
+// NewOrderCacheAdapterTwoTier creates a new tiered.CacheTwoTier
+//
+// Adapter for service: l1 is usually an in-memory LRU of this instance, l2 is a shared redis cache,
+// invalidations tell the other instances to drop their l1 orders.
+// It's returned as is, the caller runs its invalidations loop
+func NewOrderCacheAdapterTwoTier(
+	l1 ports.OrderCache, l2 pkgports.GenerationCache[string, models.Order],
+	invalidations pkgports.CacheInvalidations[string], l1TTL time.Duration,
+) *tiered.CacheTwoTier[string, models.Order] {
+	return tiered.NewCacheTwoTier[string, models.Order](l1, l2, invalidations, l1TTL)
+}
+
+// NewNotFoundOrderCacheAdapterInMemoryLRU creates a new lru.CacheLRUInMemory of order_uids that aren't stored
+//
+// There's no expiry loop: expired order_uids are removed when they're read or evicted by new ones
+func NewNotFoundOrderCacheAdapterInMemoryLRU(capacity int, ttl time.Duration) ports.NotFoundOrderCache {
+	return lru.NewCacheLRUInMemoryWithTTL[string, struct{}](capacity, ttl, 0)
 }
@@ -0,0 +1,72 @@
+package deadletters
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/segmentio/kafka-go"
+	"order_service/internal/models"
+	"order_service/internal/ports"
+	"order_service/pkg/pkgports/adapters/receiver"
+)
+
+// maxOrderUIDLength is the length of order_uid column, longer ones can't be a real order_uid
+const maxOrderUIDLength = 50
+
+// KafkaDeadLetterReceiver is the kafka implementation of ports.DeadLetterReceiver
+//
+// It reads the DLQ topic written by receiver.KafkaReceiver
+type KafkaDeadLetterReceiver struct {
+	reader *kafka.Reader
+}
+
+// NewKafkaDeadLetterReceiver creates a new *KafkaDeadLetterReceiver, returning it as a ports.DeadLetterReceiver
+func NewKafkaDeadLetterReceiver(reader *kafka.Reader) ports.DeadLetterReceiver[kafka.Message] {
+	return &KafkaDeadLetterReceiver{
+		reader: reader,
+	}
+}
+
+// Consume reads the next DLQ message and maps its headers into models.DeadLetter
+//
+// If the original payload is an order, its order_uid is filled
+func (k *KafkaDeadLetterReceiver) Consume(ctx context.Context) (models.DeadLetter, kafka.Message, error) {
+	msg, err := k.reader.FetchMessage(ctx)
+	if err != nil {
+		return models.DeadLetter{}, kafka.Message{}, fmt.Errorf("error while reading from kafka DLQ: %w", err)
+	}
+
+	info := receiver.ParseDLQMessage(msg)
+
+	// payload might be anything, it's fine if it's not an order
+	var orderID struct {
+		OrderUID string `json:"order_uid"`
+	}
+	_ = json.Unmarshal(msg.Value, &orderID)
+	if len(orderID.OrderUID) > maxOrderUIDLength {
+		orderID.OrderUID = ""
+	}
+
+	// an empty message still has a payload, NULL isn't allowed
+	payload := msg.Value
+	if payload == nil {
+		payload = []byte{}
+	}
+
+	return models.DeadLetter{
+		OrderUID:          orderID.OrderUID,
+		Reason:            info.Reason,
+		Error:             info.Error,
+		Attempts:          info.Attempts,
+		OriginalTopic:     info.OriginalTopic,
+		OriginalPartition: info.OriginalPartition,
+		OriginalOffset:    info.OriginalOffset,
+		FailedAt:          info.FailedAt,
+		Payload:           payload,
+	}, msg, nil
+}
+
+// Commit must be called after the dead letter is stored
+func (k *KafkaDeadLetterReceiver) Commit(ctx context.Context, givenMessage kafka.Message) error {
+	return k.reader.CommitMessages(ctx, givenMessage)
+}
@@ -0,0 +1,59 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"github.com/segmentio/kafka-go"
+	"order_service/internal/models"
+	"order_service/internal/ports"
+	"strconv"
+)
+
+// Headers of an order event message, the value is the models.OrderEventPayload JSON
+const (
+	HeaderEventID   = "event-id"
+	HeaderEventType = "event-type"
+)
+
+// KafkaOrderEventPublisher is the kafka implementation of ports.OrderEventPublisher
+//
+// Messages are keyed by order_uid, so the events of an order go to the same partition in order
+type KafkaOrderEventPublisher struct {
+	writer *kafka.Writer
+}
+
+// NewKafkaOrderEventPublisher creates a new *KafkaOrderEventPublisher, returning it as a ports.OrderEventPublisher
+//
+// writer must be synchronous, otherwise events are marked sent before they're written
+func NewKafkaOrderEventPublisher(writer *kafka.Writer) ports.OrderEventPublisher {
+	return &KafkaOrderEventPublisher{
+		writer: writer,
+	}
+}
+
+// PublishOrderEvents writes all the events in one call
+//
+// Consumers should dedupe by HeaderEventID, an event is published again if marking it sent fails
+func (k *KafkaOrderEventPublisher) PublishOrderEvents(ctx context.Context, events []models.OrderEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	msgs := make([]kafka.Message, len(events))
+	for i, event := range events {
+		msgs[i] = kafka.Message{
+			Key:   []byte(event.OrderUID),
+			Value: event.Payload,
+			Headers: []kafka.Header{
+				{Key: HeaderEventID, Value: []byte(strconv.FormatInt(event.ID, 10))},
+				{Key: HeaderEventType, Value: []byte(event.Type)},
+			},
+		}
+	}
+
+	err := k.writer.WriteMessages(ctx, msgs...)
+	if err != nil {
+		return fmt.Errorf("error while writing order events to kafka: %w", err)
+	}
+	return nil
+}
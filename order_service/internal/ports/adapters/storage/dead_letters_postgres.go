@@ -0,0 +1,218 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"github.com/Masterminds/squirrel"
+	"github.com/go-faster/errors"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"order_service/internal/custom_errors"
+	"order_service/internal/models"
+)
+
+// DeadLettersStoragePostgres is the postgres implementation of ports.DeadLetterStorage
+type DeadLettersStoragePostgres struct {
+	pool *pgxpool.Pool
+}
+
+// NewDeadLettersStoragePostgres creates a new *DeadLettersStoragePostgres with given DB pool
+func NewDeadLettersStoragePostgres(pool *pgxpool.Pool) *DeadLettersStoragePostgres {
+	return &DeadLettersStoragePostgres{
+		pool: pool,
+	}
+}
+
+// deadLetterColumns are the columns of models.DeadLetter, scan them with scanDeadLetter
+//
+// An unknown origin is NULL, it's scanned as zero values
+var deadLetterColumns = []string{
+	"id", "order_uid", "reason", "error", "attempts", "COALESCE(original_topic, '')",
+	"COALESCE(original_partition, 0)", "COALESCE(original_offset, 0)", "failed_at", "payload", "created_at", "replayed_at",
+}
+
+// scanDeadLetter scans a row selected with deadLetterColumns into given dead letter
+func scanDeadLetter(row pgx.Row, deadLetter *models.DeadLetter) error {
+	return row.Scan(
+		&deadLetter.ID, &deadLetter.OrderUID, &deadLetter.Reason, &deadLetter.Error, &deadLetter.Attempts,
+		&deadLetter.OriginalTopic, &deadLetter.OriginalPartition, &deadLetter.OriginalOffset,
+		&deadLetter.FailedAt, &deadLetter.Payload, &deadLetter.CreatedAt, &deadLetter.ReplayedAt,
+	)
+}
+
+// applyDeadLettersFilter adds WHERE clauses of the filter, except for pagination
+func applyDeadLettersFilter[Builder interface {
+	Where(pred interface{}, args ...interface{}) Builder
+}](query Builder, filter models.DeadLettersFilter) Builder {
+	if filter.Reason != "" {
+		query = query.Where(squirrel.Eq{"reason": filter.Reason})
+	}
+	if filter.OrderUID != "" {
+		query = query.Where(squirrel.Eq{"order_uid": filter.OrderUID})
+	}
+	if filter.Replayed != nil {
+		if *filter.Replayed {
+			query = query.Where(squirrel.NotEq{"replayed_at": nil})
+		} else {
+			query = query.Where(squirrel.Eq{"replayed_at": nil})
+		}
+	}
+	if filter.FailedBefore != nil {
+		query = query.Where(squirrel.Lt{"failed_at": *filter.FailedBefore})
+	}
+	return query
+}
+
+// SaveDeadLetter is implementation of such method in ports.DeadLetterStorage
+//
+// Saving is idempotent: a redelivered DLQ message is ignored.
+// A dead letter with an unknown origin is saved with NULLs there, such ones can't be told apart and are all saved
+func (d *DeadLettersStoragePostgres) SaveDeadLetter(ctx context.Context, deadLetter models.DeadLetter) error {
+	var originalTopic, originalPartition, originalOffset any
+	if deadLetter.OriginalTopic != "" {
+		originalTopic, originalPartition, originalOffset =
+			deadLetter.OriginalTopic, deadLetter.OriginalPartition, deadLetter.OriginalOffset
+	}
+
+	sql, args, err := squirrel.
+		Insert("order_service.dead_letters").
+		Columns(
+			"order_uid", "reason", "error", "attempts", "original_topic", "original_partition",
+			"original_offset", "failed_at", "payload",
+		).
+		Values(
+			deadLetter.OrderUID, deadLetter.Reason, deadLetter.Error, deadLetter.Attempts, originalTopic,
+			originalPartition, originalOffset, deadLetter.FailedAt, deadLetter.Payload,
+		).
+		Suffix("ON CONFLICT (original_topic, original_partition, original_offset) DO NOTHING").
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("couldn't build an SQL query: %w", err)
+	}
+
+	_, err = d.pool.Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("couldn't exec save dead letter query: %w", err)
+	}
+	return nil
+}
+
+// GetDeadLetter is implementation of such method in ports.DeadLetterStorage
+func (d *DeadLettersStoragePostgres) GetDeadLetter(ctx context.Context, id int64) (models.DeadLetter, error) {
+	sql, args, err := squirrel.Select(deadLetterColumns...).
+		From("order_service.dead_letters").
+		Where(squirrel.Eq{"id": id}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		return models.DeadLetter{}, fmt.Errorf("couldn't build an SQL query: %w", err)
+	}
+
+	var deadLetter models.DeadLetter
+	err = scanDeadLetter(d.pool.QueryRow(ctx, sql, args...), &deadLetter)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.DeadLetter{}, customerrors.ErrDeadLetterNotFound
+		}
+		return models.DeadLetter{}, fmt.Errorf("error mapping query result fields: %w", err)
+	}
+	return deadLetter, nil
+}
+
+// ListDeadLetters is implementation of such method in ports.DeadLetterStorage
+func (d *DeadLettersStoragePostgres) ListDeadLetters(ctx context.Context, filter models.DeadLettersFilter) ([]models.DeadLetter, error) {
+	query := squirrel.Select(deadLetterColumns...).
+		From("order_service.dead_letters").
+		Where(squirrel.Gt{"id": filter.AfterID})
+	query = applyDeadLettersFilter(query, filter)
+
+	sql, args, err := query.
+		OrderBy("id ASC").
+		Limit(uint64(filter.Limit)).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("couldn't build an SQL query: %w", err)
+	}
+
+	rows, err := d.pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't query dead letters: %w", err)
+	}
+	defer rows.Close()
+
+	var deadLetters = make([]models.DeadLetter, 0)
+	for rows.Next() {
+		var deadLetter models.DeadLetter
+		err = scanDeadLetter(rows, &deadLetter)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't scan dead letter: %w", err)
+		}
+		deadLetters = append(deadLetters, deadLetter)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error reading dead letters rows: %w", err)
+	}
+
+	return deadLetters, nil
+}
+
+// MarkDeadLetterReplayed is implementation of such method in ports.DeadLetterStorage
+//
+// replayed_at of already replayed dead letter isn't changed
+func (d *DeadLettersStoragePostgres) MarkDeadLetterReplayed(ctx context.Context, id int64) error {
+	sql, args, err := squirrel.Update("order_service.dead_letters").
+		Set("replayed_at", squirrel.Expr("CURRENT_TIMESTAMP")).
+		Where(squirrel.Eq{"id": id, "replayed_at": nil}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("couldn't build an SQL query: %w", err)
+	}
+
+	_, err = d.pool.Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("couldn't exec mark dead letter replayed query: %w", err)
+	}
+	return nil
+}
+
+// DeleteDeadLetter is implementation of such method in ports.DeadLetterStorage
+func (d *DeadLettersStoragePostgres) DeleteDeadLetter(ctx context.Context, id int64) error {
+	sql, args, err := squirrel.Delete("order_service.dead_letters").
+		Where(squirrel.Eq{"id": id}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("couldn't build an SQL query: %w", err)
+	}
+
+	result, err := d.pool.Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("couldn't exec delete dead letter query: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return customerrors.ErrDeadLetterNotFound
+	}
+	return nil
+}
+
+// PurgeDeadLetters is implementation of such method in ports.DeadLetterStorage
+func (d *DeadLettersStoragePostgres) PurgeDeadLetters(ctx context.Context, filter models.DeadLettersFilter) (int64, error) {
+	query := squirrel.Delete("order_service.dead_letters")
+	query = applyDeadLettersFilter(query, filter)
+
+	sql, args, err := query.
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("couldn't build an SQL query: %w", err)
+	}
+
+	result, err := d.pool.Exec(ctx, sql, args...)
+	if err != nil {
+		return 0, fmt.Errorf("couldn't exec purge dead letters query: %w", err)
+	}
+	return result.RowsAffected(), nil
+}
@@ -0,0 +1,77 @@
+package storage
+
+import (
+	"errors"
+	"github.com/jackc/pgx/v5/pgconn"
+	"order_service/internal/custom_errors"
+	"strings"
+)
+
+// SQLSTATE codes and classes that are classified in classifyError
+//
+// Full list: https://www.postgresql.org/docs/current/errcodes-appendix.html
+const (
+	sqlStateClassConnection        = "08" // connection_exception
+	sqlStateClassDataException     = "22" // e.g. string_data_right_truncation, numeric_value_out_of_range
+	sqlStateClassIntegrity         = "23" // integrity_constraint_violation
+	sqlStateClassInsufficientRes   = "53" // e.g. too_many_connections, out_of_memory
+	sqlStateClassOperatorIntervene = "57" // e.g. admin_shutdown, cannot_connect_now
+	sqlStateClassSyntaxOrAccess    = "42" // syntax_error_or_access_rule_violation
+
+	sqlStateUniqueViolation      = "23505"
+	sqlStateSerializationFailure = "40001"
+	sqlStateDeadlockDetected     = "40P01"
+	sqlStateLockNotAvailable     = "55P03"
+	sqlStateUndefinedTable       = "42P01"
+	sqlStateUndefinedColumn      = "42703"
+)
+
+// classifyError wraps err into customerrors.ClassifiedError according to its SQLSTATE code
+//
+// Errors that are already classified (e.g. customerrors.ErrOrderConflict) are returned as is
+func classifyError(err error) error {
+	if err == nil || customerrors.KindOf(err) != customerrors.KindUnknown {
+		return err
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		kind := ClassifySQLState(pgErr.Code)
+		if kind == customerrors.KindUnknown {
+			return err
+		}
+		return customerrors.NewClassifiedError(kind, pgErr.Code, err)
+	}
+
+	// the query hasn't reached the server or the connection is broken
+	if pgconn.SafeToRetry(err) || pgconn.Timeout(err) {
+		return customerrors.NewClassifiedError(customerrors.KindTransient, "", err)
+	}
+	return err
+}
+
+// ClassifySQLState returns the customerrors.Kind of given SQLSTATE code
+//
+// An undefined table or column is transient, unlike the rest of class 42:
+// it's expected while migrations are being rolled out, the query works once they're applied
+func ClassifySQLState(code string) customerrors.Kind {
+	switch code {
+	case sqlStateUniqueViolation:
+		return customerrors.KindConflict
+	case sqlStateSerializationFailure, sqlStateDeadlockDetected, sqlStateLockNotAvailable,
+		sqlStateUndefinedTable, sqlStateUndefinedColumn:
+		return customerrors.KindTransient
+	}
+
+	switch {
+	case strings.HasPrefix(code, sqlStateClassConnection),
+		strings.HasPrefix(code, sqlStateClassInsufficientRes),
+		strings.HasPrefix(code, sqlStateClassOperatorIntervene):
+		return customerrors.KindTransient
+	case strings.HasPrefix(code, sqlStateClassDataException),
+		strings.HasPrefix(code, sqlStateClassIntegrity),
+		strings.HasPrefix(code, sqlStateClassSyntaxOrAccess):
+		return customerrors.KindPermanent
+	}
+	return customerrors.KindUnknown
+}
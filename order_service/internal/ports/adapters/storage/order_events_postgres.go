@@ -0,0 +1,159 @@
+package storage
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"order_service/internal/models"
+	"slices"
+	"time"
+)
+
+// OrderEventsStoragePostgres is the postgres implementation of ports.OrderEventStorage
+//
+// Events are written by OrdersStoragePostgres in the transactions of orders, this is the reading side of the outbox.
+// Relays of all the replicas read it, events are leased with locked_until so only one of them publishes an event
+type OrderEventsStoragePostgres struct {
+	pool *pgxpool.Pool
+}
+
+// NewOrderEventsStoragePostgres creates a new *OrderEventsStoragePostgres with given DB pool
+func NewOrderEventsStoragePostgres(pool *pgxpool.Pool) *OrderEventsStoragePostgres {
+	return &OrderEventsStoragePostgres{
+		pool: pool,
+	}
+}
+
+// saveOrderEvent writes an event of given type with a snapshot of the order in given transaction
+func saveOrderEvent(ctx context.Context, transaction pgx.Tx, eventType string, order *models.Order) error {
+	event, err := models.NewOrderEvent(eventType, *order)
+	if err != nil {
+		return err
+	}
+
+	sql, args, err := squirrel.
+		Insert("order_service.order_events").
+		Columns("order_uid", "event_type", "payload").
+		Values(event.OrderUID, event.Type, event.Payload).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("couldn't build an SQL query: %w", err)
+	}
+
+	_, err = transaction.Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("couldn't exec save order event query: %w", err)
+	}
+	return nil
+}
+
+// ClaimUnsentOrderEvents is implementation of such method in ports.OrderEventStorage
+//
+// Claims of all the replicas are serialized by an advisory lock, so an event is claimed once per lease.
+// An event isn't claimed while an earlier event of its order is leased, so events of an order are published in order
+func (e *OrderEventsStoragePostgres) ClaimUnsentOrderEvents(
+	ctx context.Context, limit int, lease time.Duration,
+) (events []models.OrderEvent, err error) {
+	transaction, err := e.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't start transaction: %w", err)
+	}
+
+	// err is the named result, so errors of rollback and commit are actually returned
+	defer func() {
+		if err != nil {
+			rollbackErr := transaction.Rollback(ctx)
+			if rollbackErr != nil {
+				err = fmt.Errorf("error rolling back transaction: %w. caused after this error: %w",
+					rollbackErr, err)
+			}
+			return
+		}
+		err = transaction.Commit(ctx)
+		if err != nil {
+			err = fmt.Errorf("couldn't commit claim order events transaction: %w", err)
+		}
+	}()
+
+	// step 1. wait for claims of other replicas, it's released on commit
+	_, err = transaction.Exec(ctx, "SELECT pg_advisory_xact_lock(hashtext('order_service.order_events'))")
+	if err != nil {
+		return nil, fmt.Errorf("couldn't lock order events for claiming: %w", err)
+	}
+
+	// step 2. lease the earliest events that aren't sent or leased
+	claimable := squirrel.
+		Select("e.id").
+		From("order_service.order_events AS e").
+		Where(squirrel.Eq{"e.sent_at": nil}).
+		Where("(e.locked_until IS NULL OR e.locked_until < CURRENT_TIMESTAMP)").
+		Where(`NOT EXISTS (SELECT 1 FROM order_service.order_events AS earlier
+			WHERE earlier.order_uid = e.order_uid AND earlier.id < e.id
+			AND earlier.sent_at IS NULL AND earlier.locked_until >= CURRENT_TIMESTAMP)`).
+		OrderBy("e.id ASC").
+		Limit(uint64(limit)).
+		Suffix("FOR UPDATE SKIP LOCKED")
+
+	sql, args, err := squirrel.Update("order_service.order_events").
+		Set("locked_until", squirrel.Expr("CURRENT_TIMESTAMP + make_interval(secs => ?)", lease.Seconds())).
+		Where(squirrel.Expr("id IN (?)", claimable)).
+		Suffix("RETURNING id, order_uid, event_type, payload, created_at, sent_at").
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("couldn't build an SQL query: %w", err)
+	}
+
+	rows, err := transaction.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't query unsent order events: %w", err)
+	}
+	defer rows.Close()
+
+	events = make([]models.OrderEvent, 0)
+	for rows.Next() {
+		var event models.OrderEvent
+		err = rows.Scan(&event.ID, &event.OrderUID, &event.Type, &event.Payload, &event.CreatedAt, &event.SentAt)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't scan order event: %w", err)
+		}
+		events = append(events, event)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error reading order events rows: %w", err)
+	}
+
+	// RETURNING has no order
+	slices.SortFunc(events, func(a, b models.OrderEvent) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+	return events, nil
+}
+
+// MarkOrderEventsSent is implementation of such method in ports.OrderEventStorage
+//
+// sent_at of already sent events isn't changed
+func (e *OrderEventsStoragePostgres) MarkOrderEventsSent(ctx context.Context, ids []int64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	sql, args, err := squirrel.Update("order_service.order_events").
+		Set("sent_at", squirrel.Expr("CURRENT_TIMESTAMP")).
+		Where(squirrel.Eq{"id": ids, "sent_at": nil}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("couldn't build an SQL query: %w", err)
+	}
+
+	_, err = e.pool.Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("couldn't exec mark order events sent query: %w", err)
+	}
+	return nil
+}
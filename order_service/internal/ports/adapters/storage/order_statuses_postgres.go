@@ -0,0 +1,243 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"github.com/Masterminds/squirrel"
+	"github.com/go-faster/errors"
+	"github.com/jackc/pgx/v5"
+	"order_service/internal/custom_errors"
+	"order_service/internal/models"
+)
+
+// saveInitialOrderStatus writes the models.OrderStatusCreated event of a new order in given transaction
+func saveInitialOrderStatus(ctx context.Context, transaction pgx.Tx, orderUID string) error {
+	sql, args, err := squirrel.
+		Insert("order_service.order_status_events").
+		Columns("order_uid", "status").
+		Values(orderUID, models.OrderStatusCreated).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("couldn't build an SQL query: %w", err)
+	}
+
+	_, err = transaction.Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("couldn't exec save initial order status query: %w", err)
+	}
+	return nil
+}
+
+// getOrderStatusTimelines returns the status events of every given order by order_uid, ordered by ID,
+// events of the items are there too, see models.Order AttachStatusTimeline
+//
+// Orders without events aren't in the result
+func getOrderStatusTimelines(
+	ctx context.Context, q querier, orderUIDs []string,
+) (map[string][]models.OrderStatusEvent, error) {
+	timelines := make(map[string][]models.OrderStatusEvent, len(orderUIDs))
+	if len(orderUIDs) == 0 {
+		return timelines, nil
+	}
+
+	sql, args, err := squirrel.Select("id", "order_uid", "COALESCE(nm_id, 0)", "status", "comment", "created_at").
+		From("order_service.order_status_events").
+		Where(squirrel.Eq{"order_uid": orderUIDs}).
+		OrderBy("order_uid", "id").
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("couldn't build an SQL query: %w", err)
+	}
+
+	rows, err := q.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't query order status events: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var event models.OrderStatusEvent
+		err = rows.Scan(&event.ID, &event.OrderUID, &event.NmID, &event.Status, &event.Comment, &event.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't scan order status event: %w", err)
+		}
+		timelines[event.OrderUID] = append(timelines[event.OrderUID], event)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error reading order status events rows: %w", err)
+	}
+	return timelines, nil
+}
+
+// attachStatusTimelines queries the status timelines of all the orders at once and puts them into orders
+func (o *OrdersStoragePostgres) attachStatusTimelines(ctx context.Context, ordersList []models.Order) error {
+	orderUIDs := make([]string, len(ordersList))
+	for i, order := range ordersList {
+		orderUIDs[i] = order.OrderUID
+	}
+
+	timelines, err := getOrderStatusTimelines(ctx, o.pool, orderUIDs)
+	if err != nil {
+		return err
+	}
+	for i := range ordersList {
+		ordersList[i].AttachStatusTimeline(timelines[ordersList[i].OrderUID])
+	}
+	return nil
+}
+
+// ChangeOrderStatus is implementation of such method in ports.OrderStorage
+//
+// The order is locked, so concurrent changes are checked against each other's result.
+// customerrors.ErrOrderNotFound, customerrors.ErrOrderItemNotFound and customerrors.ErrIllegalStatusTransition
+// are returned before anything is written. A models.OrderEventTypeStatusChanged event with the snapshot
+// of the order after the change is written to the outbox
+func (o *OrdersStoragePostgres) ChangeOrderStatus(
+	ctx context.Context, orderUID string, nmID int, status models.OrderStatus, comment string,
+) (event models.OrderStatusEvent, err error) {
+	// runs last, after commit or rollback
+	defer func() {
+		err = classifyError(err)
+	}()
+
+	transaction, err := o.pool.Begin(ctx)
+	if err != nil {
+		return models.OrderStatusEvent{}, fmt.Errorf("couldn't start transaction: %w", err)
+	}
+
+	// err is the named result, so errors of rollback and commit are actually returned
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("error changing order status transaction, rolling back: %w", err)
+			rollbackErr := transaction.Rollback(ctx)
+			if rollbackErr != nil {
+				err = fmt.Errorf("error rolling back transaction: %w. caused after this error: %w",
+					rollbackErr, err)
+			}
+			return
+		}
+		err = transaction.Commit(ctx)
+		if err != nil {
+			err = fmt.Errorf("couldn't commit change order status transaction: %w", err)
+		}
+	}()
+
+	// step 1. lock the order
+	_, found, err := lockOrderVersion(ctx, transaction, orderUID)
+	if err != nil {
+		return models.OrderStatusEvent{}, fmt.Errorf("couldn't lock stored order: %w", err)
+	}
+	if !found {
+		return models.OrderStatusEvent{}, fmt.Errorf("%w: %s", customerrors.ErrOrderNotFound, orderUID)
+	}
+
+	// step 2. check the item and the transition
+	if nmID != 0 {
+		err = checkOrderItemExists(ctx, transaction, orderUID, nmID)
+		if err != nil {
+			return models.OrderStatusEvent{}, err
+		}
+	}
+	current, err := getCurrentOrderStatus(ctx, transaction, orderUID, nmID)
+	if err != nil {
+		return models.OrderStatusEvent{}, err
+	}
+	if !current.CanTransitionTo(status) {
+		return models.OrderStatusEvent{}, fmt.Errorf("%w: %s, from %s to %s",
+			customerrors.ErrIllegalStatusTransition, orderUID, current, status)
+	}
+
+	// step 3. write the event
+	var eventNmID *int
+	if nmID != 0 {
+		eventNmID = &nmID
+	}
+	sql, args, err := squirrel.
+		Insert("order_service.order_status_events").
+		Columns("order_uid", "nm_id", "status", "comment").
+		Values(orderUID, eventNmID, status, comment).
+		Suffix("RETURNING id, created_at").
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		return models.OrderStatusEvent{}, fmt.Errorf("couldn't build an SQL query: %w", err)
+	}
+
+	event = models.OrderStatusEvent{
+		OrderUID: orderUID,
+		NmID:     nmID,
+		Status:   status,
+		Comment:  comment,
+	}
+	err = transaction.QueryRow(ctx, sql, args...).Scan(&event.ID, &event.CreatedAt)
+	if err != nil {
+		return models.OrderStatusEvent{}, fmt.Errorf("couldn't exec save order status query: %w", err)
+	}
+
+	// step 4. publish the change with the new timeline
+	order, err := getOrderByIDInTx(ctx, transaction, orderUID)
+	if err != nil {
+		return models.OrderStatusEvent{}, fmt.Errorf("couldn't read order after changing status: %w", err)
+	}
+	err = saveOrderEvent(ctx, transaction, models.OrderEventTypeStatusChanged, &order)
+	if err != nil {
+		return models.OrderStatusEvent{}, fmt.Errorf("error saving order event: %w", err)
+	}
+
+	// check defer for more possible errors
+	return event, nil
+}
+
+// checkOrderItemExists returns customerrors.ErrOrderItemNotFound if the order has no item with given nm_id
+func checkOrderItemExists(ctx context.Context, transaction pgx.Tx, orderUID string, nmID int) error {
+	sql, args, err := squirrel.Select("1").
+		From("order_service.order_items").
+		Where(squirrel.Eq{"order_id": orderUID, "nm_id": nmID}).
+		Limit(1).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("couldn't build an SQL query: %w", err)
+	}
+
+	var exists int
+	err = transaction.QueryRow(ctx, sql, args...).Scan(&exists)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("%w: %s, nm_id %d", customerrors.ErrOrderItemNotFound, orderUID, nmID)
+	}
+	if err != nil {
+		return fmt.Errorf("couldn't check order item: %w", err)
+	}
+	return nil
+}
+
+// getCurrentOrderStatus returns the status of the last event of the order or its item with nmID,
+// models.OrderStatusCreated if there are none
+func getCurrentOrderStatus(ctx context.Context, transaction pgx.Tx, orderUID string, nmID int) (models.OrderStatus, error) {
+	var eventNmID interface{}
+	if nmID != 0 {
+		eventNmID = nmID
+	}
+	sql, args, err := squirrel.Select("status").
+		From("order_service.order_status_events").
+		Where(squirrel.Eq{"order_uid": orderUID, "nm_id": eventNmID}).
+		OrderBy("id DESC").
+		Limit(1).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		return "", fmt.Errorf("couldn't build an SQL query: %w", err)
+	}
+
+	var status models.OrderStatus
+	err = transaction.QueryRow(ctx, sql, args...).Scan(&status)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.OrderStatusCreated, nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("couldn't get current order status: %w", err)
+	}
+	return status, nil
+}
@@ -11,7 +11,6 @@ import (
 	"golang.org/x/sync/errgroup"
 	"order_service/internal/custom_errors"
 	"order_service/internal/models"
-	"strings"
 )
 
 // OrdersStoragePostgres is the postgres implementation of ports.OrderStorage
@@ -19,6 +18,12 @@ type OrdersStoragePostgres struct {
 	pool *pgxpool.Pool
 }
 
+// querier runs queries, it's either the pool or a transaction, e.g. to read an order while it's locked
+type querier interface {
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
 // NewOrdersStoragePostgres creates a new *OrdersStoragePostgres with given DB pool
 func NewOrdersStoragePostgres(pool *pgxpool.Pool) *OrdersStoragePostgres {
 	return &OrdersStoragePostgres{
@@ -28,18 +33,19 @@ func NewOrdersStoragePostgres(pool *pgxpool.Pool) *OrdersStoragePostgres {
 
 // GetOrderByID is the implementation of GetOrderByID method of ports.OrderStorage
 //
-// It gathers all data about the order with given ID if any.
+// It gathers all data about the order with given ID if any, including the status timelines of the order and its items.
 //
-// Querying for order and its items is parallel
+// Querying for order, its items and statuses is parallel
 func (o *OrdersStoragePostgres) GetOrderByID(ctx context.Context, orderID string) (models.Order, error) {
 	var items []models.OrderItem
 	var order models.Order
+	var timelines map[string][]models.OrderStatusEvent
 
 	eg, _ := errgroup.WithContext(ctx)
 
 	eg.Go(func() error {
 		var err error
-		order, err = o.getOrderByIDBase(ctx, orderID)
+		order, err = getOrderByIDBase(ctx, o.pool, orderID)
 		if err != nil {
 			return fmt.Errorf("error trying to get order itself: %w", err)
 		}
@@ -48,18 +54,50 @@ func (o *OrdersStoragePostgres) GetOrderByID(ctx context.Context, orderID string
 
 	eg.Go(func() error {
 		var err error
-		items, err = o.getOrderItemsByID(ctx, orderID)
+		items, err = getOrderItemsByID(ctx, o.pool, orderID)
 		if err != nil {
 			return fmt.Errorf("error trying to order items: %w", err)
 		}
 		return nil
 	})
 
+	eg.Go(func() error {
+		var err error
+		timelines, err = getOrderStatusTimelines(ctx, o.pool, []string{orderID})
+		if err != nil {
+			return fmt.Errorf("error trying to get order statuses: %w", err)
+		}
+		return nil
+	})
+
 	if err := eg.Wait(); err != nil {
 		return models.Order{}, err
 	}
 
 	order.Items = items
+	order.AttachStatusTimeline(timelines[orderID])
+
+	return order, nil
+}
+
+// getOrderByIDInTx gathers all data about the order as GetOrderByID does, but in given transaction,
+// e.g. to take a snapshot of a locked order. A transaction runs one query at a time, so they're sequential
+func getOrderByIDInTx(ctx context.Context, transaction pgx.Tx, orderID string) (models.Order, error) {
+	order, err := getOrderByIDBase(ctx, transaction, orderID)
+	if err != nil {
+		return models.Order{}, fmt.Errorf("error trying to get order itself: %w", err)
+	}
+
+	order.Items, err = getOrderItemsByID(ctx, transaction, orderID)
+	if err != nil {
+		return models.Order{}, fmt.Errorf("error trying to order items: %w", err)
+	}
+
+	timelines, err := getOrderStatusTimelines(ctx, transaction, []string{orderID})
+	if err != nil {
+		return models.Order{}, fmt.Errorf("error trying to get order statuses: %w", err)
+	}
+	order.AttachStatusTimeline(timelines[orderID])
 
 	return order, nil
 }
@@ -69,13 +107,13 @@ func (o *OrdersStoragePostgres) GetOrderByID(ctx context.Context, orderID string
 // instead of making many (3) async queries to many (3) different tables we make 1 big query
 //
 // call getOrderItemsByID to find the items
-func (o *OrdersStoragePostgres) getOrderByIDBase(ctx context.Context, orderID string) (models.Order, error) {
+func getOrderByIDBase(ctx context.Context, q querier, orderID string) (models.Order, error) {
 	// build select query
 	sql, args, err := squirrel.Select(
 		// order fields
 		"o.order_uid", "o.track_number", "o.entry", "o.locale", "o.internal_signature",
 		"o.customer_id", "o.delivery_service", "o.shardkey", "o.sm_id", "o.date_created",
-		"o.oof_shard", "o.created_at", "o.updated_at",
+		"o.oof_shard", "o.version", "o.created_at", "o.updated_at",
 		// delivery fields
 		"d.order_id", "d.name", "d.phone", "d.zip", "d.city", "d.address", "d.region", "d.email",
 		// payment fields
@@ -96,11 +134,11 @@ func (o *OrdersStoragePostgres) getOrderByIDBase(ctx context.Context, orderID st
 	var order models.Order
 
 	// perform select query
-	err = o.pool.QueryRow(context.Background(), sql, args...).Scan(
+	err = q.QueryRow(ctx, sql, args...).Scan(
 		// order fields
 		&order.OrderUID, &order.TrackNumber, &order.Entry, &order.Locale, &order.InternalSignature,
 		&order.CustomerID, &order.DeliveryService, &order.ShardKey, &order.SmID, &order.DateCreated,
-		&order.OofShard, &order.CreatedAt, &order.UpdatedAt,
+		&order.OofShard, &order.Version, &order.CreatedAt, &order.UpdatedAt,
 		// delivery fields
 		&order.Delivery.OrderID, &order.Delivery.Name, &order.Delivery.Phone,
 		&order.Delivery.Zip, &order.Delivery.City, &order.Delivery.Address,
@@ -126,24 +164,72 @@ func (o *OrdersStoragePostgres) getOrderByIDBase(ctx context.Context, orderID st
 	return order, nil
 }
 
-// getLastOrdersBase makes a long SELECT query to retrieve models.Order list, SORT BY created_at ASC
+// orderBaseColumns are the columns of models.Order including models.Delivery, models.Payment
+//
+// tables are aliased as "o", "d" and "p", scan them with scanOrderBase
+var orderBaseColumns = []string{
+	// order fields
+	"o.order_uid", "o.track_number", "o.entry", "o.locale", "o.internal_signature",
+	"o.customer_id", "o.delivery_service", "o.shardkey", "o.sm_id", "o.date_created",
+	"o.oof_shard", "o.version", "o.created_at", "o.updated_at",
+	// delivery fields
+	"d.order_id", "d.name", "d.phone", "d.zip", "d.city", "d.address", "d.region", "d.email",
+	// payment fields
+	"p.order_id", "p.transaction", "p.request_id", "p.currency", "p.provider", "p.amount",
+	"p.payment_dt", "p.bank", "p.delivery_cost", "p.goods_total", "p.custom_fee",
+}
+
+// scanOrderBase scans a row selected with orderBaseColumns into given order
+func scanOrderBase(row pgx.Row, order *models.Order) error {
+	return row.Scan(
+		// order fields
+		&order.OrderUID, &order.TrackNumber, &order.Entry, &order.Locale, &order.InternalSignature,
+		&order.CustomerID, &order.DeliveryService, &order.ShardKey, &order.SmID, &order.DateCreated,
+		&order.OofShard, &order.Version, &order.CreatedAt, &order.UpdatedAt,
+		// delivery fields
+		&order.Delivery.OrderID, &order.Delivery.Name, &order.Delivery.Phone,
+		&order.Delivery.Zip, &order.Delivery.City, &order.Delivery.Address,
+		&order.Delivery.Region, &order.Delivery.Email,
+		// payment fields
+		&order.Payment.OrderID, &order.Payment.Transaction, &order.Payment.RequestID,
+		&order.Payment.Currency, &order.Payment.Provider, &order.Payment.Amount,
+		&order.Payment.PaymentDt, &order.Payment.Bank, &order.Payment.DeliveryCost,
+		&order.Payment.GoodsTotal, &order.Payment.CustomFee,
+	)
+}
+
+// queryOrdersBase performs given SELECT query built with orderBaseColumns and scans every row
+func (o *OrdersStoragePostgres) queryOrdersBase(ctx context.Context, sql string, args []interface{}) ([]models.Order, error) {
+	rows, err := o.pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't query orders: %v", err)
+	}
+	defer rows.Close()
+
+	var orders = make([]models.Order, 0)
+	for rows.Next() {
+		var order models.Order
+		err = scanOrderBase(rows, &order)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't scan order: %v", err)
+		}
+		orders = append(orders, order)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error reading orders rows: %v", err)
+	}
+
+	return orders, nil
+}
+
+// getLastOrdersBase makes a long SELECT query to retrieve models.Order list, SORT BY created_at DESC
 //
 // it includes Payment and Delivery fields
 //
 // call getOrderItemsByID to get items for each of these
 func (o *OrdersStoragePostgres) getLastOrdersBase(ctx context.Context, limit int) ([]models.Order, error) {
 	// build select query
-	sql, args, err := squirrel.Select(
-		// order fields
-		"o.order_uid", "o.track_number", "o.entry", "o.locale", "o.internal_signature",
-		"o.customer_id", "o.delivery_service", "o.shardkey", "o.sm_id", "o.date_created",
-		"o.oof_shard", "o.created_at", "o.updated_at",
-		// delivery fields
-		"d.order_id", "d.name", "d.phone", "d.zip", "d.city", "d.address", "d.region", "d.email",
-		// payment fields
-		"p.order_id", "p.transaction", "p.request_id", "p.currency", "p.provider", "p.amount",
-		"p.payment_dt", "p.bank", "p.delivery_cost", "p.goods_total", "p.custom_fee",
-	).
+	sql, args, err := squirrel.Select(orderBaseColumns...).
 		From("order_service.orders o").
 		Join("order_service.deliveries d ON d.order_id = o.order_uid").
 		Join("order_service.payments p ON p.order_id = o.order_uid").
@@ -156,35 +242,64 @@ func (o *OrdersStoragePostgres) getLastOrdersBase(ctx context.Context, limit int
 	}
 
 	// perform select query
-	var rows pgx.Rows
-	rows, err = o.pool.Query(ctx, sql, args...)
+	orders, err := o.queryOrdersBase(ctx, sql, args)
 	if err != nil {
 		return nil, fmt.Errorf("couldn't query last orders: %v", err)
 	}
-	defer rows.Close()
 
-	var orders = make([]models.Order, 0)
-	for rows.Next() {
-		var order models.Order
-		err = rows.Scan(
-			// order fields
-			&order.OrderUID, &order.TrackNumber, &order.Entry, &order.Locale, &order.InternalSignature,
-			&order.CustomerID, &order.DeliveryService, &order.ShardKey, &order.SmID, &order.DateCreated,
-			&order.OofShard, &order.CreatedAt, &order.UpdatedAt,
-			// delivery fields
-			&order.Delivery.OrderID, &order.Delivery.Name, &order.Delivery.Phone,
-			&order.Delivery.Zip, &order.Delivery.City, &order.Delivery.Address,
-			&order.Delivery.Region, &order.Delivery.Email,
-			// payment fields
-			&order.Payment.OrderID, &order.Payment.Transaction, &order.Payment.RequestID,
-			&order.Payment.Currency, &order.Payment.Provider, &order.Payment.Amount,
-			&order.Payment.PaymentDt, &order.Payment.Bank, &order.Payment.DeliveryCost,
-			&order.Payment.GoodsTotal, &order.Payment.CustomFee,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("couldn't scan order: %v", err)
-		}
-		orders = append(orders, order)
+	return orders, nil
+}
+
+// listOrdersBase makes a long SELECT query to retrieve a page of models.Order list
+// that match the filter, SORT BY created_at DESC, order_uid DESC
+//
+// it includes Payment and Delivery fields and returns up to limit+1 orders,
+// so the caller knows if there's a next page
+//
+// call getOrderItemsByID to get items for each of these
+func (o *OrdersStoragePostgres) listOrdersBase(ctx context.Context, filter models.OrdersFilter) ([]models.Order, error) {
+	query := squirrel.Select(orderBaseColumns...).
+		From("order_service.orders o").
+		Join("order_service.deliveries d ON d.order_id = o.order_uid").
+		Join("order_service.payments p ON p.order_id = o.order_uid")
+
+	// keyset pagination: the row comparison uses the (created_at, order_uid) index
+	if filter.After != nil {
+		query = query.Where("(o.created_at, o.order_uid) < (?, ?)", filter.After.CreatedAt, filter.After.OrderUID)
+	}
+
+	// filters
+	if filter.CustomerID != "" {
+		query = query.Where(squirrel.Eq{"o.customer_id": filter.CustomerID})
+	}
+	if filter.TrackNumber != "" {
+		query = query.Where(squirrel.Eq{"o.track_number": filter.TrackNumber})
+	}
+	if filter.DeliveryService != "" {
+		query = query.Where(squirrel.Eq{"o.delivery_service": filter.DeliveryService})
+	}
+	if filter.Locale != "" {
+		query = query.Where(squirrel.Eq{"o.locale": filter.Locale})
+	}
+	if filter.DateCreatedFrom != nil {
+		query = query.Where(squirrel.GtOrEq{"o.date_created": *filter.DateCreatedFrom})
+	}
+	if filter.DateCreatedTo != nil {
+		query = query.Where(squirrel.Lt{"o.date_created": *filter.DateCreatedTo})
+	}
+
+	sql, args, err := query.
+		OrderBy("o.created_at DESC", "o.order_uid DESC").
+		Limit(uint64(filter.Limit) + 1).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("couldn't build and SQL query: %v", err)
+	}
+
+	orders, err := o.queryOrdersBase(ctx, sql, args)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't query orders page: %v", err)
 	}
 
 	return orders, nil
@@ -192,7 +307,7 @@ func (o *OrdersStoragePostgres) getLastOrdersBase(ctx context.Context, limit int
 
 // getOrderItemsByID makes a query to create a slice of models.OrderItem
 // which can be used when retrieving models.Order
-func (o *OrdersStoragePostgres) getOrderItemsByID(ctx context.Context, orderID string) ([]models.OrderItem, error) {
+func getOrderItemsByID(ctx context.Context, q querier, orderID string) ([]models.OrderItem, error) {
 	sql, args, err := squirrel.Select(
 		"order_id", "chrt_id", "track_number", "price", "rid",
 		"name", "sale", "size", "total_price", "nm_id", "brand", "status",
@@ -206,7 +321,7 @@ func (o *OrdersStoragePostgres) getOrderItemsByID(ctx context.Context, orderID s
 	}
 
 	var rows pgx.Rows
-	rows, err = o.pool.Query(ctx, sql, args...)
+	rows, err = q.Query(ctx, sql, args...)
 	if err != nil {
 		return nil, fmt.Errorf("couldn't query items: %v", err)
 	}
@@ -240,6 +355,52 @@ func (o *OrdersStoragePostgres) GetLastOrders(ctx context.Context, limit int) ([
 		return nil, fmt.Errorf("couldn't get last orders: %v", err)
 	}
 
+	if err = o.attachItems(ctx, ordersList); err != nil {
+		return nil, fmt.Errorf("couldn't get last orders items: %w", err)
+	}
+	if err = o.attachStatusTimelines(ctx, ordersList); err != nil {
+		return nil, fmt.Errorf("couldn't get last orders statuses: %w", err)
+	}
+
+	return ordersList, nil
+}
+
+// ListOrders is implementation of such method in ports.OrderStorage
+//
+// It gets a page of orders that match the filter, newest first.
+// Pagination is keyset-based on (created_at, order_uid)
+func (o *OrdersStoragePostgres) ListOrders(ctx context.Context, filter models.OrdersFilter) (models.OrdersPage, error) {
+	ordersList, err := o.listOrdersBase(ctx, filter)
+	if err != nil {
+		return models.OrdersPage{}, fmt.Errorf("couldn't list orders: %w", err)
+	}
+
+	// we've asked for 1 extra order, it only tells us that the next page exists
+	var nextCursor *models.OrdersCursor
+	if len(ordersList) > filter.Limit {
+		ordersList = ordersList[:filter.Limit]
+		last := ordersList[len(ordersList)-1]
+		nextCursor = &models.OrdersCursor{
+			CreatedAt: last.CreatedAt,
+			OrderUID:  last.OrderUID,
+		}
+	}
+
+	if err = o.attachItems(ctx, ordersList); err != nil {
+		return models.OrdersPage{}, fmt.Errorf("couldn't get listed orders items: %w", err)
+	}
+	if err = o.attachStatusTimelines(ctx, ordersList); err != nil {
+		return models.OrdersPage{}, fmt.Errorf("couldn't get listed orders statuses: %w", err)
+	}
+
+	return models.OrdersPage{
+		Orders:     ordersList,
+		NextCursor: nextCursor,
+	}, nil
+}
+
+// attachItems queries items for every order in parallel and puts them into orders
+func (o *OrdersStoragePostgres) attachItems(ctx context.Context, ordersList []models.Order) error {
 	var eg *errgroup.Group
 	eg, ctx = errgroup.WithContext(ctx)
 
@@ -250,7 +411,7 @@ func (o *OrdersStoragePostgres) GetLastOrders(ctx context.Context, limit int) ([
 			var items []models.OrderItem
 			var itemsErr error
 
-			items, itemsErr = o.getOrderItemsByID(ctx, order.OrderUID)
+			items, itemsErr = getOrderItemsByID(ctx, o.pool, order.OrderUID)
 			if itemsErr != nil {
 				return fmt.Errorf("error finding items for order %s: %w", order.OrderUID, itemsErr)
 			}
@@ -259,89 +420,487 @@ func (o *OrdersStoragePostgres) GetLastOrders(ctx context.Context, limit int) ([
 		})
 	}
 
-	if err = eg.Wait(); err != nil {
-		return nil, fmt.Errorf("couldn't get last orders items: %w", err)
+	if err := eg.Wait(); err != nil {
+		return err
 	}
 
 	for i, items := range itemsList {
 		ordersList[i].Items = items
 	}
 
-	return ordersList, nil
+	return nil
+}
+
+// insertOrder saves the order, related entities, the initial status and the outbox event in given transaction
+//
+// inserted is false if the order_uid is already taken (e.g. by a concurrent insert), nothing is written then
+func insertOrder(ctx context.Context, transaction pgx.Tx, order *models.Order) (inserted bool, err error) {
+	inserted, err = saveOrder(ctx, transaction, order)
+	if err != nil {
+		return false, fmt.Errorf("couldn't save order: %w", err)
+	}
+	if !inserted {
+		return false, nil
+	}
+
+	err = savePayment(ctx, transaction, order.OrderUID, &order.Payment)
+	if err != nil {
+		return false, fmt.Errorf("error saving payment: %w", err)
+	}
+
+	err = saveDelivery(ctx, transaction, order.OrderUID, &order.Delivery)
+	if err != nil {
+		return false, fmt.Errorf("error saving delivery: %w", err)
+	}
+
+	err = saveItems(ctx, transaction, order.OrderUID, &order.Items)
+	if err != nil {
+		return false, fmt.Errorf("error saving items: %w", err)
+	}
+
+	err = saveInitialOrderStatus(ctx, transaction, order.OrderUID)
+	if err != nil {
+		return false, fmt.Errorf("error saving initial order status: %w", err)
+	}
+
+	err = saveOrderEvent(ctx, transaction, models.OrderEventTypeSaved, order)
+	if err != nil {
+		return false, fmt.Errorf("error saving order event: %w", err)
+	}
+	return true, nil
 }
 
-// SaveOrder is implementation of such method in ports.OrderStorage
+// UpsertOrder is implementation of such method in ports.OrderStorage
+//
+// A new order is saved with related entities in a "long" transaction, a models.OrderEventTypeSaved event
+// is written to the order_events outbox in the same transaction.
+// If the order is already stored, it's locked and versions are compared:
+//   - bigger version: the stored one is archived in order_history and replaced, items are replaced too,
+//     a models.OrderEventTypeUpdated event is written to the outbox
+//   - same version: customerrors.ErrOrderAlreadySaved if exactly the same order is stored (e.g. a redelivery),
+//     customerrors.ErrOrderConflict otherwise
+//   - smaller version: customerrors.ErrOrderOutdated, nothing is written
 //
-// It saves the order and related entities in a "long" transaction
-func (o *OrdersStoragePostgres) SaveOrder(ctx context.Context, order models.Order) error {
+// Database errors are classified with customerrors.Kind by their SQLSTATE codes
+func (o *OrdersStoragePostgres) UpsertOrder(ctx context.Context, order models.Order) (err error) {
+	// runs last, after commit or rollback
+	defer func() {
+		err = classifyError(err)
+	}()
+
 	transaction, err := o.pool.Begin(ctx)
 	if err != nil {
-		return fmt.Errorf("couldn't start transaction: %v", err)
+		return fmt.Errorf("couldn't start transaction: %w", err)
 	}
 
+	// err is the named result, so errors of rollback and commit are actually returned
 	defer func() {
 		if err != nil {
-			err = fmt.Errorf("error saving order transaction, rolling back: %w", err)
+			err = fmt.Errorf("error upserting order transaction, rolling back: %w", err)
 			rollbackErr := transaction.Rollback(ctx)
 			if rollbackErr != nil {
 				err = fmt.Errorf("error rolling back transaction: %w. caused after this error: %w",
 					rollbackErr, err)
 			}
+			return
 		}
 		err = transaction.Commit(ctx)
+		if err != nil {
+			err = fmt.Errorf("couldn't commit upsert order transaction: %w", err)
+		}
 	}()
 
-	err = saveOrder(ctx, transaction, &order)
+	var storedVersion int
+	var found bool
+	storedVersion, found, err = lockOrderVersion(ctx, transaction, order.OrderUID)
 	if err != nil {
-		return fmt.Errorf("couldn't save order: %w", err)
+		return fmt.Errorf("couldn't lock stored order: %w", err)
 	}
 
-	err = savePayment(ctx, transaction, order.OrderUID, &order.Payment)
+	// step 1. it's a new order, save it the usual way
+	if !found {
+		var inserted bool
+		inserted, err = insertOrder(ctx, transaction, &order)
+		if err != nil || inserted {
+			return err
+		}
+
+		// a concurrent writer has inserted it meanwhile (e.g. a previous version), the insert has waited for its
+		// transaction, so the row is committed now and is replaced as a stored one
+		storedVersion, found, err = lockOrderVersion(ctx, transaction, order.OrderUID)
+		if err != nil {
+			return fmt.Errorf("couldn't lock stored order: %w", err)
+		}
+		if !found {
+			// and deleted right away, the next try inserts it
+			return customerrors.NewClassifiedError(customerrors.KindTransient, "",
+				fmt.Errorf("order %s was deleted while it was inserted", order.OrderUID))
+		}
+	}
+
+	// step 2. it's stored, replace it if it's newer
+	err = replaceStoredOrder(ctx, transaction, &order, storedVersion)
+
+	// check defer for more possible errors
+	return err
+}
+
+// replaceStoredOrder compares versions of given and stored locked order and replaces the stored one if given is newer
+//
+// customerrors.ErrOrderOutdated, customerrors.ErrOrderAlreadySaved and customerrors.ErrOrderConflict
+// are returned before anything is written
+func replaceStoredOrder(ctx context.Context, transaction pgx.Tx, order *models.Order, storedVersion int) error {
+	// step 1. compare versions
+	if order.Version < storedVersion {
+		return fmt.Errorf("%w: %s, stored version: %d, given: %d",
+			customerrors.ErrOrderOutdated, order.OrderUID, storedVersion, order.Version)
+	}
+
+	contentHash, err := order.ContentHash()
 	if err != nil {
-		return fmt.Errorf("error saving payment: %w", err)
+		return err
 	}
 
-	err = saveDelivery(ctx, transaction, order.OrderUID, &order.Delivery)
+	if order.Version == storedVersion {
+		return checkExistingOrder(ctx, transaction, order.OrderUID, contentHash)
+	}
+
+	// step 2. archive the stored version and replace it
+	err = archiveOrder(ctx, transaction, order.OrderUID)
+	if err != nil {
+		return fmt.Errorf("error archiving previous order version: %w", err)
+	}
+	err = updateOrder(ctx, transaction, order, contentHash)
+	if err != nil {
+		return fmt.Errorf("couldn't update order: %w", err)
+	}
+	err = updatePayment(ctx, transaction, order.OrderUID, &order.Payment)
 	if err != nil {
-		return fmt.Errorf("error saving delivery: %w", err)
+		return fmt.Errorf("error updating payment: %w", err)
 	}
+	err = updateDelivery(ctx, transaction, order.OrderUID, &order.Delivery)
+	if err != nil {
+		return fmt.Errorf("error updating delivery: %w", err)
+	}
+	err = replaceItems(ctx, transaction, order.OrderUID, &order.Items)
+	if err != nil {
+		return fmt.Errorf("error replacing items: %w", err)
+	}
+	err = saveOrderEvent(ctx, transaction, models.OrderEventTypeUpdated, order)
+	if err != nil {
+		return fmt.Errorf("error saving order event: %w", err)
+	}
+	return nil
+}
 
-	err = saveItems(ctx, transaction, order.OrderUID, &order.Items)
+// DeleteOrder is implementation of such method in ports.OrderStorage
+//
+// The order is locked and deleted with everything related to it: delivery, payment, items and status events.
+// Archived versions are kept in order_history for audit. A models.OrderEventTypeDeleted event with the last snapshot
+// is written to the outbox. customerrors.ErrOrderNotFound is returned if there's no such order
+func (o *OrdersStoragePostgres) DeleteOrder(ctx context.Context, orderUID string) (err error) {
+	// runs last, after commit or rollback
+	defer func() {
+		err = classifyError(err)
+	}()
+
+	transaction, err := o.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("couldn't start transaction: %w", err)
+	}
+
+	// err is the named result, so errors of rollback and commit are actually returned
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("error deleting order transaction, rolling back: %w", err)
+			rollbackErr := transaction.Rollback(ctx)
+			if rollbackErr != nil {
+				err = fmt.Errorf("error rolling back transaction: %w. caused after this error: %w",
+					rollbackErr, err)
+			}
+			return
+		}
+		err = transaction.Commit(ctx)
+		if err != nil {
+			err = fmt.Errorf("couldn't commit delete order transaction: %w", err)
+		}
+	}()
+
+	// step 1. lock the order
+	_, found, err := lockOrderVersion(ctx, transaction, orderUID)
+	if err != nil {
+		return fmt.Errorf("couldn't lock stored order: %w", err)
+	}
+	if !found {
+		return fmt.Errorf("%w: %s", customerrors.ErrOrderNotFound, orderUID)
+	}
+
+	// step 2. take the last snapshot for the event
+	//   through the transaction: a pool read would wait for another connection while this one is held
+	order, err := getOrderByIDInTx(ctx, transaction, orderUID)
+	if err != nil {
+		return fmt.Errorf("couldn't read order before deleting: %w", err)
+	}
+
+	// step 3. delete, the related rows are deleted by cascade
+	sql, args, err := squirrel.Delete("order_service.orders").
+		Where(squirrel.Eq{"order_uid": orderUID}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("couldn't build an SQL query: %w", err)
+	}
+	_, err = transaction.Exec(ctx, sql, args...)
 	if err != nil {
-		return fmt.Errorf("error saving items: %w", err)
+		return fmt.Errorf("couldn't exec delete order query: %w", err)
+	}
+
+	err = saveOrderEvent(ctx, transaction, models.OrderEventTypeDeleted, &order)
+	if err != nil {
+		return fmt.Errorf("error saving order event: %w", err)
 	}
 
 	// check defer for more possible errors
-	return err
+	return nil
+}
+
+// lockOrderVersion locks the stored order row until the end of transaction and returns its version
+//
+// found is false if there's no such order, nothing is locked then
+func lockOrderVersion(ctx context.Context, transaction pgx.Tx, orderUID string) (version int, found bool, err error) {
+	sql, args, err := squirrel.Select("version").
+		From("order_service.orders").
+		Where(squirrel.Eq{"order_uid": orderUID}).
+		Suffix("FOR UPDATE").
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		return 0, false, fmt.Errorf("couldn't build an SQL query: %w", err)
+	}
+
+	err = transaction.QueryRow(ctx, sql, args...).Scan(&version)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, false, nil
+		}
+		return 0, false, fmt.Errorf("couldn't exec lock order query: %w", err)
+	}
+	return version, true, nil
+}
+
+// archiveOrder copies the stored order with its delivery, payment and items into order_history
+//
+// the snapshot is built by postgres, its keys are column names, same as models.Order JSON
+func archiveOrder(ctx context.Context, transaction pgx.Tx, orderUID string) error {
+	sql := `INSERT INTO order_service.order_history (order_uid, version, content_hash, snapshot)
+SELECT o.order_uid,
+       o.version,
+       o.content_hash,
+       to_jsonb(o) || jsonb_build_object(
+               'delivery', to_jsonb(d),
+               'payment', to_jsonb(p),
+               'items', COALESCE((SELECT jsonb_agg(to_jsonb(i))
+                                  FROM order_service.order_items i
+                                  WHERE i.order_id = o.order_uid), '[]'::jsonb)
+                      )
+FROM order_service.orders o
+         JOIN order_service.deliveries d ON d.order_id = o.order_uid
+         JOIN order_service.payments p ON p.order_id = o.order_uid
+WHERE o.order_uid = $1`
+
+	result, err := transaction.Exec(ctx, sql, orderUID)
+	if err != nil {
+		return fmt.Errorf("couldn't exec archive order query: %w", err)
+	}
+	if result.RowsAffected() != 1 {
+		return fmt.Errorf("couldn't archive order, rows affected: %d, expected: 1", result.RowsAffected())
+	}
+	return nil
+}
+
+func updateOrder(ctx context.Context, transaction pgx.Tx, order *models.Order, contentHash string) error {
+	// updated_at is set by the trigger
+	sql, args, err := squirrel.
+		Update("order_service.orders").
+		SetMap(map[string]interface{}{
+			"track_number":       order.TrackNumber,
+			"entry":              order.Entry,
+			"locale":             order.Locale,
+			"internal_signature": order.InternalSignature,
+			"customer_id":        order.CustomerID,
+			"delivery_service":   order.DeliveryService,
+			"shardkey":           order.ShardKey,
+			"sm_id":              order.SmID,
+			"date_created":       order.DateCreated,
+			"oof_shard":          order.OofShard,
+			"version":            order.Version,
+			"content_hash":       contentHash,
+		}).
+		Where(squirrel.Eq{"order_uid": order.OrderUID}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("couldn't build an SQL query: %w", err)
+	}
+
+	var result pgconn.CommandTag
+	result, err = transaction.Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("couldn't exec update order query: %w", err)
+	}
+	if result.RowsAffected() != 1 {
+		return fmt.Errorf("couldn't update order, rows affected: %d, expected: 1", result.RowsAffected())
+	}
+	return nil
+}
+
+func updatePayment(ctx context.Context, transaction pgx.Tx, orderUID string, payment *models.Payment) error {
+	sql, args, err := squirrel.
+		Update("order_service.payments").
+		SetMap(map[string]interface{}{
+			"transaction":   payment.Transaction,
+			"request_id":    payment.RequestID,
+			"currency":      payment.Currency,
+			"provider":      payment.Provider,
+			"amount":        payment.Amount,
+			"payment_dt":    payment.PaymentDt,
+			"bank":          payment.Bank,
+			"delivery_cost": payment.DeliveryCost,
+			"goods_total":   payment.GoodsTotal,
+			"custom_fee":    payment.CustomFee,
+		}).
+		Where(squirrel.Eq{"order_id": orderUID}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("couldn't build an SQL query: %w", err)
+	}
+
+	var result pgconn.CommandTag
+	result, err = transaction.Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("couldn't exec update payment query: %w", err)
+	}
+	if result.RowsAffected() != 1 {
+		return fmt.Errorf("couldn't update payment, rows affected: %d, expected: 1", result.RowsAffected())
+	}
+	return nil
+}
+
+func updateDelivery(ctx context.Context, transaction pgx.Tx, orderUID string, delivery *models.Delivery) error {
+	sql, args, err := squirrel.
+		Update("order_service.deliveries").
+		SetMap(map[string]interface{}{
+			"name":    delivery.Name,
+			"phone":   delivery.Phone,
+			"zip":     delivery.Zip,
+			"city":    delivery.City,
+			"address": delivery.Address,
+			"region":  delivery.Region,
+			"email":   delivery.Email,
+		}).
+		Where(squirrel.Eq{"order_id": orderUID}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("couldn't build an SQL query: %w", err)
+	}
+
+	var result pgconn.CommandTag
+	result, err = transaction.Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("couldn't exec update delivery query: %w", err)
+	}
+	if result.RowsAffected() != 1 {
+		return fmt.Errorf("couldn't update delivery, rows affected: %d, expected: 1", result.RowsAffected())
+	}
+	return nil
+}
+
+// replaceItems deletes all the items of the order and saves given ones
+func replaceItems(ctx context.Context, transaction pgx.Tx, orderUID string, items *[]models.OrderItem) error {
+	sql, args, err := squirrel.
+		Delete("order_service.order_items").
+		Where(squirrel.Eq{"order_id": orderUID}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("couldn't build an SQL query: %w", err)
+	}
+
+	_, err = transaction.Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("couldn't exec delete items query: %w", err)
+	}
+
+	return saveItems(ctx, transaction, orderUID, items)
 }
 
-func saveOrder(ctx context.Context, transaction pgx.Tx, order *models.Order) error {
+// saveOrder inserts the order row, inserted is false if the order_uid is already taken
+func saveOrder(ctx context.Context, transaction pgx.Tx, order *models.Order) (inserted bool, err error) {
+	contentHash, err := order.ContentHash()
+	if err != nil {
+		return false, err
+	}
+
+	// on conflict, nothing is inserted and the caller compares versions
 	sql, args, err := squirrel.
 		Insert("order_service.orders").
 		Columns(
 			"order_uid", "track_number", "entry", "locale", "internal_signature", "customer_id",
-			"delivery_service", "shardkey", "sm_id", "date_created", "oof_shard",
+			"delivery_service", "shardkey", "sm_id", "date_created", "oof_shard", "version", "content_hash",
 		).
 		Values(
 			order.OrderUID, order.TrackNumber, order.Entry, order.Locale, order.InternalSignature, order.CustomerID,
-			order.DeliveryService, order.ShardKey, order.SmID, order.DateCreated, order.OofShard,
+			order.DeliveryService, order.ShardKey, order.SmID, order.DateCreated, order.OofShard, order.Version, contentHash,
 		).
+		Suffix("ON CONFLICT (order_uid) DO NOTHING").
 		PlaceholderFormat(squirrel.Dollar).
 		ToSql()
 
 	if err != nil {
-		return fmt.Errorf("couldn't build an SQL query: %w", err)
+		return false, fmt.Errorf("couldn't build an SQL query: %w", err)
 	}
 
 	var result pgconn.CommandTag
 	result, err = transaction.Exec(ctx, sql, args...)
 	if err != nil {
-		return fmt.Errorf("couldn't exec save order query: %w", err)
+		return false, fmt.Errorf("couldn't exec save order query: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return false, nil
 	}
 	if result.RowsAffected() != 1 {
-		return fmt.Errorf("couldn't save order query, rows affected: %d, expected: 1", result.RowsAffected())
+		return false, fmt.Errorf("couldn't save order query, rows affected: %d, expected: 1", result.RowsAffected())
 	}
-	return err
+	return true, nil
+}
+
+// checkExistingOrder is called when the stored order has the same version,
+// it tells a redelivery of the same order from a conflicting one by content hash
+//
+// returns either customerrors.ErrOrderAlreadySaved or customerrors.ErrOrderConflict
+func checkExistingOrder(ctx context.Context, transaction pgx.Tx, orderUID string, contentHash string) error {
+	sql, args, err := squirrel.Select("content_hash").
+		From("order_service.orders").
+		Where(squirrel.Eq{"order_uid": orderUID}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("couldn't build an SQL query: %w", err)
+	}
+
+	// orders saved before hashes were introduced have NULL, they can't be compared
+	var existingHash *string
+	err = transaction.QueryRow(ctx, sql, args...).Scan(&existingHash)
+	if err != nil {
+		return fmt.Errorf("couldn't get existing order content hash: %w", err)
+	}
+
+	if existingHash != nil && *existingHash == contentHash {
+		return fmt.Errorf("%w: %s", customerrors.ErrOrderAlreadySaved, orderUID)
+	}
+	return fmt.Errorf("%w: %s", customerrors.ErrOrderConflict, orderUID)
 }
 
 func savePayment(ctx context.Context, transaction pgx.Tx, orderUID string, payment *models.Payment) error {
@@ -402,27 +961,39 @@ func saveDelivery(ctx context.Context, transaction pgx.Tx, orderUID string, deli
 	return err
 }
 
+// saveItems inserts all the items with one query, values are bound parameters
 func saveItems(ctx context.Context, transaction pgx.Tx, orderUID string, items *[]models.OrderItem) error {
 	if len(*items) == 0 {
 		return nil
 	}
 
-	valuesList := make([]string, len(*items))
-	for i, item := range *items {
-		valuesList[i] = fmt.Sprintf("('%s',%d,'%s',%d,'%s','%s',%d,'%s',%d,%d,'%s',%d)",
+	query := squirrel.
+		Insert("order_service.order_items").
+		Columns(
+			"order_id", "chrt_id", "track_number", "price", "rid", "name", "sale", "size",
+			"total_price", "nm_id", "brand", "status",
+		)
+	for _, item := range *items {
+		query = query.Values(
 			orderUID, item.ChrtID, item.TrackNumber, item.Price, item.RID, item.Name, item.Sale, item.Size,
-			item.TotalPrice, item.NmID, item.Brand, item.Status)
+			item.TotalPrice, item.NmID, item.Brand, item.Status,
+		)
 	}
-	values := strings.Join(valuesList, ",")
 
-	sql := fmt.Sprintf(
-		"INSERT INTO order_service.order_items(\"order_id\", \"chrt_id\", \"track_number\", \"price\", \"rid\", \"name\", \"sale\", \"size\", \"total_price\", \"nm_id\", \"brand\", \"status\") VALUES %s",
-		values,
-	)
+	sql, args, err := query.
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("couldn't build an SQL query: %w", err)
+	}
 
-	_, err := transaction.Exec(ctx, sql)
+	var result pgconn.CommandTag
+	result, err = transaction.Exec(ctx, sql, args...)
 	if err != nil {
 		return fmt.Errorf("couldn't exec save items query: %w", err)
 	}
-	return err
+	if result.RowsAffected() != int64(len(*items)) {
+		return fmt.Errorf("couldn't save items, rows affected: %d, expected: %d", result.RowsAffected(), len(*items))
+	}
+	return nil
 }
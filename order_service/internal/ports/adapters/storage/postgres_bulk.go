@@ -0,0 +1,280 @@
+package storage
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+	"order_service/internal/custom_errors"
+	"order_service/internal/models"
+	"order_service/pkg/logger"
+	"slices"
+)
+
+// UpsertOrders is implementation of such method in ports.OrderStorage
+//
+// The whole batch is written in one transaction with the outbox events: new orders are inserted with COPY,
+// stored ones are replaced as in UpsertOrder. Several versions of an order in the batch are applied in version order.
+// If the transaction fails, e.g. one order violates a CHECK or is inserted concurrently, every order is upserted
+// in its own transaction, so a bad order doesn't fail the good ones
+func (o *OrdersStoragePostgres) UpsertOrders(ctx context.Context, orders []models.Order) []error {
+	results, err := o.upsertOrdersBulk(ctx, orders)
+	if err == nil {
+		return results
+	}
+
+	logger.GetOrCreateLoggerFromCtx(ctx).Warn(ctx, "bulk upsert failed, upserting orders one by one",
+		zap.Int("count", len(orders)), zap.Error(err))
+
+	results = make([]error, len(orders))
+	for i, order := range orders {
+		results[i] = o.UpsertOrder(ctx, order)
+	}
+	return results
+}
+
+// upsertOrdersBulk upserts all the orders in one transaction
+//
+// results are the per-order outcomes of UpsertOrder that don't fail the transaction
+// (customerrors.ErrOrderOutdated, customerrors.ErrOrderAlreadySaved, customerrors.ErrOrderConflict).
+// err is the failure of the whole transaction, nothing is written then
+func (o *OrdersStoragePostgres) upsertOrdersBulk(ctx context.Context, orders []models.Order) (results []error, err error) {
+	// versions of the same order are applied one by one, from the oldest
+	byVersion := make([]int, len(orders))
+	for i := range byVersion {
+		byVersion[i] = i
+	}
+	slices.SortStableFunc(byVersion, func(a, b int) int {
+		return cmp.Or(
+			cmp.Compare(orders[a].OrderUID, orders[b].OrderUID),
+			cmp.Compare(orders[a].Version, orders[b].Version),
+		)
+	})
+
+	orderUIDs := make([]string, 0, len(orders))
+	for _, i := range byVersion {
+		if len(orderUIDs) == 0 || orderUIDs[len(orderUIDs)-1] != orders[i].OrderUID {
+			orderUIDs = append(orderUIDs, orders[i].OrderUID)
+		}
+	}
+
+	transaction, err := o.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't start transaction: %w", err)
+	}
+
+	// err is the named result, so errors of rollback and commit are actually returned
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("error bulk upserting orders transaction, rolling back: %w", err)
+			rollbackErr := transaction.Rollback(ctx)
+			if rollbackErr != nil {
+				err = fmt.Errorf("error rolling back transaction: %w. caused after this error: %w",
+					rollbackErr, err)
+			}
+			return
+		}
+		err = transaction.Commit(ctx)
+		if err != nil {
+			err = fmt.Errorf("couldn't commit bulk upsert orders transaction: %w", err)
+		}
+	}()
+
+	// step 1. lock the stored ones
+	var storedVersions map[string]int
+	storedVersions, err = lockOrdersVersions(ctx, transaction, orderUIDs)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't lock stored orders: %w", err)
+	}
+
+	// step 2. replace the stored ones, collect the oldest versions of the new ones
+	results = make([]error, len(orders))
+	newOrders := make([]models.Order, 0, len(orders))
+	newerVersions := make([]int, 0)
+	for _, i := range byVersion {
+		if _, found := storedVersions[orders[i].OrderUID]; found {
+			results[i], err = replaceInBulk(ctx, transaction, &orders[i], storedVersions)
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if len(newOrders) > 0 && newOrders[len(newOrders)-1].OrderUID == orders[i].OrderUID {
+			// it's stored only after the copy
+			newerVersions = append(newerVersions, i)
+			continue
+		}
+		newOrders = append(newOrders, orders[i])
+	}
+
+	// step 3. copy the new ones
+	err = copyOrders(ctx, transaction, newOrders)
+	if err != nil {
+		return nil, err
+	}
+
+	// step 4. replace the copied ones with their newer versions
+	for _, newOrder := range newOrders {
+		storedVersions[newOrder.OrderUID] = newOrder.Version
+	}
+	for _, i := range newerVersions {
+		results[i], err = replaceInBulk(ctx, transaction, &orders[i], storedVersions)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// check defer for more possible errors
+	return results, nil
+}
+
+// replaceInBulk replaces the stored order as replaceStoredOrder does and keeps storedVersions up to date
+//
+// result is the outcome of the order that doesn't fail the transaction, err fails it
+func replaceInBulk(
+	ctx context.Context, transaction pgx.Tx, order *models.Order, storedVersions map[string]int,
+) (result error, err error) {
+	err = replaceStoredOrder(ctx, transaction, order, storedVersions[order.OrderUID])
+	if errors.Is(err, customerrors.ErrOrderOutdated) ||
+		errors.Is(err, customerrors.ErrOrderAlreadySaved) ||
+		errors.Is(err, customerrors.ErrOrderConflict) {
+		// nothing is written for this order, the transaction is fine
+		return err, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error replacing order %s: %w", order.OrderUID, err)
+	}
+	storedVersions[order.OrderUID] = order.Version
+	return nil, nil
+}
+
+// lockOrdersVersions locks the stored orders with given order_uid and returns their versions by order_uid
+func lockOrdersVersions(ctx context.Context, transaction pgx.Tx, orderUIDs []string) (map[string]int, error) {
+	// ordered, so concurrent batches lock in the same order and don't deadlock
+	sql, args, err := squirrel.Select("order_uid", "version").
+		From("order_service.orders").
+		Where(squirrel.Eq{"order_uid": orderUIDs}).
+		OrderBy("order_uid").
+		Suffix("FOR UPDATE").
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("couldn't build an SQL query: %w", err)
+	}
+
+	rows, err := transaction.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't exec lock orders query: %w", err)
+	}
+	defer rows.Close()
+
+	versions := make(map[string]int)
+	for rows.Next() {
+		var orderUID string
+		var version int
+		err = rows.Scan(&orderUID, &version)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't scan order version: %w", err)
+		}
+		versions[orderUID] = version
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error reading orders versions rows: %w", err)
+	}
+	return versions, nil
+}
+
+// copyOrders inserts new orders, related entities, initial statuses and outbox events with COPY, one per table
+func copyOrders(ctx context.Context, transaction pgx.Tx, orders []models.Order) error {
+	if len(orders) == 0 {
+		return nil
+	}
+
+	orderRows := make([][]any, len(orders))
+	paymentRows := make([][]any, len(orders))
+	deliveryRows := make([][]any, len(orders))
+	itemRows := make([][]any, 0, len(orders))
+	statusRows := make([][]any, len(orders))
+	eventRows := make([][]any, len(orders))
+	for i, order := range orders {
+		contentHash, err := order.ContentHash()
+		if err != nil {
+			return err
+		}
+		event, err := models.NewOrderEvent(models.OrderEventTypeSaved, order)
+		if err != nil {
+			return err
+		}
+		statusRows[i] = []any{order.OrderUID, models.OrderStatusCreated}
+		eventRows[i] = []any{event.OrderUID, event.Type, event.Payload}
+
+		orderRows[i] = []any{
+			order.OrderUID, order.TrackNumber, order.Entry, order.Locale, order.InternalSignature, order.CustomerID,
+			order.DeliveryService, order.ShardKey, order.SmID, order.DateCreated, order.OofShard, order.Version, contentHash,
+		}
+		payment := order.Payment
+		paymentRows[i] = []any{
+			order.OrderUID, payment.Transaction, payment.RequestID, payment.Currency,
+			payment.Provider, payment.Amount, payment.PaymentDt, payment.Bank,
+			payment.DeliveryCost, payment.GoodsTotal, payment.CustomFee,
+		}
+		delivery := order.Delivery
+		deliveryRows[i] = []any{
+			order.OrderUID, delivery.Name, delivery.Phone, delivery.Zip, delivery.City, delivery.Address,
+			delivery.Region, delivery.Email,
+		}
+		for _, item := range order.Items {
+			itemRows = append(itemRows, []any{
+				order.OrderUID, item.ChrtID, item.TrackNumber, item.Price, item.RID, item.Name, item.Sale, item.Size,
+				item.TotalPrice, item.NmID, item.Brand, item.Status,
+			})
+		}
+	}
+
+	// orders go first, the others reference them
+	tables := []struct {
+		name    string
+		columns []string
+		rows    [][]any
+	}{
+		{"orders", []string{
+			"order_uid", "track_number", "entry", "locale", "internal_signature", "customer_id",
+			"delivery_service", "shardkey", "sm_id", "date_created", "oof_shard", "version", "content_hash",
+		}, orderRows},
+		{"payments", []string{
+			"order_id", "transaction", "request_id", "currency", "provider",
+			"amount", "payment_dt", "bank", "delivery_cost", "goods_total", "custom_fee",
+		}, paymentRows},
+		{"deliveries", []string{
+			"order_id", "name", "phone", "zip", "city", "address", "region", "email",
+		}, deliveryRows},
+		{"order_items", []string{
+			"order_id", "chrt_id", "track_number", "price", "rid", "name", "sale", "size",
+			"total_price", "nm_id", "brand", "status",
+		}, itemRows},
+		{"order_status_events", []string{
+			"order_uid", "status",
+		}, statusRows},
+		{"order_events", []string{
+			"order_uid", "event_type", "payload",
+		}, eventRows},
+	}
+
+	for _, table := range tables {
+		if len(table.rows) == 0 {
+			continue
+		}
+		copied, err := transaction.CopyFrom(ctx,
+			pgx.Identifier{"order_service", table.name}, table.columns, pgx.CopyFromRows(table.rows))
+		if err != nil {
+			return fmt.Errorf("couldn't copy %s: %w", table.name, err)
+		}
+		if copied != int64(len(table.rows)) {
+			return fmt.Errorf("couldn't copy %s, rows copied: %d, expected: %d", table.name, copied, len(table.rows))
+		}
+	}
+	return nil
+}
@@ -4,13 +4,66 @@ import (
 	"context"
 	"order_service/internal/models"
 	"order_service/pkg/pkgports"
+	"time"
 )
 
 // OrderStorage port describes a persistent orders storage, e.g. postgres
+//
+// Orders are read with their status timelines, see models.Order StatusTimeline
 type OrderStorage interface {
 	GetOrderByID(ctx context.Context, orderID string) (models.Order, error)
 	GetLastOrders(ctx context.Context, limit int) ([]models.Order, error)
-	SaveOrder(ctx context.Context, order models.Order) error
+	ListOrders(ctx context.Context, filter models.OrdersFilter) (models.OrdersPage, error)
+	// UpsertOrder saves a new order or replaces the stored one if given order has a bigger version
+	UpsertOrder(ctx context.Context, order models.Order) error
+	// UpsertOrders upserts a batch of orders as UpsertOrder does, the result of every order is at its index
+	UpsertOrders(ctx context.Context, orders []models.Order) []error
+	// DeleteOrder deletes an order with everything related to it, customerrors.ErrOrderNotFound if there's none
+	DeleteOrder(ctx context.Context, orderUID string) error
+
+	// ChangeOrderStatus appends a status event of the order or its item with nmID (0 is the order itself)
+	// if the current status allows it, customerrors.ErrIllegalStatusTransition otherwise
+	ChangeOrderStatus(
+		ctx context.Context, orderUID string, nmID int, status models.OrderStatus, comment string,
+	) (models.OrderStatusEvent, error)
+}
+
+// DeadLetterStorage port describes a persistent storage of dead-lettered messages, e.g. postgres
+type DeadLetterStorage interface {
+	// SaveDeadLetter saves a dead letter, a dead letter with the same original topic/partition/offset is saved once,
+	// ones with an unknown origin are always saved
+	SaveDeadLetter(ctx context.Context, deadLetter models.DeadLetter) error
+	GetDeadLetter(ctx context.Context, id int64) (models.DeadLetter, error)
+	// ListDeadLetters returns up to filter.Limit dead letters with ID > filter.AfterID, ordered by ID
+	ListDeadLetters(ctx context.Context, filter models.DeadLettersFilter) ([]models.DeadLetter, error)
+	MarkDeadLetterReplayed(ctx context.Context, id int64) error
+	DeleteDeadLetter(ctx context.Context, id int64) error
+	// PurgeDeadLetters deletes every dead letter that matches the filter (Limit and AfterID are ignored)
+	PurgeDeadLetters(ctx context.Context, filter models.DeadLettersFilter) (int64, error)
+}
+
+// OrderEventStorage port describes the reading side of the order events outbox, e.g. postgres
+//
+// Events are written by OrderStorage in the same transactions as orders
+type OrderEventStorage interface {
+	// ClaimUnsentOrderEvents leases up to limit events that aren't marked sent or leased, ordered by ID.
+	// Other callers don't get them until the lease is over, then they're claimed again unless marked sent
+	ClaimUnsentOrderEvents(ctx context.Context, limit int, lease time.Duration) ([]models.OrderEvent, error)
+	MarkOrderEventsSent(ctx context.Context, ids []int64) error
+}
+
+// OrderEventPublisher port describes a message queue producer of order events, e.g. kafka
+type OrderEventPublisher interface {
+	// PublishOrderEvents returns after all the events are accepted by the queue, events of an order keep their order
+	PublishOrderEvents(ctx context.Context, events []models.OrderEvent) error
+}
+
+// DeadLetterReceiver port describes a consumer of the DLQ, e.g. kafka
+//
+// values are read with Consume method and must be commited with Commit after they're stored
+type DeadLetterReceiver[MessageType any] interface {
+	Consume(ctx context.Context) (models.DeadLetter, MessageType, error)
+	Commit(ctx context.Context, givenMessage MessageType) error
 }
 
 // OrderReceiver port describes a message queue consumer that gets orders for save, e.g. kafka
@@ -18,7 +71,13 @@ type OrderStorage interface {
 // values are read with Consume method and must be commited with either OnSuccess or OnFail
 type OrderReceiver[MessageType any] pkgports.Receiver[models.Order, MessageType]
 
+// BatchOrderReceiver port describes an OrderReceiver that commits processed batches at once
+type BatchOrderReceiver[MessageType any] pkgports.BatchReceiver[models.Order, MessageType]
+
 // OrderCache describes a cache that might be
 // implemented with different storages (e.g. in-memory, redis)
 // and mechanisms (e.g. N last saved)
 type OrderCache pkgports.Cache[string, models.Order]
+
+// NotFoundOrderCache describes a cache of order_uids that aren't stored, so they aren't looked up in storage again
+type NotFoundOrderCache pkgports.Cache[string, struct{}]
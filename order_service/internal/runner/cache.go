@@ -0,0 +1,35 @@
+package runner
+
+import (
+	"context"
+	"go.uber.org/zap"
+	"order_service/pkg/logger"
+	"order_service/pkg/pkgports/adapters/cache/lru"
+	"order_service/pkg/pkgports/adapters/cache/tiered"
+	"time"
+)
+
+// RunCacheExpiry launches removing expired values of an in-memory cache in background, logs the beginning and the end if failure
+func RunCacheExpiry[Key comparable, Value any](ctx context.Context, cache *lru.CacheLRUInMemory[Key, Value]) {
+	logger.GetLoggerFromCtx(ctx).Info(ctx, "starting removing expired cache values")
+	if err := cache.StartExpiring(ctx); err != nil {
+		logger.GetLoggerFromCtx(ctx).Error(ctx, "failed to remove expired cache values", zap.Error(err))
+	}
+}
+
+// ShutdownCacheExpiry stops removing expired values of an in-memory cache with 10 seconds timeout
+func ShutdownCacheExpiry[Key comparable, Value any](ctx context.Context, cache *lru.CacheLRUInMemory[Key, Value]) {
+	cancelCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	cache.StopExpiring(cancelCtx)
+}
+
+// RunCacheInvalidations launches dropping L1 values changed by other instances in background, logs the beginning and
+// the end if failure. It's stopped with ctx
+func RunCacheInvalidations[Key comparable, Value any](ctx context.Context, cache *tiered.CacheTwoTier[Key, Value]) {
+	logger.GetLoggerFromCtx(ctx).Info(ctx, "starting receiving cache invalidations")
+	if err := cache.StartInvalidations(ctx); err != nil {
+		logger.GetLoggerFromCtx(ctx).Error(ctx, "failed to receive cache invalidations", zap.Error(err))
+	}
+}
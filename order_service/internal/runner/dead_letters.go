@@ -0,0 +1,25 @@
+package runner
+
+import (
+	"context"
+	"go.uber.org/zap"
+	"order_service/internal/service"
+	"order_service/pkg/logger"
+	"time"
+)
+
+// RunDeadLetterCollector launches a DLQ collector in background, logs the beginning and the end if failure
+func RunDeadLetterCollector[T any](ctx context.Context, collector *service.DeadLetterCollectorService[T]) {
+	logger.GetLoggerFromCtx(ctx).Info(ctx, "starting collecting dead letters")
+	if err := collector.StartCollecting(ctx); err != nil {
+		logger.GetLoggerFromCtx(ctx).Error(ctx, "failed to collect dead letters", zap.Error(err))
+	}
+}
+
+// ShutdownDeadLetterCollector stops collector from reading the DLQ with 10 seconds timeout
+func ShutdownDeadLetterCollector[T any](ctx context.Context, collector *service.DeadLetterCollectorService[T]) {
+	cancelCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	collector.StopCollecting(cancelCtx)
+}
@@ -0,0 +1,25 @@
+package runner
+
+import (
+	"context"
+	"go.uber.org/zap"
+	"order_service/internal/service"
+	"order_service/pkg/logger"
+	"time"
+)
+
+// RunOrderEventRelay launches an outbox relay in background, logs the beginning and the end if failure
+func RunOrderEventRelay(ctx context.Context, relay *service.OrderEventRelayService) {
+	logger.GetLoggerFromCtx(ctx).Info(ctx, "starting relaying order events")
+	if err := relay.StartRelaying(ctx); err != nil {
+		logger.GetLoggerFromCtx(ctx).Error(ctx, "failed to relay order events", zap.Error(err))
+	}
+}
+
+// ShutdownOrderEventRelay stops relay from publishing order events with 10 seconds timeout
+func ShutdownOrderEventRelay(ctx context.Context, relay *service.OrderEventRelayService) {
+	cancelCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	relay.StopRelaying(cancelCtx)
+}
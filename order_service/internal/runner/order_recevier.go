@@ -16,7 +16,7 @@ func RunOrderReceiver[T any](ctx context.Context, receiver *service.OrderReceive
 	}
 }
 
-// ShutdownOrderReceiver stops receiver from receiving new orders with 10 seconds timeout
+// ShutdownOrderReceiver stops receiver from receiving new orders and waits for in-flight ones with 10 seconds timeout
 func ShutdownOrderReceiver[T any](ctx context.Context, receiver *service.OrderReceiverService[T]) {
 	cancelCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
@@ -0,0 +1,91 @@
+package service
+
+import (
+	"context"
+	"go.uber.org/zap"
+	"order_service/internal/ports"
+	"order_service/pkg/logger"
+	"order_service/pkg/pkgports"
+	"time"
+)
+
+// DeadLetterCollectorService is a service that reads the DLQ continuously and stores dead letters
+//
+// A DLQ message is committed only after it's stored, so nothing is lost.
+// It supports different implementations, so MessageType is generic
+type DeadLetterCollectorService[MessageType any] struct {
+	receiver ports.DeadLetterReceiver[MessageType]
+	storage  ports.DeadLetterStorage
+	backoff  pkgports.BackoffPolicy
+
+	done chan struct{}
+}
+
+// NewDeadLetterCollectorService creates a new collector service with given receiver, storage
+// and backoff policy between attempts to store a dead letter
+func NewDeadLetterCollectorService[MessageType any](
+	receiver ports.DeadLetterReceiver[MessageType], storage ports.DeadLetterStorage, backoff pkgports.BackoffPolicy,
+) *DeadLetterCollectorService[MessageType] {
+	return &DeadLetterCollectorService[MessageType]{receiver: receiver, storage: storage, backoff: backoff, done: make(chan struct{})}
+}
+
+// StartCollecting is the main loop function that is meant to be run in background
+func (s *DeadLetterCollectorService[_]) StartCollecting(ctx context.Context) error {
+out:
+	for {
+		select {
+		case <-ctx.Done():
+			break out
+		case <-s.done:
+			break out
+		default:
+			// step 1: try to consume
+			deadLetter, msg, err := s.receiver.Consume(ctx)
+			if err != nil {
+				logger.GetLoggerFromCtx(ctx).Error(ctx, "error while receiving dead letters",
+					zap.Error(err))
+				break
+			}
+
+			// step 2: store, until it works or we're stopped
+			var delay time.Duration
+			firstFailedAt := time.Now()
+			for attempt := 1; ; attempt++ {
+				err = s.storage.SaveDeadLetter(ctx, deadLetter)
+				if err == nil {
+					break
+				}
+
+				// a dead letter is never dropped, see nextRetryDelay
+				delay = nextRetryDelay(s.backoff, attempt, delay, time.Since(firstFailedAt))
+				logger.GetLoggerFromCtx(ctx).Error(ctx, "error while saving dead letter, retrying",
+					zap.Int("attempt", attempt), zap.Duration("delay", delay), zap.Error(err))
+
+				select {
+				case <-ctx.Done():
+					break out
+				case <-s.done:
+					break out
+				case <-time.After(delay):
+				}
+			}
+
+			// step 3: commit
+			err = s.receiver.Commit(ctx, msg)
+			if err != nil {
+				logger.GetLoggerFromCtx(ctx).Error(ctx, "error while committing stored dead letter", zap.Error(err))
+			}
+		}
+	}
+	return nil
+}
+
+// StopCollecting sends a signal to stop looping in the StartCollecting
+//
+// gives up when ctx is done, e.g. the loop has already stopped because of its own ctx
+func (s *DeadLetterCollectorService[_]) StopCollecting(ctx context.Context) {
+	select {
+	case s.done <- struct{}{}:
+	case <-ctx.Done():
+	}
+}
@@ -0,0 +1,155 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"go.uber.org/zap"
+	"order_service/internal/models"
+	"order_service/internal/ports"
+	"order_service/internal/validators"
+	"order_service/pkg/logger"
+)
+
+// DeadLetterService is a service that lets operators inspect, replay and purge dead letters
+//
+// Replayed orders are validated again and passed to processOrderFunction,
+// the same one that OrderReceiverService uses
+type DeadLetterService struct {
+	storage              ports.DeadLetterStorage
+	processOrderFunction ProcessOrderFunction
+}
+
+// NewDeadLetterService creates a new DeadLetterService
+func NewDeadLetterService(storage ports.DeadLetterStorage, processOrderFunction ProcessOrderFunction) *DeadLetterService {
+	return &DeadLetterService{
+		storage:              storage,
+		processOrderFunction: processOrderFunction,
+	}
+}
+
+// ListDeadLetters gets a page of dead letters that match the filter
+func (s *DeadLetterService) ListDeadLetters(ctx context.Context, filter models.DeadLettersFilter) ([]models.DeadLetter, error) {
+	result, err := s.storage.ListDeadLetters(ctx, filter)
+	if err != nil {
+		logger.GetLoggerFromCtx(ctx).Error(ctx, "error listing dead letters",
+			zap.Any("filter", filter), zap.Error(err))
+		return nil, fmt.Errorf("error listing dead letters: %w", err)
+	}
+	return result, nil
+}
+
+// GetDeadLetter gets a dead letter by ID
+func (s *DeadLetterService) GetDeadLetter(ctx context.Context, id int64) (models.DeadLetter, error) {
+	return s.storage.GetDeadLetter(ctx, id)
+}
+
+// DeleteDeadLetter deletes a dead letter by ID
+func (s *DeadLetterService) DeleteDeadLetter(ctx context.Context, id int64) error {
+	err := s.storage.DeleteDeadLetter(ctx, id)
+	if err != nil {
+		return err
+	}
+	logger.GetLoggerFromCtx(ctx).Info(ctx, "deleted dead letter", zap.Int64("id", id))
+	return nil
+}
+
+// PurgeDeadLetters deletes every dead letter that matches the filter, returns the amount of deleted ones
+func (s *DeadLetterService) PurgeDeadLetters(ctx context.Context, filter models.DeadLettersFilter) (int64, error) {
+	deleted, err := s.storage.PurgeDeadLetters(ctx, filter)
+	if err != nil {
+		logger.GetLoggerFromCtx(ctx).Error(ctx, "error purging dead letters",
+			zap.Any("filter", filter), zap.Error(err))
+		return 0, fmt.Errorf("error purging dead letters: %w", err)
+	}
+	logger.GetLoggerFromCtx(ctx).Info(ctx, "purged dead letters", zap.Int64("deleted", deleted))
+	return deleted, nil
+}
+
+// ReplayDeadLetter replays 1 dead letter by ID, the outcome is in the result
+//
+// error is returned only if the dead letter couldn't be read (e.g. customerrors.ErrDeadLetterNotFound)
+func (s *DeadLetterService) ReplayDeadLetter(ctx context.Context, id int64) (models.ReplayResult, error) {
+	deadLetter, err := s.storage.GetDeadLetter(ctx, id)
+	if err != nil {
+		return models.ReplayResult{}, err
+	}
+	return s.replay(ctx, deadLetter), nil
+}
+
+// ReplayDeadLetters replays up to filter.Limit not yet replayed dead letters that match the filter
+//
+// Every dead letter is replayed separately, the outcome is reported for each of them
+func (s *DeadLetterService) ReplayDeadLetters(ctx context.Context, filter models.DeadLettersFilter) ([]models.ReplayResult, error) {
+	notReplayed := false
+	filter.Replayed = &notReplayed
+
+	deadLetters, err := s.ListDeadLetters(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]models.ReplayResult, len(deadLetters))
+	for i, deadLetter := range deadLetters {
+		results[i] = s.replay(ctx, deadLetter)
+	}
+
+	logger.GetLoggerFromCtx(ctx).Info(ctx, "replayed dead letters", zap.Int("count", len(results)))
+
+	return results, nil
+}
+
+// replay decodes, validates and processes the order of given dead letter, then marks it replayed
+//
+// replaying twice is safe: replayed dead letters are skipped and order processing is idempotent
+func (s *DeadLetterService) replay(ctx context.Context, deadLetter models.DeadLetter) models.ReplayResult {
+	result := models.ReplayResult{
+		ID:       deadLetter.ID,
+		OrderUID: deadLetter.OrderUID,
+	}
+
+	if deadLetter.ReplayedAt != nil {
+		result.Outcome = models.ReplayOutcomeAlreadyReplayed
+		return result
+	}
+
+	// step 1: decode
+	var order models.Order
+	err := json.Unmarshal(deadLetter.Payload, &order)
+	if err != nil {
+		result.Outcome = models.ReplayOutcomeInvalid
+		result.Error = fmt.Errorf("error while unmarshalling payload: %w", err).Error()
+		return result
+	}
+
+	// step 2: validate
+	err = validators.ValidateOrder(order)
+	if err != nil {
+		result.Outcome = models.ReplayOutcomeInvalid
+		result.Error = fmt.Errorf("invalid order: %w", err).Error()
+		return result
+	}
+
+	// step 3: process
+	err = s.processOrderFunction(ctx, order)
+	if err != nil {
+		logger.GetLoggerFromCtx(ctx).Warn(ctx, "error replaying dead letter",
+			zap.Int64("id", deadLetter.ID), zap.Error(err))
+		result.Outcome = models.ReplayOutcomeFailed
+		result.Error = err.Error()
+		return result
+	}
+
+	// step 4: remember it, replaying it again would be a no-op anyway
+	err = s.storage.MarkDeadLetterReplayed(ctx, deadLetter.ID)
+	if err != nil {
+		logger.GetLoggerFromCtx(ctx).Error(ctx, "order is replayed, but dead letter isn't marked",
+			zap.Int64("id", deadLetter.ID), zap.Error(err))
+		result.Outcome = models.ReplayOutcomeFailed
+		result.Error = fmt.Errorf("order is processed, but couldn't mark dead letter replayed: %w", err).Error()
+		return result
+	}
+
+	result.Outcome = models.ReplayOutcomeReplayed
+	return result
+}
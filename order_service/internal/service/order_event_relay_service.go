@@ -0,0 +1,140 @@
+package service
+
+import (
+	"context"
+	"go.uber.org/zap"
+	"order_service/internal/ports"
+	"order_service/pkg/logger"
+	"order_service/pkg/pkgports"
+	"time"
+)
+
+// OrderEventRelayService is a service that publishes the order events outbox continuously
+//
+// Events are marked sent only after they're published, so delivery is at-least-once:
+// if publishing or marking fails or the service stops in between, the events are published again after their lease.
+// Relays of several replicas share the outbox, an event is published by the one that has claimed it
+type OrderEventRelayService struct {
+	storage      ports.OrderEventStorage
+	publisher    ports.OrderEventPublisher
+	batchSize    int
+	pollInterval time.Duration
+	lease        time.Duration
+	backoff      pkgports.BackoffPolicy
+
+	done chan struct{}
+}
+
+// NewOrderEventRelayService creates a new relay service with given storage and publisher
+//
+// Up to batchSize events are published at once, the outbox is checked every pollInterval when it's empty.
+// Claimed events aren't published by other relays for lease, it must be longer than publishing a batch.
+// backoff is the delay policy between failed attempts. batchSize < 1 is replaced with 1
+func NewOrderEventRelayService(
+	storage ports.OrderEventStorage, publisher ports.OrderEventPublisher,
+	batchSize int, pollInterval, lease time.Duration, backoff pkgports.BackoffPolicy,
+) *OrderEventRelayService {
+	return &OrderEventRelayService{
+		storage:      storage,
+		publisher:    publisher,
+		batchSize:    max(batchSize, 1),
+		pollInterval: pollInterval,
+		lease:        lease,
+		backoff:      backoff,
+		done:         make(chan struct{}),
+	}
+}
+
+// StartRelaying is the main loop function that is meant to be run in background
+func (s *OrderEventRelayService) StartRelaying(ctx context.Context) error {
+	var attempt int
+	var delay time.Duration
+	var firstFailedAt time.Time
+
+	// onFail returns the delay before the next attempt, see nextRetryDelay
+	onFail := func(msg string, err error) time.Duration {
+		attempt++
+		if attempt == 1 {
+			firstFailedAt = time.Now()
+		}
+		delay = nextRetryDelay(s.backoff, attempt, delay, time.Since(firstFailedAt))
+		logger.GetLoggerFromCtx(ctx).Error(ctx, msg,
+			zap.Int("attempt", attempt), zap.Duration("delay", delay), zap.Error(err))
+		return delay
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-s.done:
+			return nil
+		default:
+		}
+
+		// step 1: claim unsent events
+		events, err := s.storage.ClaimUnsentOrderEvents(ctx, s.batchSize, s.lease)
+		if err != nil {
+			if !s.wait(ctx, onFail("error while reading order events, retrying", err)) {
+				return nil
+			}
+			continue
+		}
+		if len(events) == 0 {
+			if !s.wait(ctx, s.pollInterval) {
+				return nil
+			}
+			continue
+		}
+
+		// step 2: publish
+		err = s.publisher.PublishOrderEvents(ctx, events)
+		if err != nil {
+			if !s.wait(ctx, onFail("error while publishing order events, retrying", err)) {
+				return nil
+			}
+			continue
+		}
+
+		// step 3: mark sent, if it fails they're published again after the lease
+		ids := make([]int64, len(events))
+		for i, event := range events {
+			ids[i] = event.ID
+		}
+		err = s.storage.MarkOrderEventsSent(ctx, ids)
+		if err != nil {
+			if !s.wait(ctx, onFail("error while marking order events sent, retrying", err)) {
+				return nil
+			}
+			continue
+		}
+		attempt, delay = 0, 0
+
+		// a full batch means there might be more, no waiting then
+		if len(events) < s.batchSize && !s.wait(ctx, s.pollInterval) {
+			return nil
+		}
+	}
+}
+
+// wait sleeps for d, returns false if the service is stopped meanwhile
+func (s *OrderEventRelayService) wait(ctx context.Context, d time.Duration) bool {
+	select {
+	case <-ctx.Done():
+		return false
+	case <-s.done:
+		return false
+	case <-time.After(d):
+		return true
+	}
+}
+
+// StopRelaying sends a signal to stop looping in the StartRelaying
+//
+// gives up when ctx is done, e.g. the loop has already stopped because of its own ctx
+func (s *OrderEventRelayService) StopRelaying(ctx context.Context) {
+	select {
+	case s.done <- struct{}{}:
+	case <-ctx.Done():
+	}
+}
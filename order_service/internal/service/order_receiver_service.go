@@ -2,93 +2,356 @@ package service
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"go.uber.org/zap"
+	"order_service/internal/custom_errors"
 	"order_service/internal/models"
 	"order_service/internal/ports"
 	"order_service/internal/validators"
 	"order_service/pkg/logger"
+	"order_service/pkg/pkgports"
+	"sync"
+	"sync/atomic"
+	"time"
 )
 
 // ProcessOrderFunction is the type of function that can be called on each received order
 type ProcessOrderFunction func(context.Context, models.Order) error
 
+// ProcessOrdersFunction is the type of function that can be called on each received batch of orders,
+// the result of every order is at its index
+type ProcessOrdersFunction func(context.Context, []models.Order) []error
+
+// WorkerStats are the metrics of a single worker of OrderReceiverService
+type WorkerStats struct {
+	ID        int
+	Processed uint64        // successfully processed orders
+	Failed    uint64        // orders that failed processing
+	Busy      bool          // the worker is processing an order right now
+	BusyTime  time.Duration // total time spent on processing
+}
+
+// workerMetrics is the concurrent-safe storage of WorkerStats
+type workerMetrics struct {
+	processed atomic.Uint64
+	failed    atomic.Uint64
+	busy      atomic.Bool
+	busyTime  atomic.Int64
+}
+
+// orderJob is a batch of consumed valid orders that waits for a free worker, a single order if not in batch mode
+type orderJob[MessageType any] struct {
+	orders []models.Order
+	msgs   []MessageType
+}
+
 // OrderReceiverService is a service that reads the orders continuously, validates and processes them
 //
+// Orders are processed by a fixed amount of workers. When all of them are busy,
+// the consume loop waits, so no more orders are read than can be processed.
+// In batch mode, a worker gets up to batchSize orders collected within batchWait at once.
 // It supports different implementations, so MessageType is generic
 type OrderReceiverService[MessageType any] struct {
 	receiver             ports.OrderReceiver[MessageType]
 	processOrderFunction ProcessOrderFunction
 
-	done chan struct{}
+	// batch mode, batchReceiver is nil if it's off
+	batchReceiver         ports.BatchOrderReceiver[MessageType]
+	processOrdersFunction ProcessOrdersFunction
+	batchSize             int
+	batchWait             time.Duration
+
+	jobs    chan orderJob[MessageType]
+	metrics []*workerMetrics
+
+	done      chan struct{}
+	stopped   chan struct{} // closed when the loop has stopped and all workers have drained
+	abort     chan struct{} // closed when StopReceivingOrders gives up, it cancels the in-flight orders
+	abortOnce sync.Once
 }
 
-// NewOrderReceiverService creates a new receiver service with given receiver repository and process function
-func NewOrderReceiverService[MessageType any](receiver ports.OrderReceiver[MessageType], processOrderFunction ProcessOrderFunction) *OrderReceiverService[MessageType] {
-	return &OrderReceiverService[MessageType]{receiver: receiver, processOrderFunction: processOrderFunction, done: make(chan struct{})}
+// NewOrderReceiverService creates a new receiver service with given receiver repository, process function
+// and amount of workers, workers < 1 is replaced with 1
+func NewOrderReceiverService[MessageType any](
+	receiver ports.OrderReceiver[MessageType], processOrderFunction ProcessOrderFunction, workers int,
+) *OrderReceiverService[MessageType] {
+	if workers < 1 {
+		workers = 1
+	}
+	metrics := make([]*workerMetrics, workers)
+	for i := range metrics {
+		metrics[i] = &workerMetrics{}
+	}
+	return &OrderReceiverService[MessageType]{
+		receiver:             receiver,
+		processOrderFunction: processOrderFunction,
+		batchSize:            1,
+		jobs:                 make(chan orderJob[MessageType]),
+		metrics:              metrics,
+		done:                 make(chan struct{}),
+		stopped:              make(chan struct{}),
+		abort:                make(chan struct{}),
+	}
+}
+
+// NewBatchOrderReceiverService creates a new receiver service in batch mode
+//
+// Up to batchSize orders are collected, but no longer than batchWait after the first one.
+// Every batch is processed with processOrdersFunction and committed at once,
+// failed orders of the batch are handled one by one. workers < 1 is replaced with 1
+func NewBatchOrderReceiverService[MessageType any](
+	receiver ports.BatchOrderReceiver[MessageType], processOrdersFunction ProcessOrdersFunction,
+	workers int, batchSize int, batchWait time.Duration,
+) *OrderReceiverService[MessageType] {
+	s := NewOrderReceiverService[MessageType](receiver, nil, workers)
+	s.batchReceiver = receiver
+	s.processOrdersFunction = processOrdersFunction
+	s.batchSize = max(batchSize, 1)
+	s.batchWait = batchWait
+	return s
 }
 
 // StartReceivingOrders is the main loop function that is meant to be run in background
-func (s *OrderReceiverService[_]) StartReceivingOrders(ctx context.Context) error {
-out:
+//
+// On return, all the consumed orders are already processed
+func (s *OrderReceiverService[MessageType]) StartReceivingOrders(ctx context.Context) error {
+	defer close(s.stopped)
+
+	// in-flight orders are finished even if ctx is cancelled, e.g. on shutdown,
+	// they're cancelled only when StopReceivingOrders gives up waiting, e.g. a DLQ write that's retried forever
+	workersCtx, cancelWorkers := context.WithCancel(context.WithoutCancel(ctx))
+	defer cancelWorkers()
+	go func() {
+		select {
+		case <-s.abort:
+			cancelWorkers()
+		case <-workersCtx.Done():
+		}
+	}()
+
+	var wg sync.WaitGroup
+	wg.Add(len(s.metrics))
+	for id := range s.metrics {
+		go func() {
+			defer wg.Done()
+			s.work(workersCtx, id)
+		}()
+	}
+
+	// step 3: drain, workers finish the orders they've got
+	defer func() {
+		close(s.jobs)
+		wg.Wait()
+		for _, stats := range s.WorkersStats() {
+			logger.GetLoggerFromCtx(ctx).Info(ctx, "order receiver worker stopped",
+				zap.Int("worker", stats.ID), zap.Uint64("processed", stats.Processed),
+				zap.Uint64("failed", stats.Failed), zap.Duration("busy time", stats.BusyTime))
+		}
+	}()
+
 	for {
 		select {
 		case <-ctx.Done():
-			break out
+			return nil
 		case <-s.done:
-			break out
+			return nil
 		default:
-			// step 1: try to consume
-			order, msg, err := s.receiver.Consume(ctx)
-			if err != nil {
-				logger.GetLoggerFromCtx(ctx).Error(ctx, "error while receiving orders",
-					zap.Error(err))
-				break
-			}
-
-			// step 2: validate
-			err = validators.ValidateOrder(order)
-			if err != nil {
-				logger.GetLoggerFromCtx(ctx).Warn(ctx, "invalid order", zap.Error(err))
-
-				// message is incorrect, no retries
-				err = s.receiver.OnFail(ctx, false, msg)
-				if err != nil {
-					logger.GetLoggerFromCtx(ctx).Error(ctx, "error while committing invalid message failure", zap.Error(err))
-				}
-				break
-			}
-
-			// step 3: process
-			go func() {
-				err = s.ProcessOrder(ctx, order)
-				if err != nil {
-					logger.GetLoggerFromCtx(ctx).Error(ctx, "error while processing order", zap.Error(err))
-
-					// send message to retry because of unknown DB errors
-					err = s.receiver.OnFail(ctx, true, msg)
-					if err != nil {
-						logger.GetLoggerFromCtx(ctx).Error(ctx, "error while committing valid message failure", zap.Error(err))
-					}
-				} else {
-					err = s.receiver.OnSuccess(ctx, msg)
-					if err != nil {
-						logger.GetLoggerFromCtx(ctx).Error(ctx, "error while committing successful message", zap.Error(err))
-					}
-				}
-			}()
 		}
+
+		// step 1: collect valid orders
+		job := s.collect(ctx)
+		if len(job.orders) == 0 {
+			continue
+		}
+
+		// step 2: hand over to a worker, blocks while all of them are busy
+		select {
+		case s.jobs <- job:
+		case <-ctx.Done():
+			// the messages aren't committed, so they're delivered again after restart
+			return nil
+		case <-s.done:
+			return nil
+		}
+	}
+}
+
+// collect consumes a job: a single order or, in batch mode, up to batchSize orders within batchWait
+func (s *OrderReceiverService[MessageType]) collect(ctx context.Context) orderJob[MessageType] {
+	var job orderJob[MessageType]
+
+	// the first order is awaited as long as needed
+	order, msg, ok := s.consume(ctx, ctx)
+	if !ok {
+		return job
+	}
+	job.orders = append(job.orders, order)
+	job.msgs = append(job.msgs, msg)
+
+	if s.batchSize <= 1 {
+		return job
+	}
+
+	batchCtx, cancel := context.WithTimeout(ctx, s.batchWait)
+	defer cancel()
+	for len(job.orders) < s.batchSize && batchCtx.Err() == nil {
+		order, msg, ok = s.consume(ctx, batchCtx)
+		if ok {
+			job.orders = append(job.orders, order)
+			job.msgs = append(job.msgs, msg)
+		}
+	}
+	return job
+}
+
+// consume reads a single order with consumeCtx and validates it, ok is false if there is no valid order
+//
+// Undecodable and invalid orders are sent to OnFail with ctx, so it's not interrupted by consumeCtx
+func (s *OrderReceiverService[MessageType]) consume(ctx, consumeCtx context.Context) (order models.Order, msg MessageType, ok bool) {
+	order, msg, err := s.receiver.Consume(consumeCtx)
+	if errors.Is(err, pkgports.ErrUndecodable) {
+		logger.GetLoggerFromCtx(ctx).Warn(ctx, "undecodable message", zap.Error(err))
+
+		// a poison message, no retries
+		err = s.receiver.OnFail(ctx, false, msg, err)
+		if err != nil {
+			logger.GetLoggerFromCtx(ctx).Error(ctx, "error while committing undecodable message failure", zap.Error(err))
+		}
+		return order, msg, false
+	}
+	if err != nil {
+		if consumeCtx.Err() == nil {
+			logger.GetLoggerFromCtx(ctx).Error(ctx, "error while receiving orders",
+				zap.Error(err))
+		} // otherwise stopping or the batch is over, not an error
+		return order, msg, false
+	}
+
+	err = validators.ValidateOrder(order)
+	if err != nil {
+		logger.GetLoggerFromCtx(ctx).Warn(ctx, "invalid order", zap.Error(err))
+
+		// message is incorrect, no retries
+		err = s.receiver.OnFail(ctx, false, msg, fmt.Errorf("invalid order: %w", err))
+		if err != nil {
+			logger.GetLoggerFromCtx(ctx).Error(ctx, "error while committing invalid message failure", zap.Error(err))
+		}
+		return order, msg, false
+	}
+	return order, msg, true
+}
+
+// work processes jobs until the channel is closed
+func (s *OrderReceiverService[MessageType]) work(ctx context.Context, id int) {
+	metrics := s.metrics[id]
+	for job := range s.jobs {
+		metrics.busy.Store(true)
+		start := time.Now()
+
+		if s.batchReceiver != nil {
+			s.processBatch(ctx, id, job)
+		} else {
+			s.processSingle(ctx, id, job.orders[0], job.msgs[0])
+		}
+
+		metrics.busyTime.Add(int64(time.Since(start)))
+		metrics.busy.Store(false)
+	}
+}
+
+// processSingle processes an order and commits its message
+func (s *OrderReceiverService[MessageType]) processSingle(ctx context.Context, id int, order models.Order, msg MessageType) {
+	processErr := s.ProcessOrder(ctx, order)
+	if processErr != nil {
+		s.onProcessFail(ctx, id, msg, processErr)
+		return
+	}
+
+	s.metrics[id].processed.Add(1)
+	commitErr := s.receiver.OnSuccess(ctx, msg)
+	if commitErr != nil {
+		logger.GetLoggerFromCtx(ctx).Error(ctx, "error while committing successful message", zap.Error(commitErr))
+	}
+}
+
+// processBatch processes a batch of orders, commits the successful ones at once and fails the others one by one
+func (s *OrderReceiverService[MessageType]) processBatch(ctx context.Context, id int, job orderJob[MessageType]) {
+	results := s.processOrdersFunction(ctx, job.orders)
+
+	succeeded := make([]MessageType, 0, len(job.msgs))
+	for i, processErr := range results {
+		if processErr != nil {
+			s.onProcessFail(ctx, id, job.msgs[i], processErr)
+			continue
+		}
+		succeeded = append(succeeded, job.msgs[i])
+	}
+
+	s.metrics[id].processed.Add(uint64(len(succeeded)))
+	commitErr := s.batchReceiver.OnSuccessBatch(ctx, succeeded)
+	if commitErr != nil {
+		logger.GetLoggerFromCtx(ctx).Error(ctx, "error while committing successful batch",
+			zap.Int("count", len(succeeded)), zap.Error(commitErr))
+	}
+}
+
+// onProcessFail sends a message that failed processing to OnFail
+func (s *OrderReceiverService[MessageType]) onProcessFail(ctx context.Context, id int, msg MessageType, processErr error) {
+	s.metrics[id].failed.Add(1)
+	logger.GetLoggerFromCtx(ctx).Error(ctx, "error while processing order",
+		zap.Int("worker", id), zap.Stringer("kind", customerrors.KindOf(processErr)), zap.Error(processErr))
+
+	// permanent errors and conflicts go straight to the DLQ, transient and unknown ones are worth a retry
+	shouldRetry := customerrors.IsRetryable(processErr)
+	commitErr := s.receiver.OnFail(ctx, shouldRetry, msg, processErr)
+	if commitErr != nil {
+		logger.GetLoggerFromCtx(ctx).Error(ctx, "error while committing valid message failure", zap.Error(commitErr))
 	}
-	return nil
 }
 
 // ProcessOrder is called on every valid order, calls processOrderFunction
-// provided in NewOrderReceiverService
+// provided in NewOrderReceiverService, or processOrdersFunction with a single order in batch mode
 func (s *OrderReceiverService[_]) ProcessOrder(ctx context.Context, order models.Order) error {
+	if s.processOrderFunction == nil {
+		return s.processOrdersFunction(ctx, []models.Order{order})[0]
+	}
 	return s.processOrderFunction(ctx, order)
 }
 
+// WorkersStats returns the metrics of every worker
+func (s *OrderReceiverService[_]) WorkersStats() []WorkerStats {
+	stats := make([]WorkerStats, len(s.metrics))
+	for id, metrics := range s.metrics {
+		stats[id] = WorkerStats{
+			ID:        id,
+			Processed: metrics.processed.Load(),
+			Failed:    metrics.failed.Load(),
+			Busy:      metrics.busy.Load(),
+			BusyTime:  time.Duration(metrics.busyTime.Load()),
+		}
+	}
+	return stats
+}
+
 // StopReceivingOrders sends a signal to stop looping in the StartReceivingOrders
+// and waits until in-flight orders are processed
+//
+// gives up when ctx is done: in-flight orders are cancelled then, their messages aren't committed,
+// so they're delivered again after restart
 func (s *OrderReceiverService[_]) StopReceivingOrders(ctx context.Context) {
-	// please tell me if it's nice or bad
-	s.done <- struct{}{}
+	select {
+	case s.done <- struct{}{}:
+	case <-s.stopped: // the loop has already stopped because of its own ctx
+	case <-ctx.Done():
+		s.abortOnce.Do(func() { close(s.abort) })
+		return
+	}
+
+	select {
+	case <-s.stopped:
+	case <-ctx.Done():
+		logger.GetOrCreateLoggerFromCtx(ctx).Warn(ctx, "gave up waiting for in-flight orders, cancelling them")
+		s.abortOnce.Do(func() { close(s.abort) })
+	}
 }
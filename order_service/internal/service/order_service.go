@@ -2,57 +2,148 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"go.uber.org/zap"
 	"golang.org/x/sync/errgroup"
+	"golang.org/x/sync/singleflight"
+	"order_service/internal/custom_errors"
 	"order_service/internal/models"
 	"order_service/internal/ports"
 	"order_service/pkg/logger"
+	"order_service/pkg/pkgports"
+	"sync/atomic"
 )
 
 // OrderService is a service that stores and retrieves the orders
 type OrderService struct {
 	storage ports.OrderStorage
 	cache   ports.OrderCache
+	// notFound remembers order_uids that aren't stored for a short time, so unknown IDs don't hit the storage
+	notFound ports.NotFoundOrderCache
+
+	// loads coalesces concurrent storage reads of the same order on cache misses
+	loads singleflight.Group
+	// coalesced is the amount of GetOrder calls that got the result of another call's storage read
+	coalesced atomic.Uint64
 }
 
 // NewOrderService creates a new OrderService
-func NewOrderService(storage ports.OrderStorage, cache ports.OrderCache) *OrderService {
+//
+// notFound must be small and have a short TTL: an order_uid stored by another instance
+// is reported as not found until it expires there
+func NewOrderService(storage ports.OrderStorage, cache ports.OrderCache, notFound ports.NotFoundOrderCache) *OrderService {
 	return &OrderService{
-		storage: storage,
-		cache:   cache,
+		storage:  storage,
+		cache:    cache,
+		notFound: notFound,
 	}
 }
 
 // GetOrder retrieves an order, firstly from cache, then storage. Caches found value on cache miss
+//
+// order_uids that were recently not found in storage are customerrors.ErrOrderNotFound right away.
+// Cache errors are logged and treated as misses, as in invalidateCachedOrder: the storage is the source of truth.
+// Concurrent misses of the same order share one storage read, which also caches it once.
+// The shared read isn't cancelled with ctx, so a cancelled caller doesn't fail the others: it returns ctx.Err() right away
 func (s *OrderService) GetOrder(ctx context.Context, orderUID string) (models.Order, error) {
 	// step 1. try to check cache first
 	result, found, err := s.cache.Get(ctx, orderUID)
 	if err != nil {
-		return models.Order{}, fmt.Errorf("error checking orders cache: %w", err)
+		logger.GetLoggerFromCtx(ctx).Error(ctx, "error checking orders cache, reading storage",
+			zap.String("key", orderUID), zap.Error(err))
+	}
+	if err == nil && found {
+		return result, nil
+	}
+	_, notFound, err := s.notFound.Get(ctx, orderUID)
+	if err != nil {
+		logger.GetLoggerFromCtx(ctx).Error(ctx, "error checking not found orders cache, reading storage",
+			zap.String("key", orderUID), zap.Error(err))
+	}
+	if err == nil && notFound {
+		return models.Order{}, fmt.Errorf("%w: %s (cached)", customerrors.ErrOrderNotFound, orderUID)
 	}
 
-	if !found {
-		// step 2. call the storage if not found in cache
-		result, err = s.storage.GetOrderByID(ctx, orderUID)
+	// step 2. call the storage if not found in cache, or join the call that's already made
+	//   loaded is set only in the goroutine of the call that reads the storage
+	var loaded bool
+	loadCtx := context.WithoutCancel(ctx)
+	resultCh := s.loads.DoChan(orderUID, func() (interface{}, error) {
+		loaded = true
+		return s.loadOrder(loadCtx, orderUID)
+	})
+
+	select {
+	case <-ctx.Done():
+		return models.Order{}, ctx.Err()
+	case res := <-resultCh:
+		if !loaded {
+			s.coalesced.Add(1)
+		}
+		if res.Err != nil {
+			return models.Order{}, res.Err
+		}
+		return res.Val.(models.Order), nil
+	}
+}
+
+// loadOrder reads an order from storage and caches it, it's the shared call of GetOrder
+//
+// If the cache is a pkgports.GenerationCache, the order isn't cached when it's invalidated during the read:
+// the read version might be already replaced then, see handleUpserted
+func (s *OrderService) loadOrder(ctx context.Context, orderUID string) (models.Order, error) {
+	// step 1. take the generation before the read, an invalidation after it bumps the generation
+	generationCache, guarded := s.cache.(pkgports.GenerationCache[string, models.Order])
+	var generation uint64
+	if guarded {
+		var err error
+		generation, err = generationCache.Generation(ctx, orderUID)
 		if err != nil {
-			logger.GetLoggerFromCtx(ctx).Error(ctx, "error retrieving order from storage",
+			// the order is still read, it's just not cached
+			logger.GetLoggerFromCtx(ctx).Error(ctx, "error getting cached order generation",
 				zap.String("key", orderUID), zap.Error(err))
-			return models.Order{}, err
+			generationCache = nil
 		}
+	}
 
-		// step 3. cache the value
-		go func() {
-			cacheErr := s.cache.Set(ctx, result.OrderUID, result)
-			if err != nil {
-				logger.GetLoggerFromCtx(ctx).Error(ctx, "error caching order",
-					zap.String("key", orderUID), zap.Error(cacheErr))
-			}
-		}()
-
+	// step 2. read the storage
+	result, err := s.storage.GetOrderByID(ctx, orderUID)
+	if errors.Is(err, customerrors.ErrOrderNotFound) {
+		// remember it, so the next reads don't hit the storage
+		cacheErr := s.notFound.Set(ctx, orderUID, struct{}{})
+		if cacheErr != nil {
+			logger.GetLoggerFromCtx(ctx).Error(ctx, "error caching not found order",
+				zap.String("key", orderUID), zap.Error(cacheErr))
+		}
+		return models.Order{}, err
+	}
+	if err != nil {
+		logger.GetLoggerFromCtx(ctx).Error(ctx, "error retrieving order from storage",
+			zap.String("key", orderUID), zap.Error(err))
+		return models.Order{}, err
 	}
 
-	return result, err
+	// step 3. cache the value
+	//   synchronously, the callers already wait for the read in their own goroutines
+	switch {
+	case !guarded:
+		cacheErr := s.cache.Set(ctx, result.OrderUID, result)
+		if cacheErr != nil {
+			logger.GetLoggerFromCtx(ctx).Error(ctx, "error caching order",
+				zap.String("key", orderUID), zap.Error(cacheErr))
+		}
+	case generationCache != nil:
+		cached, cacheErr := generationCache.SetIfGeneration(ctx, result.OrderUID, result, generation)
+		if cacheErr != nil {
+			logger.GetLoggerFromCtx(ctx).Error(ctx, "error caching order",
+				zap.String("key", orderUID), zap.Error(cacheErr))
+		} else if !cached {
+			logger.GetLoggerFromCtx(ctx).Debug(ctx, "order was invalidated while it was read, not cached",
+				zap.String("key", orderUID))
+		}
+	}
+	return result, nil
 }
 
 // GetLastOrders gets a list of last <=limit orders from storage
@@ -66,31 +157,181 @@ func (s *OrderService) GetLastOrders(ctx context.Context, limit int) ([]models.O
 	return result, nil
 }
 
-// SaveOrder saves an order in storage and runs a goroutine that caches it after return
-func (s *OrderService) SaveOrder(ctx context.Context, order models.Order) error {
-	// step 1. try to save in storage
-	err := s.storage.SaveOrder(ctx, order)
+// ListOrders gets a page of orders that match the filter from storage
+//
+// The cache is bypassed: it only knows orders by ID and can't filter or paginate
+func (s *OrderService) ListOrders(ctx context.Context, filter models.OrdersFilter) (models.OrdersPage, error) {
+	result, err := s.storage.ListOrders(ctx, filter)
 	if err != nil {
-		logger.GetLoggerFromCtx(ctx).Error(ctx, "error saving order",
+		logger.GetLoggerFromCtx(ctx).Error(ctx, "error listing orders",
+			zap.Any("filter", filter), zap.Error(err))
+		return models.OrdersPage{}, fmt.Errorf("error listing orders: %w", err)
+	}
+	return result, nil
+}
+
+// UpsertOrder saves a new order or replaces the stored one if given order has a bigger version
+//
+// Redeliveries and late messages with older versions are acknowledged, nothing is changed then.
+// On success the cached order is invalidated right away, so nobody reads the previous version from cache
+func (s *OrderService) UpsertOrder(ctx context.Context, order models.Order) error {
+	// step 1. try to upsert in storage
+	err := s.storage.UpsertOrder(ctx, order)
+
+	// step 2. acknowledge and cache
+	return s.handleUpserted(ctx, order, err)
+}
+
+// UpsertOrders upserts a batch of orders as UpsertOrder does, the result of every order is at its index
+func (s *OrderService) UpsertOrders(ctx context.Context, orders []models.Order) []error {
+	// step 1. try to upsert in storage
+	results := s.storage.UpsertOrders(ctx, orders)
+
+	// step 2. acknowledge and cache every order
+	for i, order := range orders {
+		results[i] = s.handleUpserted(ctx, order, results[i])
+	}
+	return results
+}
+
+// handleUpserted acknowledges redeliveries and outdated orders, invalidates the cached order if it's upserted
+func (s *OrderService) handleUpserted(ctx context.Context, order models.Order, err error) error {
+	if errors.Is(err, customerrors.ErrOrderAlreadySaved) || errors.Is(err, customerrors.ErrOrderOutdated) {
+		logger.GetLoggerFromCtx(ctx).Info(ctx, "order is already saved or outdated, acknowledged",
+			zap.String("key", order.OrderUID), zap.Int("version", order.Version), zap.Error(err))
+		return nil
+	}
+	if err != nil {
+		logger.GetLoggerFromCtx(ctx).Error(ctx, "error upserting order",
 			zap.String("key", order.OrderUID), zap.Error(err))
 		return err
 	}
 
-	// step 2. cache it for the future
-	//   only if value was successfully saved
-	go func() {
-		cacheErr := s.cache.Set(ctx, order.OrderUID, order)
-		if err != nil {
-			logger.GetLoggerFromCtx(ctx).Error(ctx, "error caching order",
-				zap.String("key", order.OrderUID), zap.Error(cacheErr))
-		}
-	}()
+	// invalidate the cache
+	//   synchronously and by deletion: concurrent upserts might set their versions in any order,
+	//   the next GetOrder caches the stored one. A GetOrder that has read the previous version doesn't, see loadOrder
+	s.invalidateCachedOrder(ctx, order.OrderUID)
+	s.forgetNotFound(ctx, order.OrderUID)
 
-	logger.GetLoggerFromCtx(ctx).Info(ctx, "saved order", zap.String("id", order.OrderUID))
+	logger.GetLoggerFromCtx(ctx).Info(ctx, "upserted order",
+		zap.String("id", order.OrderUID), zap.Int("version", order.Version))
 
 	return nil
 }
 
+// DeleteOrder deletes an order from storage and invalidates the cached one
+//
+// customerrors.ErrOrderNotFound is returned if there's no such order
+func (s *OrderService) DeleteOrder(ctx context.Context, orderUID string) error {
+	// step 1. delete from storage
+	err := s.storage.DeleteOrder(ctx, orderUID)
+
+	// step 2. invalidate the cache
+	//   even if it isn't stored, a stale order might be cached
+	s.invalidateCachedOrder(ctx, orderUID)
+
+	if err != nil {
+		logger.GetLoggerFromCtx(ctx).Warn(ctx, "error deleting order",
+			zap.String("key", orderUID), zap.Error(err))
+		return err
+	}
+
+	logger.GetLoggerFromCtx(ctx).Info(ctx, "deleted order", zap.String("id", orderUID))
+
+	return nil
+}
+
+// invalidateCachedOrder deletes an order from cache, errors are only logged: the cache isn't the source of truth
+//
+// The generation of a pkgports.GenerationCache is bumped too, so reads that are already made don't cache the order
+func (s *OrderService) invalidateCachedOrder(ctx context.Context, orderUID string) {
+	var err error
+	if generationCache, ok := s.cache.(pkgports.GenerationCache[string, models.Order]); ok {
+		_, err = generationCache.Invalidate(ctx, orderUID)
+	} else {
+		_, err = s.cache.Delete(ctx, orderUID)
+	}
+	if err != nil {
+		logger.GetLoggerFromCtx(ctx).Error(ctx, "error invalidating cached order",
+			zap.String("key", orderUID), zap.Error(err))
+	}
+}
+
+// forgetNotFound deletes a stored order_uid from the not found cache, errors are only logged as in invalidateCachedOrder
+//
+// A GetOrder that has read the storage right before the order was stored might still put it back,
+// it's found again after the short TTL then
+func (s *OrderService) forgetNotFound(ctx context.Context, orderUID string) {
+	_, err := s.notFound.Delete(ctx, orderUID)
+	if err != nil {
+		logger.GetLoggerFromCtx(ctx).Error(ctx, "error invalidating not found order",
+			zap.String("key", orderUID), zap.Error(err))
+	}
+}
+
+// ChangeOrderStatus changes the status of an order, or of its item with nmID if it isn't 0,
+// if the current status allows it. The cached order is invalidated, its status timeline is cached with it
+//
+// Unknown statuses and illegal transitions are customerrors.ErrIllegalStatusTransition
+func (s *OrderService) ChangeOrderStatus(
+	ctx context.Context, orderUID string, nmID int, status models.OrderStatus, comment string,
+) (models.OrderStatusEvent, error) {
+	if !status.IsValid() {
+		return models.OrderStatusEvent{}, fmt.Errorf("%w: unknown status %q", customerrors.ErrIllegalStatusTransition, status)
+	}
+
+	event, err := s.storage.ChangeOrderStatus(ctx, orderUID, nmID, status, comment)
+	if err != nil {
+		logger.GetLoggerFromCtx(ctx).Warn(ctx, "error changing order status",
+			zap.String("key", orderUID), zap.Int("nm_id", nmID), zap.String("status", string(status)), zap.Error(err))
+		return models.OrderStatusEvent{}, err
+	}
+	s.invalidateCachedOrder(ctx, orderUID)
+
+	logger.GetLoggerFromCtx(ctx).Info(ctx, "changed order status",
+		zap.String("id", orderUID), zap.Int("nm_id", nmID), zap.String("status", string(status)))
+
+	return event, nil
+}
+
+// GetCacheStats returns the counters of the orders cache
+//
+// The most and least used keys are filled only if the cache knows the order of its values, see pkgports.RecencyCache,
+// a tiered one tells them of its L1.
+// The tiers are filled only for a pkgports.TieredCache
+func (s *OrderService) GetCacheStats(_ context.Context) models.OrderCacheStats {
+	result := models.OrderCacheStats{
+		OrderCacheTierStats: cacheTierStats(s.cache.Stats()),
+		Coalesced:           s.coalesced.Load(),
+	}
+
+	if tieredCache, ok := s.cache.(pkgports.TieredCache[string, models.Order]); ok {
+		for _, tierStats := range tieredCache.TierStats() {
+			result.Tiers = append(result.Tiers, cacheTierStats(tierStats))
+		}
+	}
+
+	// the errors mean the cache is empty, the keys are left empty then
+	if recencyCache, ok := s.cache.(pkgports.RecencyCache[string, models.Order]); ok {
+		result.MostUsedKey, _ = recencyCache.MostUsedKey()
+		result.LeastUsedKey, _ = recencyCache.LeastUsedKey()
+	}
+	return result
+}
+
+// cacheTierStats maps pkgports.CacheStats into models.OrderCacheTierStats
+func cacheTierStats(stats pkgports.CacheStats) models.OrderCacheTierStats {
+	return models.OrderCacheTierStats{
+		Hits:      stats.Hits,
+		Misses:    stats.Misses,
+		Sets:      stats.Sets,
+		Evictions: stats.Evictions,
+		Expired:   stats.Expired,
+		Size:      stats.Size,
+		Capacity:  stats.Capacity,
+	}
+}
+
 // CacheLastOrders retrieves and saves last <=limit orders in cache
 func (s *OrderService) CacheLastOrders(ctx context.Context, limit int) error {
 	lastOrders, err := s.storage.GetLastOrders(ctx, limit)
@@ -115,9 +356,9 @@ func (s *OrderService) CacheLastOrders(ctx context.Context, limit int) error {
 		return fmt.Errorf("error caching last orders to cache: %w", err)
 	}
 
+	// the total amount of cached orders isn't logged, a shared cache would SCAN every key for it
 	logger.GetLoggerFromCtx(ctx).Info(ctx, "cached last orders",
 		zap.Int("count", len(lastOrders)),
-		zap.Int("total", s.cache.GetKeysAmount()),
 	)
 
 	return nil
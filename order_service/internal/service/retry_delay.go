@@ -0,0 +1,24 @@
+package service
+
+import (
+	"order_service/pkg/pkgports"
+	"time"
+)
+
+// minRetryDelay is the delay of endless retries when the backoff policy has none, e.g. it refuses the first attempt
+const minRetryDelay = time.Second
+
+// nextRetryDelay returns the delay before the next attempt of a loop that retries until it works
+//
+// When the policy gives up, the previous delay is kept: the loop can't drop what it retries.
+// A zero delay is replaced with minRetryDelay, so the loop doesn't spin against a failing dependency
+func nextRetryDelay(policy pkgports.BackoffPolicy, attempt int, previous, elapsed time.Duration) time.Duration {
+	delay, ok := policy.NextDelay(attempt, previous, elapsed)
+	if !ok {
+		delay = previous
+	}
+	if delay <= 0 {
+		delay = minRetryDelay
+	}
+	return delay
+}
@@ -129,10 +129,16 @@ func validateItems(items []models.OrderItem) error {
 		return fmt.Errorf("at least one item is required")
 	}
 
+	// nm_id identifies an item within the order, it's a part of the primary key
+	nmIDs := make(map[int]int, len(items))
 	for i, item := range items {
 		if err := validateItem(item); err != nil {
 			return fmt.Errorf("item %d: %w", i, err)
 		}
+		if first, ok := nmIDs[item.NmID]; ok {
+			return fmt.Errorf("item %d: nm_id %d is already used by item %d", i, item.NmID, first)
+		}
+		nmIDs[item.NmID] = i
 	}
 	return nil
 }
@@ -0,0 +1,163 @@
+package delayqueue
+
+import (
+	"container/heap"
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrFull describes an error when pushing into a queue that has reached its capacity
+var ErrFull = errors.New("delay queue is full")
+
+// item is a value stored in the queue with the time it becomes ready
+//
+// seq keeps FIFO order among items that are ready at the same time
+type item[ValueType any] struct {
+	value   ValueType
+	readyAt time.Time
+	seq     uint64
+}
+
+// itemsHeap is a min-heap of items ordered by readyAt, implements heap.Interface
+type itemsHeap[ValueType any] []item[ValueType]
+
+func (h itemsHeap[ValueType]) Len() int { return len(h) }
+
+func (h itemsHeap[ValueType]) Less(i, j int) bool {
+	if h[i].readyAt.Equal(h[j].readyAt) {
+		return h[i].seq < h[j].seq
+	}
+	return h[i].readyAt.Before(h[j].readyAt)
+}
+
+func (h itemsHeap[ValueType]) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+
+func (h *itemsHeap[ValueType]) Push(x any) { *h = append(*h, x.(item[ValueType])) }
+
+func (h *itemsHeap[ValueType]) Pop() any {
+	old := *h
+	n := len(old)
+	popped := old[n-1]
+	old[n-1] = item[ValueType]{} // don't keep a reference to the value
+	*h = old[:n-1]
+	return popped
+}
+
+// DelayQueue is a bounded queue of values that become available at a certain time, any ValueType supported
+//
+// Values are stored in a min-heap keyed on the ready time, so the earliest one is always on top.
+// Push and PopReady never block, WaitReady sleeps exactly until the earliest value is ready
+type DelayQueue[ValueType any] struct {
+	items    itemsHeap[ValueType]
+	capacity int
+	seq      uint64
+
+	// changed is closed and replaced on every push to wake up all waiters, the earliest ready time might change
+	changed chan struct{}
+	mu      sync.Mutex
+}
+
+// NewDelayQueue creates a new *DelayQueue with given capacity, capacity <= 0 means unbounded
+func NewDelayQueue[ValueType any](capacity int) *DelayQueue[ValueType] {
+	return &DelayQueue[ValueType]{
+		items:    make(itemsHeap[ValueType], 0),
+		capacity: capacity,
+		changed:  make(chan struct{}),
+	}
+}
+
+// Push adds a value that becomes ready at readyAt, returns ErrFull if there is no space left
+func (q *DelayQueue[ValueType]) Push(value ValueType, readyAt time.Time) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.capacity > 0 && len(q.items) >= q.capacity {
+		return ErrFull
+	}
+
+	q.seq++
+	heap.Push(&q.items, item[ValueType]{value: value, readyAt: readyAt, seq: q.seq})
+
+	close(q.changed)
+	q.changed = make(chan struct{})
+	return nil
+}
+
+// PopReady removes and returns the earliest value if it's ready, ok is false otherwise
+func (q *DelayQueue[ValueType]) PopReady() (value ValueType, ok bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if len(q.items) == 0 || q.items[0].readyAt.After(time.Now()) {
+		return value, false
+	}
+	return heap.Pop(&q.items).(item[ValueType]).value, true
+}
+
+// NextReadyAt returns the time when the earliest value becomes ready, ok is false if the queue is empty
+func (q *DelayQueue[ValueType]) NextReadyAt() (readyAt time.Time, ok bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if len(q.items) == 0 {
+		return readyAt, false
+	}
+	return q.items[0].readyAt, true
+}
+
+// WaitReady blocks until the earliest value is ready or ctx is done, the value isn't removed
+//
+// Pushing an earlier value while waiting wakes the waiter up at the new time
+func (q *DelayQueue[ValueType]) WaitReady(ctx context.Context) error {
+	for {
+		q.mu.Lock()
+		changed := q.changed
+		var readyAt time.Time
+		hasItems := len(q.items) > 0
+		if hasItems {
+			readyAt = q.items[0].readyAt
+		}
+		q.mu.Unlock()
+
+		// an empty queue waits only for a push
+		var timer *time.Timer
+		var timerC <-chan time.Time
+		if hasItems {
+			wait := time.Until(readyAt)
+			if wait <= 0 {
+				return nil
+			}
+			timer = time.NewTimer(wait)
+			timerC = timer.C
+		}
+
+		select {
+		case <-ctx.Done():
+			if timer != nil {
+				timer.Stop()
+			}
+			return ctx.Err()
+		case <-timerC:
+			return nil
+		case <-changed:
+			if timer != nil {
+				timer.Stop()
+			}
+		}
+	}
+}
+
+// Len returns the depth of the queue, both ready and not ready values are counted
+func (q *DelayQueue[ValueType]) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return len(q.items)
+}
+
+// Cap returns the capacity of the queue, <= 0 means unbounded
+func (q *DelayQueue[ValueType]) Cap() int {
+	return q.capacity
+}
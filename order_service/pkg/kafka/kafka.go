@@ -7,6 +7,7 @@ import (
 	"github.com/segmentio/kafka-go"
 	"go.uber.org/zap"
 	"order_service/pkg/logger"
+	"order_service/pkg/pkgports"
 	"time"
 )
 
@@ -45,6 +46,25 @@ func NewReader(ctx context.Context, cfg Config, topic, groupID string) *kafka.Re
 	return r
 }
 
+// NewWriter creates a new synchronous kafka.Writer for given topic
+//
+// It waits for all replicas to acknowledge every message, so a successful write is really stored
+func NewWriter(ctx context.Context, cfg Config, topic string) *kafka.Writer {
+	l := logger.GetOrCreateLoggerFromCtx(ctx)
+	w := &kafka.Writer{
+		Addr:         kafka.TCP(cfg.Brokers...),
+		Topic:        topic,
+		RequiredAcks: kafka.RequireAll,
+		Balancer:     &kafka.Hash{},
+		Async:        false,
+	}
+	l.Info(ctx, "created Kafka writer",
+		zap.Strings("brokers", cfg.Brokers),
+		zap.String("topic", topic),
+	)
+	return w
+}
+
 // CreateTopicIfNotExists safely creates a topic. Supposed to be called on startup to ensure that topic exists
 func CreateTopicIfNotExists(cfg Config, topic string, numPartitions, replicationFactor int) error {
 	if topic == "" {
@@ -78,16 +98,34 @@ func CreateTopicIfNotExists(cfg Config, topic string, numPartitions, replication
 }
 
 // CreateTopicWithRetry safely creates a topic using CreateTopicIfNotExists, but it gives a few tries
-func CreateTopicWithRetry(cfg Config, topic string, numPartitions, replicationFactor int, retries int) error {
+//
+// Delays between tries are given by backoff, it stops earlier if the policy gives up
+func CreateTopicWithRetry(
+	ctx context.Context, cfg Config, topic string, numPartitions, replicationFactor int,
+	retries int, backoff pkgports.BackoffPolicy,
+) error {
 	var err error
+	var delay time.Duration
+	start := time.Now()
 	for i := 0; i < retries; i++ {
 		err = CreateTopicIfNotExists(cfg, topic, numPartitions, replicationFactor)
 		if err == nil {
 			return nil
 		}
 
-		fmt.Printf("Attempt %d failed: %v\n", i+1, err)
-		time.Sleep(time.Second * time.Duration(i))
+		var ok bool
+		delay, ok = backoff.NextDelay(i+1, delay, time.Since(start))
+		if !ok {
+			break
+		}
+		logger.GetOrCreateLoggerFromCtx(ctx).Warn(ctx, "failed to create kafka topic, retrying",
+			zap.String("topic", topic), zap.Int("attempt", i+1), zap.Duration("delay", delay), zap.Error(err))
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%w: %w", ctx.Err(), err)
+		case <-time.After(delay):
+		}
 	}
 	return err
 }
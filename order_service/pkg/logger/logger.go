@@ -60,7 +60,7 @@ func TryAppendRequestIDFromContext(ctx context.Context, fields []zap.Field) []za
 
 // GetOrCreateLoggerFromCtx is a safe version on GetLoggerFromCtx that creates a new logger if no logger is in ctx
 func GetOrCreateLoggerFromCtx(ctx context.Context) *Logger {
-	logger := GetLoggerFromCtx(ctx)
+	logger, _ := ctx.Value(KeyForLogger).(*Logger)
 	if logger == nil {
 		logger, _ = NewLogger()
 	}
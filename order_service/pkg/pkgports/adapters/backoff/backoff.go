@@ -0,0 +1,143 @@
+package backoff
+
+import (
+	"math/rand/v2"
+	"order_service/pkg/pkgports"
+	"time"
+)
+
+// Fixed is an implementation of pkgports.BackoffPolicy that always waits the same delay
+type Fixed struct {
+	delay time.Duration
+}
+
+// NewFixed creates a new *Fixed policy, returning it as a pkgports.BackoffPolicy
+func NewFixed(delay time.Duration) pkgports.BackoffPolicy {
+	return &Fixed{delay: delay}
+}
+
+// NextDelay is implementation of such method in pkgports.BackoffPolicy
+func (f *Fixed) NextDelay(_ int, _, _ time.Duration) (time.Duration, bool) {
+	return f.delay, true
+}
+
+// Exponential is an implementation of pkgports.BackoffPolicy that multiplies the delay every attempt
+//
+// delay = min(max, base * multiplier^(attempt-1))
+type Exponential struct {
+	base       time.Duration
+	max        time.Duration
+	multiplier float64
+}
+
+// NewExponential creates a new *Exponential policy, returning it as a pkgports.BackoffPolicy
+//
+// max <= 0 means no upper bound, multiplier < 1 is replaced with 2
+func NewExponential(base, max time.Duration, multiplier float64) pkgports.BackoffPolicy {
+	return newExponential(base, max, multiplier)
+}
+
+func newExponential(base, max time.Duration, multiplier float64) *Exponential {
+	if multiplier < 1 {
+		multiplier = 2
+	}
+	return &Exponential{base: base, max: max, multiplier: multiplier}
+}
+
+// NextDelay is implementation of such method in pkgports.BackoffPolicy
+func (e *Exponential) NextDelay(attempt int, _, _ time.Duration) (time.Duration, bool) {
+	delay := float64(e.base)
+	for i := 1; i < attempt; i++ {
+		delay *= e.multiplier
+		// stop early, the delay only grows and might overflow
+		if e.max > 0 && delay >= float64(e.max) {
+			return e.max, true
+		}
+	}
+	if e.max > 0 && delay >= float64(e.max) {
+		return e.max, true
+	}
+	return time.Duration(delay), true
+}
+
+// FullJitter is an implementation of pkgports.BackoffPolicy that waits a random delay up to the exponential one
+//
+// delay = random(0, min(max, base * multiplier^(attempt-1))), spreads retries of many clients
+type FullJitter struct {
+	exponential *Exponential
+}
+
+// NewFullJitter creates a new *FullJitter policy, returning it as a pkgports.BackoffPolicy
+//
+// Arguments are the same as in NewExponential
+func NewFullJitter(base, max time.Duration, multiplier float64) pkgports.BackoffPolicy {
+	return &FullJitter{exponential: newExponential(base, max, multiplier)}
+}
+
+// NextDelay is implementation of such method in pkgports.BackoffPolicy
+func (f *FullJitter) NextDelay(attempt int, previous, elapsed time.Duration) (time.Duration, bool) {
+	delay, ok := f.exponential.NextDelay(attempt, previous, elapsed)
+	return randomDuration(0, delay), ok
+}
+
+// DecorrelatedJitter is an implementation of pkgports.BackoffPolicy that depends on the previous delay
+//
+// delay = min(max, random(base, previous * 3))
+type DecorrelatedJitter struct {
+	base time.Duration
+	max  time.Duration
+}
+
+// NewDecorrelatedJitter creates a new *DecorrelatedJitter policy, returning it as a pkgports.BackoffPolicy
+//
+// max <= 0 means no upper bound
+func NewDecorrelatedJitter(base, max time.Duration) pkgports.BackoffPolicy {
+	return &DecorrelatedJitter{base: base, max: max}
+}
+
+// NextDelay is implementation of such method in pkgports.BackoffPolicy
+func (d *DecorrelatedJitter) NextDelay(_ int, previous, _ time.Duration) (time.Duration, bool) {
+	if previous < d.base {
+		previous = d.base
+	}
+	delay := randomDuration(d.base, previous*3)
+	if d.max > 0 && delay > d.max {
+		delay = d.max
+	}
+	return delay, true
+}
+
+// MaxElapsed is a pkgports.BackoffPolicy decorator that stops retrying after a certain time
+//
+// The retry is rejected if it would start later than maxElapsed after the first failure
+type MaxElapsed struct {
+	policy     pkgports.BackoffPolicy
+	maxElapsed time.Duration
+}
+
+// NewMaxElapsed wraps given policy with a *MaxElapsed, returning it as a pkgports.BackoffPolicy
+//
+// maxElapsed <= 0 means no cap, the policy is returned as is
+func NewMaxElapsed(policy pkgports.BackoffPolicy, maxElapsed time.Duration) pkgports.BackoffPolicy {
+	if maxElapsed <= 0 {
+		return policy
+	}
+	return &MaxElapsed{policy: policy, maxElapsed: maxElapsed}
+}
+
+// NextDelay is implementation of such method in pkgports.BackoffPolicy
+func (m *MaxElapsed) NextDelay(attempt int, previous, elapsed time.Duration) (time.Duration, bool) {
+	delay, ok := m.policy.NextDelay(attempt, previous, elapsed)
+	if !ok || elapsed+delay > m.maxElapsed {
+		return 0, false
+	}
+	return delay, true
+}
+
+// randomDuration returns a random duration in [from, to], from if the range is empty
+func randomDuration(from, to time.Duration) time.Duration {
+	if to <= from {
+		return from
+	}
+	return from + rand.N(to-from+1)
+}
@@ -0,0 +1,46 @@
+package backoff
+
+import (
+	"fmt"
+	"order_service/pkg/pkgports"
+	"time"
+)
+
+// Names of the policies that can be selected in Config
+const (
+	PolicyFixed              = "fixed"
+	PolicyExponential        = "exponential"
+	PolicyFullJitter         = "full_jitter"
+	PolicyDecorrelatedJitter = "decorrelated_jitter"
+)
+
+// Config is a backoff policy config, meant to be embedded with an env-prefix
+type Config struct {
+	Policy       string  `yaml:"policy" env:"POLICY" env-default:"fixed"`
+	BaseMs       int     `yaml:"base_ms" env:"BASE_MS" env-default:"3000"`
+	MaxMs        int     `yaml:"max_ms" env:"MAX_MS" env-default:"60000"`
+	Multiplier   float64 `yaml:"multiplier" env:"MULTIPLIER" env-default:"2"`
+	MaxElapsedMs int     `yaml:"max_elapsed_ms" env:"MAX_ELAPSED_MS" env-default:"0"` // 0 means no cap
+}
+
+// New creates a pkgports.BackoffPolicy selected in the config
+func New(cfg Config) (pkgports.BackoffPolicy, error) {
+	base := time.Duration(cfg.BaseMs) * time.Millisecond
+	max := time.Duration(cfg.MaxMs) * time.Millisecond
+
+	var policy pkgports.BackoffPolicy
+	switch cfg.Policy {
+	case PolicyFixed:
+		policy = NewFixed(base)
+	case PolicyExponential:
+		policy = NewExponential(base, max, cfg.Multiplier)
+	case PolicyFullJitter:
+		policy = NewFullJitter(base, max, cfg.Multiplier)
+	case PolicyDecorrelatedJitter:
+		policy = NewDecorrelatedJitter(base, max)
+	default:
+		return nil, fmt.Errorf("unknown backoff policy %q", cfg.Policy)
+	}
+
+	return NewMaxElapsed(policy, time.Duration(cfg.MaxElapsedMs)*time.Millisecond), nil
+}
@@ -3,48 +3,107 @@ package lru
 import (
 	"context"
 	"errors"
-	"fmt"
 	"go.uber.org/zap"
-	"order_service/pkg/linkedlist"
+	"hash/maphash"
 	"order_service/pkg/logger"
+	"order_service/pkg/pkgports"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
-// ErrUnexpectedLinkedListBehaviour describes an error when linked list works wrong way
-var ErrUnexpectedLinkedListBehaviour = errors.New("unexpected linked list behaviour")
+// readBufferSize is the amount of reads whose promotions wait for the exclusive lock, see CacheLRUInMemory
+const readBufferSize = 256
+
+// generationStripes is the amount of generation counters of CacheLRUInMemory, keys share them by hash
+const generationStripes = 256
+
+// ErrEmptyCache describes an error when trying to get a key of a cache that has none
+var ErrEmptyCache = errors.New("cache is empty")
+
+// entry is a node of the doubly linked recency list, it stores the key to be deleted from data on eviction
+type entry[Key comparable, Value any] struct {
+	key   Key
+	value Value
+	// expiresAt is zero if the entry doesn't expire
+	expiresAt time.Time
+	prev      *entry[Key, Value]
+	next      *entry[Key, Value]
+}
+
+// expired tells whether the entry is expired at now
+func (e *entry[Key, Value]) expired(now time.Time) bool {
+	return !e.expiresAt.IsZero() && !now.Before(e.expiresAt)
+}
 
 // CacheLRUInMemory saves up to N Values and LRU algorithm and in-memory map storage
 //
 // It uses given key and value types, e.g. string and models.Order
 //
-// It uses sync.RWMutex because there are going to be many read operations from the web
-type CacheLRUInMemory[Key comparable, Value any] struct {
-	data     map[Key]Value
-	keysList linkedlist.LinkedList[Key]
-	mu       sync.RWMutex
-	cap      int
-}
-
-// There are 2 options:
-// A) store key index in keysList (in a separate map or in the data field)
-// B) don't store key index and look it up every time I GET an element (LRU moves it to the top)
+// Every key is mapped to its node in a doubly linked list ordered from the most to the least used,
+// so Get, Set and eviction are O(1): the node is found by the map and relinked without a scan
 //
-// option A:
-//   1. after every SET, when data is inserted into linked list, we have to loop through N values and update index
-//   2. after every GET, when key is moved to top, we have to loop through N-1 values and update index
-// option B: after every GET, when data is retrieved from the list, we have to loop through N values to find the index
+// Reads are concurrent: Get only takes mu.RLock and puts the read entry into a buffer.
+// Buffered promotions are applied under the exclusive lock before anything relies on the order
+// (Set, eviction, GetKeys), or by the reader that finds the buffer full
 //
-// we choose option B
+// Values might expire: Get removes an expired value it finds, the rest are removed by the StartExpiring sweep.
+// Until then they are counted by GetKeysAmount and listed by GetKeys
+//
+// Generations of pkgports.GenerationCache are striped: keys share generationStripes counters by hash,
+// so the memory doesn't grow with invalidated keys. Invalidating a key might skip a SetIfGeneration of another one,
+// it's only a missed caching
+type CacheLRUInMemory[Key comparable, Value any] struct {
+	data map[Key]*entry[Key, Value]
+	// root is the sentinel of the circular list: root.next is the most used entry, root.prev is the least used one
+	root  entry[Key, Value]
+	reads chan *entry[Key, Value]
+	mu    sync.RWMutex
+	cap   int
+
+	// generations are guarded by mu, see stripe
+	generations [generationStripes]uint64
+	seed        maphash.Seed
+
+	defaultTTL     time.Duration
+	expiryInterval time.Duration
+	done           chan struct{}
+	stopOnce       sync.Once
+
+	// counters are atomic, Get updates them under the shared lock
+	hits      atomic.Uint64
+	misses    atomic.Uint64
+	sets      atomic.Uint64
+	evictions atomic.Uint64
+	expired   atomic.Uint64
+}
 
 // NewCacheLRUInMemory creates a new CacheLRUInMemory with given capacity and key/value types
 //
 // Example: myCache := NewCacheLRUInMemory[string, myStruct](myCapacity)
 func NewCacheLRUInMemory[Key comparable, Value any](cacheCapacity int) *CacheLRUInMemory[Key, Value] {
-	return &CacheLRUInMemory[Key, Value]{
-		data:     make(map[Key]Value),
-		keysList: linkedlist.NewLinkedList[Key](),
-		cap:      cacheCapacity,
+	return NewCacheLRUInMemoryWithTTL[Key, Value](cacheCapacity, 0, 0)
+}
+
+// NewCacheLRUInMemoryWithTTL creates a new CacheLRUInMemory whose values set with Set expire after defaultTTL
+//
+// StartExpiring removes expired values every expiryInterval. defaultTTL <= 0 means values don't expire,
+// expiryInterval <= 0 turns the sweep off, expired values are removed only when they're read then
+func NewCacheLRUInMemoryWithTTL[Key comparable, Value any](
+	cacheCapacity int, defaultTTL, expiryInterval time.Duration,
+) *CacheLRUInMemory[Key, Value] {
+	c := &CacheLRUInMemory[Key, Value]{
+		data:           make(map[Key]*entry[Key, Value]),
+		reads:          make(chan *entry[Key, Value], readBufferSize),
+		cap:            cacheCapacity,
+		defaultTTL:     defaultTTL,
+		expiryInterval: expiryInterval,
+		done:           make(chan struct{}),
+		seed:           maphash.MakeSeed(),
 	}
+	c.root.next = &c.root
+	c.root.prev = &c.root
+	return c
 }
 
 // GetCapacity returns read-only value of CacheLRUInMemory capacity
@@ -54,103 +113,354 @@ func (c *CacheLRUInMemory[Key, Value]) GetCapacity() int {
 
 // Get tries to get an item by key, logs on miss
 //
-// It also moves read item to the top (if able)
+// It also moves read item to the top, lazily: the move is buffered until the exclusive lock is taken.
+// An expired item is a miss, it's removed right away
 func (c *CacheLRUInMemory[Key, Value]) Get(ctx context.Context, key Key) (Value, bool, error) {
 	c.mu.RLock()
-	defer c.mu.RUnlock()
-
-	value, ok := c.data[key]
-
+	e, ok := c.data[key]
+	var value Value
+	var expired bool
 	if ok {
-		index, err := c.keysList.GetIndex(key, func(a, b Key) bool { return a == b })
-		if err != nil {
-			if errors.Is(err, linkedlist.ErrEmptyList) {
-				return *new(Value), false, fmt.Errorf("%w: key is stored in data, but keys linked list is empty",
-					ErrUnexpectedLinkedListBehaviour)
-			}
-			// not going to occur
-			return value, false, fmt.Errorf("error getting index of read key: %w", err)
-		}
-		if index != -1 {
-			err = c.keysList.MoveToFirst(index)
-			if err != nil {
-				return *new(Value), false, fmt.Errorf("error while putting element to top: %w", err)
-			}
-		} else {
-			return *new(Value), false, fmt.Errorf("%w: key \"%v\" is stored in data, but not in non-empty linked list",
-				ErrUnexpectedLinkedListBehaviour, key)
+		value = e.value
+		expired = e.expired(time.Now())
+	}
+	c.mu.RUnlock()
+
+	if expired {
+		c.misses.Add(1)
+		c.mu.Lock()
+		// it might have been replaced or renewed by set after the read
+		if c.data[key] == e && e.expired(time.Now()) {
+			c.remove(e)
+			c.expired.Add(1)
 		}
-	} else {
+		c.mu.Unlock()
+
+		logger.GetOrCreateLoggerFromCtx(ctx).Debug(ctx, "in-memory LRU cache value expired", zap.Any("key", key))
+		return *new(Value), false, nil
+	}
+	if !ok {
+		c.misses.Add(1)
 		logger.GetOrCreateLoggerFromCtx(ctx).Debug(ctx, "in-memory LRU cache miss", zap.Any("key", key))
+		return value, false, nil
 	}
+	c.hits.Add(1)
 
-	return value, ok, nil
+	select {
+	case c.reads <- e:
+	default:
+		// the buffer is full, apply it with this read
+		c.mu.Lock()
+		c.applyReads()
+		c.promote(e)
+		c.mu.Unlock()
+	}
+	return value, true, nil
 }
 
-// Set saves the value
+// Set saves the value, it expires after the default TTL
 //
 // moves it to the top as the most frequently checked
 func (c *CacheLRUInMemory[Key, Value]) Set(ctx context.Context, key Key, value Value) error {
+	return c.SetWithTTL(ctx, key, value, c.defaultTTL)
+}
+
+// SetWithTTL saves the value as Set does, but it expires after ttl. ttl <= 0 means it doesn't expire
+func (c *CacheLRUInMemory[Key, Value]) SetWithTTL(ctx context.Context, key Key, value Value, ttl time.Duration) error {
+	var expiresAt time.Time
+	if ttl > 0 {
+		expiresAt = time.Now().Add(ttl)
+	}
+
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	index, err := c.keysList.GetIndex(key, func(a, b Key) bool { return a == b })
-	if err != nil && !errors.Is(err, linkedlist.ErrEmptyList) {
-		return fmt.Errorf("error trying to get existing entry of set key: %w", err)
+	c.set(ctx, key, value, expiresAt)
+	return nil
+}
+
+// Generation returns the current generation of the key, see pkgports.GenerationCache
+func (c *CacheLRUInMemory[Key, Value]) Generation(_ context.Context, key Key) (uint64, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.generations[c.stripe(key)], nil
+}
+
+// Invalidate removes an item by key as Delete does and bumps its generation
+func (c *CacheLRUInMemory[Key, Value]) Invalidate(_ context.Context, key Key) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.generations[c.stripe(key)]++
+	e, ok := c.data[key]
+	if !ok {
+		return false, nil
 	}
-	if index != -1 {
-		err = c.keysList.RemoveAt(index)
-		if err != nil {
-			return fmt.Errorf("error trying to remove existing entry of set key: %w", err)
-		}
+	c.remove(e)
+	return true, nil
+}
+
+// SetIfGeneration saves the value as Set does if the key is still of given generation, ok is false otherwise
+func (c *CacheLRUInMemory[Key, Value]) SetIfGeneration(
+	ctx context.Context, key Key, value Value, generation uint64,
+) (bool, error) {
+	var expiresAt time.Time
+	if c.defaultTTL > 0 {
+		expiresAt = time.Now().Add(c.defaultTTL)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.generations[c.stripe(key)] != generation {
+		return false, nil
 	}
+	c.set(ctx, key, value, expiresAt)
+	return true, nil
+}
 
-	err = c.keysList.Insert(key, 0)
-	if err != nil {
-		return fmt.Errorf("error inserting key in list: %w", err)
+// set saves the value as the most used one, the least used one is evicted if it's full. The caller holds mu exclusively
+func (c *CacheLRUInMemory[Key, Value]) set(ctx context.Context, key Key, value Value, expiresAt time.Time) {
+	c.sets.Add(1)
+
+	// reads must be applied before anything is evicted
+	c.applyReads()
+
+	if e, ok := c.data[key]; ok {
+		e.value = value
+		e.expiresAt = expiresAt
+		c.moveToFront(e)
+		return
 	}
 
-	c.data[key] = value
+	e := &entry[Key, Value]{key: key, value: value, expiresAt: expiresAt}
+	c.insertAfter(e, &c.root)
+	c.data[key] = e
 
 	// remove value if we're out of space
-	if c.keysList.Len() > c.cap {
-		var keyToDelete Key
-		keyToDelete, err = c.keysList.GetLast()
+	if len(c.data) > c.cap {
+		evicted := c.root.prev
+		c.remove(evicted)
+		c.evictions.Add(1)
 
-		if err != nil {
-			if !errors.Is(err, linkedlist.ErrEmptyList) {
-				return fmt.Errorf("error while getting last key index: %w", err)
-			}
-		} else {
-			err = c.keysList.RemoveLast()
-			if err != nil {
-				return fmt.Errorf("error while removing last key index: %w", err)
-			}
+		logger.GetOrCreateLoggerFromCtx(ctx).Debug(ctx, "cache overflow, erased a value",
+			zap.Any("key", evicted.key), zap.Int("length", len(c.data)),
+			zap.Int("capacity", c.GetCapacity()))
+	}
+}
 
-			logger.GetLoggerFromCtx(ctx).Debug(ctx, "cache overflow, erased a value",
-				zap.Any("key", keyToDelete), zap.Int("length", c.keysList.Len()),
-				zap.Int("capacity", c.GetCapacity()))
+// Peek tries to get an item by key as Get does, but it stays where it is in the list
+func (c *CacheLRUInMemory[Key, Value]) Peek(_ context.Context, key Key) (Value, bool, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
 
-			delete(c.data, keyToDelete)
-		}
+	// an expired item is left for the sweep, so Peek never takes the exclusive lock
+	e, ok := c.data[key]
+	if !ok || e.expired(time.Now()) {
+		return *new(Value), false, nil
 	}
+	return e.value, true, nil
+}
 
+// Delete removes an item by key, ok is false if there was none
+func (c *CacheLRUInMemory[Key, Value]) Delete(_ context.Context, key Key) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// buffered reads of the item are skipped by promote later
+	e, ok := c.data[key]
+	if !ok {
+		return false, nil
+	}
+	c.remove(e)
+	return true, nil
+}
+
+// Clear removes all the items, the capacity and TTLs are kept
+func (c *CacheLRUInMemory[Key, Value]) Clear(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// the buffer is emptied, its entries are dropped below anyway
+	c.applyReads()
+	removed := len(c.data)
+	c.data = make(map[Key]*entry[Key, Value])
+	c.root.next = &c.root
+	c.root.prev = &c.root
+
+	logger.GetOrCreateLoggerFromCtx(ctx).Debug(ctx, "cleared in-memory LRU cache", zap.Int("removed", removed))
 	return nil
 }
 
+// GetKeysAmount returns the amount of saved keys
 func (c *CacheLRUInMemory[_, _]) GetKeysAmount() int {
-	return c.keysList.Len()
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return len(c.data)
 }
 
 // GetKeys returns them in order from the Most to the least used
 func (c *CacheLRUInMemory[Key, _]) GetKeys() []Key {
-	return c.keysList.GetAll()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.applyReads()
+
+	keys := make([]Key, 0, len(c.data))
+	for e := c.root.next; e != &c.root; e = e.next {
+		keys = append(keys, e.key)
+	}
+	return keys
+}
+
+// Stats returns the counters of the cache, Peek isn't counted as a hit or a miss
+func (c *CacheLRUInMemory[Key, Value]) Stats() pkgports.CacheStats {
+	return pkgports.CacheStats{
+		Hits:      c.hits.Load(),
+		Misses:    c.misses.Load(),
+		Sets:      c.sets.Load(),
+		Evictions: c.evictions.Load(),
+		Expired:   c.expired.Load(),
+		Size:      c.GetKeysAmount(),
+		Capacity:  c.cap,
+	}
 }
 
+// MostUsedKey returns the key that was set or read last, ErrEmptyCache if there are none
 func (c *CacheLRUInMemory[Key, _]) MostUsedKey() (Key, error) {
-	return c.keysList.GetFirst()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.applyReads()
+
+	if c.root.next == &c.root {
+		return *new(Key), ErrEmptyCache
+	}
+	return c.root.next.key, nil
 }
 
+// LeastUsedKey returns the key that is evicted next, ErrEmptyCache if there are none
 func (c *CacheLRUInMemory[Key, _]) LeastUsedKey() (Key, error) {
-	return c.keysList.GetLast()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.applyReads()
+
+	if c.root.prev == &c.root {
+		return *new(Key), ErrEmptyCache
+	}
+	return c.root.prev.key, nil
+}
+
+// StartExpiring is the loop that removes expired values every expiryInterval, it's meant to be run in background
+//
+// returns right away if the sweep is off
+func (c *CacheLRUInMemory[Key, Value]) StartExpiring(ctx context.Context) error {
+	if c.expiryInterval <= 0 {
+		return nil
+	}
+
+	ticker := time.NewTicker(c.expiryInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-c.done:
+			return nil
+		case <-ticker.C:
+			if removed := c.removeExpired(); removed > 0 {
+				logger.GetOrCreateLoggerFromCtx(ctx).Debug(ctx, "removed expired values from in-memory LRU cache",
+					zap.Int("removed", removed))
+			}
+		}
+	}
+}
+
+// StopExpiring stops the StartExpiring loop
+//
+// unlike the services it doesn't wait for the loop, so it's fine to call it when the loop isn't running
+func (c *CacheLRUInMemory[Key, Value]) StopExpiring(_ context.Context) {
+	c.stopOnce.Do(func() {
+		close(c.done)
+	})
+}
+
+// removeExpired removes every expired value and returns their amount
+//
+// It's a full scan under the exclusive lock, so it shouldn't run too often on big caches
+func (c *CacheLRUInMemory[Key, Value]) removeExpired() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.applyReads()
+
+	now := time.Now()
+	removed := 0
+	for e := c.root.next; e != &c.root; {
+		next := e.next
+		if e.expired(now) {
+			c.remove(e)
+			removed++
+		}
+		e = next
+	}
+	c.expired.Add(uint64(removed))
+	return removed
+}
+
+// stripe returns the index of the generation counter of the key
+func (c *CacheLRUInMemory[Key, _]) stripe(key Key) int {
+	return int(maphash.Comparable(c.seed, key) % generationStripes)
+}
+
+// remove deletes e from both the list and data, the caller holds mu exclusively
+func (c *CacheLRUInMemory[Key, Value]) remove(e *entry[Key, Value]) {
+	c.unlink(e)
+	delete(c.data, e.key)
+}
+
+// insertAfter links e right after at, the caller holds mu
+func (c *CacheLRUInMemory[Key, Value]) insertAfter(e, at *entry[Key, Value]) {
+	e.prev = at
+	e.next = at.next
+	at.next.prev = e
+	at.next = e
+}
+
+// unlink removes e from the list, the caller holds mu
+func (c *CacheLRUInMemory[Key, Value]) unlink(e *entry[Key, Value]) {
+	e.prev.next = e.next
+	e.next.prev = e.prev
+	e.prev = nil
+	e.next = nil
+}
+
+// applyReads promotes the buffered reads in order, the caller holds mu exclusively
+func (c *CacheLRUInMemory[Key, Value]) applyReads() {
+	for {
+		select {
+		case e := <-c.reads:
+			c.promote(e)
+		default:
+			return
+		}
+	}
+}
+
+// promote moves a read entry to the front if it's still stored, the caller holds mu exclusively
+func (c *CacheLRUInMemory[Key, Value]) promote(e *entry[Key, Value]) {
+	// it might have been evicted or replaced after the read
+	if c.data[e.key] != e {
+		return
+	}
+	c.moveToFront(e)
+}
+
+// moveToFront makes e the most used entry, the caller holds mu
+func (c *CacheLRUInMemory[Key, Value]) moveToFront(e *entry[Key, Value]) {
+	if c.root.next == e {
+		return
+	}
+	c.unlink(e)
+	c.insertAfter(e, &c.root)
 }
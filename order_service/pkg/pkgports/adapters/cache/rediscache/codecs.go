@@ -0,0 +1,52 @@
+package rediscache
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Codec serializes the values of CacheRedis, e.g. JSONCodec
+type Codec[Value any] interface {
+	Marshal(value Value) ([]byte, error)
+	Unmarshal(data []byte) (Value, error)
+}
+
+// KeyCodec formats the keys of CacheRedis into redis keys (without the prefix) and parses them back for GetKeys
+type KeyCodec[Key comparable] interface {
+	Format(key Key) string
+	Parse(s string) (Key, error)
+}
+
+// JSONCodec is a Codec that uses encoding/json, so Value must be JSON friendly
+type JSONCodec[Value any] struct{}
+
+// Marshal encodes value into JSON
+func (JSONCodec[Value]) Marshal(value Value) ([]byte, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't marshal value into json: %w", err)
+	}
+	return data, nil
+}
+
+// Unmarshal decodes a JSON value
+func (JSONCodec[Value]) Unmarshal(data []byte) (Value, error) {
+	var value Value
+	if err := json.Unmarshal(data, &value); err != nil {
+		return value, fmt.Errorf("couldn't unmarshal value from json: %w", err)
+	}
+	return value, nil
+}
+
+// StringKeyCodec is a KeyCodec of string keys, they're used as is
+type StringKeyCodec struct{}
+
+// Format returns the key as is
+func (StringKeyCodec) Format(key string) string {
+	return key
+}
+
+// Parse returns the key as is, it never fails
+func (StringKeyCodec) Parse(s string) (string, error) {
+	return s, nil
+}
@@ -0,0 +1,116 @@
+package rediscache
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	goredis "github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+	"order_service/pkg/logger"
+)
+
+// invalidationMessage is the JSON published by Invalidations, Key is empty if Clear is true
+type invalidationMessage struct {
+	From  string `json:"from"`
+	Key   string `json:"key,omitempty"`
+	Clear bool   `json:"clear,omitempty"`
+}
+
+// Invalidations broadcasts changed keys of a cache with redis pub/sub on a channel, keys are formatted with KeyCodec
+//
+// Every Invalidations has a random ID that's sent with its messages, so it skips its own ones.
+// A subscription is restored by go-redis after a disconnect, messages published meanwhile are lost
+type Invalidations[Key comparable] struct {
+	client  goredis.UniversalClient
+	keys    KeyCodec[Key]
+	channel string
+	id      string
+}
+
+// NewInvalidations creates a new Invalidations with given client, key codec and pub/sub channel
+//
+// Example: myInvalidations := NewInvalidations[string](client, StringKeyCodec{}, "my:invalidations")
+func NewInvalidations[Key comparable](
+	client goredis.UniversalClient, keys KeyCodec[Key], channel string,
+) *Invalidations[Key] {
+	return &Invalidations[Key]{
+		client:  client,
+		keys:    keys,
+		channel: channel,
+		id:      rand.Text(),
+	}
+}
+
+// Publish tells the other subscribers of the channel to drop the key
+func (i *Invalidations[Key]) Publish(ctx context.Context, key Key) error {
+	return i.publish(ctx, invalidationMessage{From: i.id, Key: i.keys.Format(key)})
+}
+
+// PublishClear tells the other subscribers of the channel to drop every key
+func (i *Invalidations[Key]) PublishClear(ctx context.Context) error {
+	return i.publish(ctx, invalidationMessage{From: i.id, Clear: true})
+}
+
+// publish sends the message to the channel
+func (i *Invalidations[Key]) publish(ctx context.Context, message invalidationMessage) error {
+	data, err := json.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("couldn't marshal invalidation message: %w", err)
+	}
+	if err = i.client.Publish(ctx, i.channel, data).Err(); err != nil {
+		return fmt.Errorf("couldn't publish to %s: %w", i.channel, err)
+	}
+	return nil
+}
+
+// Subscribe calls onKey and onClear with the messages of the other publishers until ctx is done
+//
+// It returns an error only if the first subscription fails, messages that can't be decoded are logged and skipped
+func (i *Invalidations[Key]) Subscribe(
+	ctx context.Context, onKey func(ctx context.Context, key Key), onClear func(ctx context.Context),
+) error {
+	pubsub := i.client.Subscribe(ctx, i.channel)
+	defer func() {
+		_ = pubsub.Close()
+	}()
+
+	// wait for the confirmation, so a broken connection is an error right away
+	if _, err := pubsub.Receive(ctx); err != nil {
+		return fmt.Errorf("couldn't subscribe to %s: %w", i.channel, err)
+	}
+
+	messages := pubsub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case received, ok := <-messages:
+			if !ok {
+				return nil
+			}
+
+			var message invalidationMessage
+			if err := json.Unmarshal([]byte(received.Payload), &message); err != nil {
+				logger.GetOrCreateLoggerFromCtx(ctx).Warn(ctx, "undecodable invalidation message, skipping it",
+					zap.String("channel", i.channel), zap.Error(err))
+				continue
+			}
+			if message.From == i.id {
+				continue
+			}
+			if message.Clear {
+				onClear(ctx)
+				continue
+			}
+
+			key, err := i.keys.Parse(message.Key)
+			if err != nil {
+				logger.GetOrCreateLoggerFromCtx(ctx).Warn(ctx, "unparsable key in invalidation message, skipping it",
+					zap.String("channel", i.channel), zap.String("key", message.Key), zap.Error(err))
+				continue
+			}
+			onKey(ctx, key)
+		}
+	}
+}
@@ -0,0 +1,348 @@
+package rediscache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	goredis "github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+	"order_service/pkg/logger"
+	"order_service/pkg/pkgports"
+	"slices"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// scanBatchSize is the COUNT hint of SCAN and the amount of keys deleted at once by Clear
+const scanBatchSize = 100
+
+// generationKeyPrefix is the prefix of generation keys, see CacheRedis
+const generationKeyPrefix = "generation:"
+
+// generationTTL is how long a generation is kept after it's bumped, it must be longer than any load of a value
+const generationTTL = 10 * time.Minute
+
+// invalidateScript bumps the generation of KEYS[1] value, that's KEYS[2], and deletes the value.
+// ARGV[1] is the TTL of the generation in ms
+var invalidateScript = goredis.NewScript(`
+redis.call('INCR', KEYS[2])
+redis.call('PEXPIRE', KEYS[2], ARGV[1])
+return redis.call('DEL', KEYS[1])
+`)
+
+// setIfGenerationScript sets KEYS[1] to ARGV[2] if its generation KEYS[2] is still ARGV[1] (a missing one is 0),
+// ARGV[3] is the TTL of the value in ms, 0 means it doesn't expire
+var setIfGenerationScript = goredis.NewScript(`
+if (redis.call('GET', KEYS[2]) or '0') ~= ARGV[1] then
+  return 0
+end
+if tonumber(ARGV[3]) > 0 then
+  redis.call('SET', KEYS[1], ARGV[2], 'PX', ARGV[3])
+else
+  redis.call('SET', KEYS[1], ARGV[2])
+end
+return 1
+`)
+
+// CacheRedis saves values in redis under keys with a common prefix, it's shared by every instance of a service
+//
+// It uses given key and value types, e.g. string and models.Order: keys are formatted with KeyCodec,
+// values are serialized with Codec
+//
+// There's no capacity: old values are removed by their TTL or by the redis maxmemory-policy.
+// GetKeys, GetKeysAmount and Clear SCAN all the keys with the prefix, they're meant for admin use only
+//
+// Generations of pkgports.GenerationCache are saved under generationKeyPrefix with the value key as a hash tag,
+// so both keys are in the same slot of a redis cluster and the scripts can use them together.
+// A generation expires generationTTL after it's bumped: a load that's longer than that might cache a stale value
+type CacheRedis[Key comparable, Value any] struct {
+	client     goredis.UniversalClient
+	keys       KeyCodec[Key]
+	values     Codec[Value]
+	prefix     string
+	defaultTTL time.Duration
+
+	// counters are of this instance only, other instances using the same prefix have their own
+	hits   atomic.Uint64
+	misses atomic.Uint64
+	sets   atomic.Uint64
+}
+
+// NewCacheRedis creates a new CacheRedis with given client, key/value codecs, key prefix and the TTL of Set
+//
+// defaultTTL <= 0 means values set with Set don't expire
+//
+// Example: myCache := NewCacheRedis[string, myStruct](client, StringKeyCodec{}, JSONCodec[myStruct]{}, "my:", time.Minute)
+func NewCacheRedis[Key comparable, Value any](
+	client goredis.UniversalClient, keys KeyCodec[Key], values Codec[Value], prefix string, defaultTTL time.Duration,
+) *CacheRedis[Key, Value] {
+	return &CacheRedis[Key, Value]{
+		client:     client,
+		keys:       keys,
+		values:     values,
+		prefix:     prefix,
+		defaultTTL: defaultTTL,
+	}
+}
+
+// Get tries to get an item by key, logs on miss
+//
+// A value that can't be decoded (e.g. saved by an older version with another codec) is a miss, it's deleted
+func (c *CacheRedis[Key, Value]) Get(ctx context.Context, key Key) (Value, bool, error) {
+	value, found, err := c.get(ctx, key)
+	if err != nil {
+		return value, false, err
+	}
+	if !found {
+		c.misses.Add(1)
+		logger.GetOrCreateLoggerFromCtx(ctx).Debug(ctx, "redis cache miss", zap.Any("key", key))
+		return value, false, nil
+	}
+	c.hits.Add(1)
+	return value, true, nil
+}
+
+// Peek tries to get an item by key as Get does, but it isn't counted in Stats
+//
+// redis itself still counts it as an access for its own maxmemory-policy
+func (c *CacheRedis[Key, Value]) Peek(ctx context.Context, key Key) (Value, bool, error) {
+	return c.get(ctx, key)
+}
+
+// get reads and decodes an item by key, undecodable ones are deleted
+func (c *CacheRedis[Key, Value]) get(ctx context.Context, key Key) (Value, bool, error) {
+	redisKey := c.redisKey(key)
+
+	data, err := c.client.Get(ctx, redisKey).Bytes()
+	if errors.Is(err, goredis.Nil) {
+		return *new(Value), false, nil
+	}
+	if err != nil {
+		return *new(Value), false, fmt.Errorf("couldn't get %s from redis: %w", redisKey, err)
+	}
+
+	value, err := c.values.Unmarshal(data)
+	if err != nil {
+		logger.GetOrCreateLoggerFromCtx(ctx).Warn(ctx, "undecodable value in redis cache, deleting it",
+			zap.String("key", redisKey), zap.Error(err))
+		if delErr := c.client.Del(ctx, redisKey).Err(); delErr != nil {
+			return *new(Value), false, fmt.Errorf("couldn't delete undecodable %s from redis: %w", redisKey, delErr)
+		}
+		return *new(Value), false, nil
+	}
+	return value, true, nil
+}
+
+// Set saves the value, it expires after the default TTL
+func (c *CacheRedis[Key, Value]) Set(ctx context.Context, key Key, value Value) error {
+	return c.SetWithTTL(ctx, key, value, c.defaultTTL)
+}
+
+// SetWithTTL saves the value as Set does, but it expires after ttl. ttl <= 0 means it doesn't expire
+func (c *CacheRedis[Key, Value]) SetWithTTL(ctx context.Context, key Key, value Value, ttl time.Duration) error {
+	redisKey := c.redisKey(key)
+
+	data, err := c.values.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("couldn't encode value of %s: %w", redisKey, err)
+	}
+
+	// 0 is "no expiration" for go-redis, negative values mean KEEPTTL
+	err = c.client.Set(ctx, redisKey, data, max(ttl, 0)).Err()
+	if err != nil {
+		return fmt.Errorf("couldn't set %s in redis: %w", redisKey, err)
+	}
+	c.sets.Add(1)
+	return nil
+}
+
+// Generation returns the current generation of the key, 0 if it has never been invalidated or it has expired
+func (c *CacheRedis[Key, Value]) Generation(ctx context.Context, key Key) (uint64, error) {
+	generationKey := c.generationKey(key)
+
+	generation, err := c.client.Get(ctx, generationKey).Uint64()
+	if errors.Is(err, goredis.Nil) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("couldn't get %s from redis: %w", generationKey, err)
+	}
+	return generation, nil
+}
+
+// Invalidate removes an item by key as Delete does and bumps its generation, both at once
+func (c *CacheRedis[Key, Value]) Invalidate(ctx context.Context, key Key) (bool, error) {
+	redisKey := c.redisKey(key)
+
+	deleted, err := invalidateScript.Run(ctx, c.client,
+		[]string{redisKey, c.generationKey(key)}, generationTTL.Milliseconds()).Int64()
+	if err != nil {
+		return false, fmt.Errorf("couldn't invalidate %s in redis: %w", redisKey, err)
+	}
+	return deleted > 0, nil
+}
+
+// SetIfGeneration saves the value as Set does if the key is still of given generation, ok is false otherwise
+func (c *CacheRedis[Key, Value]) SetIfGeneration(
+	ctx context.Context, key Key, value Value, generation uint64,
+) (bool, error) {
+	redisKey := c.redisKey(key)
+
+	data, err := c.values.Marshal(value)
+	if err != nil {
+		return false, fmt.Errorf("couldn't encode value of %s: %w", redisKey, err)
+	}
+
+	set, err := setIfGenerationScript.Run(ctx, c.client, []string{redisKey, c.generationKey(key)},
+		strconv.FormatUint(generation, 10), data, max(c.defaultTTL, 0).Milliseconds()).Int64()
+	if err != nil {
+		return false, fmt.Errorf("couldn't set %s in redis: %w", redisKey, err)
+	}
+	if set == 0 {
+		return false, nil
+	}
+	c.sets.Add(1)
+	return true, nil
+}
+
+// Delete removes an item by key, ok is false if there was none
+func (c *CacheRedis[Key, Value]) Delete(ctx context.Context, key Key) (bool, error) {
+	redisKey := c.redisKey(key)
+
+	deleted, err := c.client.Del(ctx, redisKey).Result()
+	if err != nil {
+		return false, fmt.Errorf("couldn't delete %s from redis: %w", redisKey, err)
+	}
+	return deleted > 0, nil
+}
+
+// Clear removes all the items with the prefix, other keys of the redis database are left as is
+//
+// The keys are collected first: deleting them while scanning might make SCAN skip some
+func (c *CacheRedis[Key, Value]) Clear(ctx context.Context) error {
+	var redisKeys []string
+	err := c.scan(ctx, func(batch []string) error {
+		redisKeys = append(redisKeys, batch...)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	var removed int64
+	for batch := range slices.Chunk(redisKeys, scanBatchSize) {
+		deleted, err := c.client.Del(ctx, batch...).Result()
+		if err != nil {
+			return fmt.Errorf("couldn't delete keys from redis: %w", err)
+		}
+		removed += deleted
+	}
+
+	logger.GetOrCreateLoggerFromCtx(ctx).Debug(ctx, "cleared redis cache",
+		zap.String("prefix", c.prefix), zap.Int64("removed", removed))
+	return nil
+}
+
+// GetKeys returns the saved keys in no particular order, keys that can't be parsed are skipped
+//
+// The port has no ctx and no error here, so redis errors are logged and the keys read so far are returned
+func (c *CacheRedis[Key, _]) GetKeys() []Key {
+	ctx := context.Background()
+
+	var keys []Key
+	err := c.scan(ctx, func(redisKeys []string) error {
+		for _, redisKey := range redisKeys {
+			key, err := c.keys.Parse(strings.TrimPrefix(redisKey, c.prefix))
+			if err != nil {
+				continue
+			}
+			keys = append(keys, key)
+		}
+		return nil
+	})
+	if err != nil {
+		logger.GetOrCreateLoggerFromCtx(ctx).Error(ctx, "error listing redis cache keys",
+			zap.String("prefix", c.prefix), zap.Error(err))
+	}
+	return keys
+}
+
+// GetKeysAmount returns the amount of saved keys, redis errors are logged as in GetKeys
+func (c *CacheRedis[_, _]) GetKeysAmount() int {
+	ctx := context.Background()
+
+	amount := 0
+	err := c.scan(ctx, func(redisKeys []string) error {
+		amount += len(redisKeys)
+		return nil
+	})
+	if err != nil {
+		logger.GetOrCreateLoggerFromCtx(ctx).Error(ctx, "error counting redis cache keys",
+			zap.String("prefix", c.prefix), zap.Error(err))
+	}
+	return amount
+}
+
+// Stats returns the counters of this instance
+//
+// Evictions and expiries are made by redis, they aren't counted. Size isn't counted either: it would SCAN every key
+// on every call, see GetKeysAmount
+func (c *CacheRedis[_, _]) Stats() pkgports.CacheStats {
+	return pkgports.CacheStats{
+		Hits:   c.hits.Load(),
+		Misses: c.misses.Load(),
+		Sets:   c.sets.Load(),
+	}
+}
+
+// redisKey returns the key with the prefix as it's saved in redis
+func (c *CacheRedis[Key, _]) redisKey(key Key) string {
+	return c.prefix + c.keys.Format(key)
+}
+
+// generationKey returns the key of the generation of the key as it's saved in redis
+func (c *CacheRedis[Key, _]) generationKey(key Key) string {
+	return generationKeyPrefix + "{" + c.redisKey(key) + "}"
+}
+
+// scan calls handle with every batch of keys with the prefix until the SCAN cursor is back to 0
+//
+// Generation keys are skipped, they match an empty prefix too
+func (c *CacheRedis[_, _]) scan(ctx context.Context, handle func(redisKeys []string) error) error {
+	match := escapeGlob(c.prefix) + "*"
+
+	var cursor uint64
+	for {
+		redisKeys, next, err := c.client.Scan(ctx, cursor, match, scanBatchSize).Result()
+		if err != nil {
+			return fmt.Errorf("couldn't scan redis keys: %w", err)
+		}
+		redisKeys = slices.DeleteFunc(redisKeys, func(redisKey string) bool {
+			return strings.HasPrefix(redisKey, generationKeyPrefix+"{")
+		})
+		if len(redisKeys) > 0 {
+			if err = handle(redisKeys); err != nil {
+				return err
+			}
+		}
+		if next == 0 {
+			return nil
+		}
+		cursor = next
+	}
+}
+
+// escapeGlob escapes the special characters of a SCAN MATCH pattern, so the prefix is matched as is
+func escapeGlob(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch r {
+		case '*', '?', '[', ']', '\\':
+			b.WriteRune('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
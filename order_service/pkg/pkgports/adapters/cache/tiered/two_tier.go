@@ -0,0 +1,389 @@
+package tiered
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go.uber.org/zap"
+	"hash/maphash"
+	"order_service/pkg/logger"
+	"order_service/pkg/pkgports"
+	"sync"
+	"time"
+)
+
+// l1GenerationStripes is the amount of L1 generation counters of CacheTwoTier, keys share them by hash
+const l1GenerationStripes = 256
+
+// ErrNoRecency is returned by MostUsedKey and LeastUsedKey if L1 doesn't know the order of its values
+var ErrNoRecency = errors.New("L1 cache isn't a recency cache")
+
+// CacheTwoTier is a cache of two caches: a small fast L1 (e.g. in-memory LRU) in front of a big shared L2 (e.g. redis)
+//
+// It uses given key and value types, e.g. string and models.Order
+//
+// Reads are read-through: an L1 miss is read from L2, a value found there is promoted into L1.
+// Writes are write-through: L2 is written first, then L1 unless the key is invalidated meanwhile. Capacities and TTLs of the tiers are their own,
+// L1 values live no longer than l1TTL
+//
+// Changes are published with invalidations, so other CacheTwoTier with the same L2 (e.g. other instances of a service)
+// drop their L1 values, see StartInvalidations. A lost message or no invalidations at all (nil) mean
+// they might read a stale value for l1TTL
+//
+// Generations of pkgports.GenerationCache are of L2. Values read from L2 are written into L1 only if there was no
+// invalidation of their key since the read: L1 has its own striped generations for that, they're guarded by l1Mu
+type CacheTwoTier[Key comparable, Value any] struct {
+	l1            pkgports.Cache[Key, Value]
+	l2            pkgports.GenerationCache[Key, Value]
+	invalidations pkgports.CacheInvalidations[Key]
+	l1TTL         time.Duration
+
+	l1Mu          sync.Mutex
+	l1Generations [l1GenerationStripes]uint64
+	seed          maphash.Seed
+}
+
+// NewCacheTwoTier creates a new CacheTwoTier of given tiers, values are kept in l1 for l1TTL at most
+//
+// l1TTL <= 0 means L1 values don't expire, they're only evicted then. invalidations might be nil
+// if there are no other instances
+//
+// Example: myCache := NewCacheTwoTier[string, myStruct](myInMemoryCache, myRedisCache, myInvalidations, 10*time.Second)
+func NewCacheTwoTier[Key comparable, Value any](
+	l1 pkgports.Cache[Key, Value], l2 pkgports.GenerationCache[Key, Value],
+	invalidations pkgports.CacheInvalidations[Key], l1TTL time.Duration,
+) *CacheTwoTier[Key, Value] {
+	return &CacheTwoTier[Key, Value]{
+		l1:            l1,
+		l2:            l2,
+		invalidations: invalidations,
+		l1TTL:         l1TTL,
+		seed:          maphash.MakeSeed(),
+	}
+}
+
+// Get tries to get an item from L1, then L2. An item found in L2 is promoted into L1
+//
+// L1 errors are logged and L2 is read instead, L1 is only a shortcut
+func (c *CacheTwoTier[Key, Value]) Get(ctx context.Context, key Key) (Value, bool, error) {
+	// step 1. the closest tier
+	value, found, err := c.l1.Get(ctx, key)
+	if err != nil {
+		logger.GetOrCreateLoggerFromCtx(ctx).Warn(ctx, "error reading L1 cache, reading L2",
+			zap.Any("key", key), zap.Error(err))
+	}
+	if err == nil && found {
+		return value, true, nil
+	}
+
+	// step 2. the shared tier
+	//   the L1 generation is taken before the read, see promote
+	l1Generation := c.l1Generation(key)
+	value, found, err = c.l2.Get(ctx, key)
+	if err != nil {
+		return value, false, fmt.Errorf("error reading L2 cache: %w", err)
+	}
+	if !found {
+		return value, false, nil
+	}
+
+	// step 3. promote, so the next read is served by L1
+	if err = c.promote(ctx, key, value, l1Generation, c.l1TTL); err != nil {
+		logger.GetOrCreateLoggerFromCtx(ctx).Warn(ctx, "error promoting value into L1 cache",
+			zap.Any("key", key), zap.Error(err))
+	}
+	return value, true, nil
+}
+
+// Peek tries to get an item from L1, then L2, as Get does, but nothing is promoted or counted as used
+func (c *CacheTwoTier[Key, Value]) Peek(ctx context.Context, key Key) (Value, bool, error) {
+	value, found, err := c.l1.Peek(ctx, key)
+	if err == nil && found {
+		return value, true, nil
+	}
+
+	value, found, err = c.l2.Peek(ctx, key)
+	if err != nil {
+		return value, false, fmt.Errorf("error reading L2 cache: %w", err)
+	}
+	return value, found, nil
+}
+
+// Set saves the value in both tiers, it expires after the default TTL of L2 and after l1TTL in L1
+func (c *CacheTwoTier[Key, Value]) Set(ctx context.Context, key Key, value Value) error {
+	return c.write(ctx, key, value, func() error {
+		return c.l2.Set(ctx, key, value)
+	}, c.l1TTL)
+}
+
+// SetWithTTL saves the value as Set does, but it expires after ttl in L2 and no later than that in L1.
+// ttl <= 0 means it doesn't expire in L2
+func (c *CacheTwoTier[Key, Value]) SetWithTTL(ctx context.Context, key Key, value Value, ttl time.Duration) error {
+	l1TTL := c.l1TTL
+	if ttl > 0 && (l1TTL <= 0 || ttl < l1TTL) {
+		l1TTL = ttl
+	}
+	return c.write(ctx, key, value, func() error {
+		return c.l2.SetWithTTL(ctx, key, value, ttl)
+	}, l1TTL)
+}
+
+// write saves the value in L2 with setL2, then in L1 for l1TTL if it isn't invalidated meanwhile,
+// the other instances drop their L1 values
+//
+// If L2 fails, the value is deleted from L1: it mustn't keep a value L2 doesn't have
+func (c *CacheTwoTier[Key, Value]) write(ctx context.Context, key Key, value Value, setL2 func() error, l1TTL time.Duration) error {
+	// the L1 generation is taken before the write, see promote
+	l1Generation := c.l1Generation(key)
+	if err := setL2(); err != nil {
+		if _, l1Err := c.invalidateL1(ctx, key); l1Err != nil {
+			logger.GetOrCreateLoggerFromCtx(ctx).Warn(ctx, "error deleting value from L1 cache",
+				zap.Any("key", key), zap.Error(l1Err))
+		}
+		return fmt.Errorf("error writing L2 cache: %w", err)
+	}
+	c.publish(ctx, key)
+
+	if err := c.promote(ctx, key, value, l1Generation, l1TTL); err != nil {
+		return fmt.Errorf("error writing L1 cache: %w", err)
+	}
+	return nil
+}
+
+// Generation returns the generation of the key in L2, see pkgports.GenerationCache
+func (c *CacheTwoTier[Key, Value]) Generation(ctx context.Context, key Key) (uint64, error) {
+	generation, err := c.l2.Generation(ctx, key)
+	if err != nil {
+		return 0, fmt.Errorf("error reading L2 cache generation: %w", err)
+	}
+	return generation, nil
+}
+
+// SetIfGeneration saves the value in L2 if the key is still of given generation there, then in L1 for l1TTL
+// if it isn't invalidated meanwhile. ok is false if L2 is skipped
+//
+// If L2 fails, the value is deleted from L1 as in Set. The other instances aren't told: the value is a read one,
+// it's not a change
+func (c *CacheTwoTier[Key, Value]) SetIfGeneration(
+	ctx context.Context, key Key, value Value, generation uint64,
+) (bool, error) {
+	l1Generation := c.l1Generation(key)
+
+	set, err := c.l2.SetIfGeneration(ctx, key, value, generation)
+	if err != nil {
+		if _, l1Err := c.invalidateL1(ctx, key); l1Err != nil {
+			logger.GetOrCreateLoggerFromCtx(ctx).Warn(ctx, "error deleting value from L1 cache",
+				zap.Any("key", key), zap.Error(l1Err))
+		}
+		return false, fmt.Errorf("error writing L2 cache: %w", err)
+	}
+	if !set {
+		return false, nil
+	}
+
+	if err = c.promote(ctx, key, value, l1Generation, c.l1TTL); err != nil {
+		return true, fmt.Errorf("error writing L1 cache: %w", err)
+	}
+	return true, nil
+}
+
+// Invalidate removes an item from both tiers as Delete does, bumping the generation of the key in both
+func (c *CacheTwoTier[Key, Value]) Invalidate(ctx context.Context, key Key) (bool, error) {
+	// L2 goes first: a value read from L2 before its invalidation is either promoted before the L1 one, so it's
+	// deleted, or after it, so it's skipped
+	deletedL2, l2Err := c.l2.Invalidate(ctx, key)
+	if l2Err != nil {
+		l2Err = fmt.Errorf("error invalidating L2 cache: %w", l2Err)
+	}
+	deletedL1, l1Err := c.invalidateL1(ctx, key)
+	if l1Err != nil {
+		l1Err = fmt.Errorf("error deleting from L1 cache: %w", l1Err)
+	}
+	c.publish(ctx, key)
+
+	return deletedL1 || deletedL2, errors.Join(l2Err, l1Err)
+}
+
+// Delete removes an item from both tiers, ok is true if any of them had it
+//
+// Both tiers are always tried, the errors of both are returned
+func (c *CacheTwoTier[Key, Value]) Delete(ctx context.Context, key Key) (bool, error) {
+	deletedL2, l2Err := c.l2.Delete(ctx, key)
+	if l2Err != nil {
+		l2Err = fmt.Errorf("error deleting from L2 cache: %w", l2Err)
+	}
+	deletedL1, l1Err := c.invalidateL1(ctx, key)
+	if l1Err != nil {
+		l1Err = fmt.Errorf("error deleting from L1 cache: %w", l1Err)
+	}
+	c.publish(ctx, key)
+
+	return deletedL1 || deletedL2, errors.Join(l2Err, l1Err)
+}
+
+// Clear removes all the items from both tiers, the other instances clear their L1
+//
+// Both tiers are always tried, the errors of both are returned
+func (c *CacheTwoTier[Key, Value]) Clear(ctx context.Context) error {
+	l2Err := c.l2.Clear(ctx)
+	if l2Err != nil {
+		l2Err = fmt.Errorf("error clearing L2 cache: %w", l2Err)
+	}
+	l1Err := c.clearL1(ctx)
+	if l1Err != nil {
+		l1Err = fmt.Errorf("error clearing L1 cache: %w", l1Err)
+	}
+
+	if c.invalidations != nil {
+		if err := c.invalidations.PublishClear(ctx); err != nil {
+			logger.GetOrCreateLoggerFromCtx(ctx).Warn(ctx, "error publishing cache clear, other L1 caches are stale",
+				zap.Error(err))
+		}
+	}
+	return errors.Join(l2Err, l1Err)
+}
+
+// StartInvalidations is the loop that drops L1 values changed by the other instances, it's run in background
+//
+// returns right away if there are no invalidations, it's stopped with ctx
+func (c *CacheTwoTier[Key, Value]) StartInvalidations(ctx context.Context) error {
+	if c.invalidations == nil {
+		return nil
+	}
+
+	return c.invalidations.Subscribe(ctx,
+		func(ctx context.Context, key Key) {
+			if _, err := c.invalidateL1(ctx, key); err != nil {
+				logger.GetOrCreateLoggerFromCtx(ctx).Warn(ctx, "error deleting invalidated value from L1 cache",
+					zap.Any("key", key), zap.Error(err))
+			}
+		},
+		func(ctx context.Context) {
+			if err := c.clearL1(ctx); err != nil {
+				logger.GetOrCreateLoggerFromCtx(ctx).Warn(ctx, "error clearing L1 cache", zap.Error(err))
+			}
+		},
+	)
+}
+
+// publish tells the other instances to drop the key from their L1, errors are only logged: L1 values expire anyway
+func (c *CacheTwoTier[Key, _]) publish(ctx context.Context, key Key) {
+	if c.invalidations == nil {
+		return
+	}
+	if err := c.invalidations.Publish(ctx, key); err != nil {
+		logger.GetOrCreateLoggerFromCtx(ctx).Warn(ctx, "error publishing cache invalidation, other L1 caches are stale",
+			zap.Any("key", key), zap.Error(err))
+	}
+}
+
+// l1Generation returns the L1 generation of the key, it's taken before a value is read from or written to L2
+func (c *CacheTwoTier[Key, _]) l1Generation(key Key) uint64 {
+	c.l1Mu.Lock()
+	defer c.l1Mu.Unlock()
+
+	return c.l1Generations[c.stripe(key)]
+}
+
+// promote writes a value of L2 into L1 for ttl if the key isn't invalidated since l1Generation was taken
+func (c *CacheTwoTier[Key, Value]) promote(
+	ctx context.Context, key Key, value Value, l1Generation uint64, ttl time.Duration,
+) error {
+	c.l1Mu.Lock()
+	defer c.l1Mu.Unlock()
+
+	if c.l1Generations[c.stripe(key)] != l1Generation {
+		return nil
+	}
+	return c.l1.SetWithTTL(ctx, key, value, ttl)
+}
+
+// invalidateL1 deletes an item from L1 and bumps its L1 generation, so values read from L2 before aren't promoted
+func (c *CacheTwoTier[Key, _]) invalidateL1(ctx context.Context, key Key) (bool, error) {
+	c.l1Mu.Lock()
+	defer c.l1Mu.Unlock()
+
+	c.l1Generations[c.stripe(key)]++
+	return c.l1.Delete(ctx, key)
+}
+
+// clearL1 removes all the items from L1 and bumps every L1 generation
+func (c *CacheTwoTier[_, _]) clearL1(ctx context.Context) error {
+	c.l1Mu.Lock()
+	defer c.l1Mu.Unlock()
+
+	for i := range c.l1Generations {
+		c.l1Generations[i]++
+	}
+	return c.l1.Clear(ctx)
+}
+
+// stripe returns the index of the L1 generation counter of the key
+func (c *CacheTwoTier[Key, _]) stripe(key Key) int {
+	return int(maphash.Comparable(c.seed, key) % l1GenerationStripes)
+}
+
+// GetKeys returns the keys of both tiers without duplicates, L2 ones first
+//
+// L1 keys are mostly in L2 too, but L2 might have evicted some of them already
+func (c *CacheTwoTier[Key, _]) GetKeys() []Key {
+	keys := c.l2.GetKeys()
+
+	seen := make(map[Key]struct{}, len(keys))
+	for _, key := range keys {
+		seen[key] = struct{}{}
+	}
+	for _, key := range c.l1.GetKeys() {
+		if _, ok := seen[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// GetKeysAmount returns the amount of keys of GetKeys
+func (c *CacheTwoTier[_, _]) GetKeysAmount() int {
+	return len(c.GetKeys())
+}
+
+// Stats returns the counters of the cache as a whole, see TierStats for every tier
+//
+// Hits are of both tiers, misses are of L2: an L1 miss that hits L2 is a hit.
+// Sets, Size and Capacity are of L2, it has every value. Evictions and expiries are of both tiers.
+// Size is 0 if L2 doesn't count its values, e.g. redis
+func (c *CacheTwoTier[_, _]) Stats() pkgports.CacheStats {
+	l1, l2 := c.l1.Stats(), c.l2.Stats()
+	return pkgports.CacheStats{
+		Hits:      l1.Hits + l2.Hits,
+		Misses:    l2.Misses,
+		Sets:      l2.Sets,
+		Evictions: l1.Evictions + l2.Evictions,
+		Expired:   l1.Expired + l2.Expired,
+		Size:      l2.Size,
+		Capacity:  l2.Capacity,
+	}
+}
+
+// TierStats returns the Stats of L1 and L2, L1 sets include promotions
+func (c *CacheTwoTier[_, _]) TierStats() []pkgports.CacheStats {
+	return []pkgports.CacheStats{c.l1.Stats(), c.l2.Stats()}
+}
+
+// MostUsedKey returns the most used key of L1, see pkgports.RecencyCache. L2 is shared, so its order isn't this
+// instance's one. ErrNoRecency is returned if L1 isn't a pkgports.RecencyCache
+func (c *CacheTwoTier[Key, Value]) MostUsedKey() (Key, error) {
+	recencyL1, ok := c.l1.(pkgports.RecencyCache[Key, Value])
+	if !ok {
+		return *new(Key), ErrNoRecency
+	}
+	return recencyL1.MostUsedKey()
+}
+
+// LeastUsedKey returns the least used key of L1 as MostUsedKey does
+func (c *CacheTwoTier[Key, Value]) LeastUsedKey() (Key, error) {
+	recencyL1, ok := c.l1.(pkgports.RecencyCache[Key, Value])
+	if !ok {
+		return *new(Key), ErrNoRecency
+	}
+	return recencyL1.LeastUsedKey()
+}
@@ -0,0 +1,113 @@
+package receiver
+
+import (
+	"github.com/segmentio/kafka-go"
+	"strconv"
+	"time"
+)
+
+// Reasons of sending a message to the DLQ, written in the DLQHeaderReason header
+const (
+	// DLQReasonRejected means that the message can never be processed, e.g. it's invalid
+	DLQReasonRejected = "rejected"
+	// DLQReasonMaxRetries means that all the retries failed
+	DLQReasonMaxRetries = "max_retries_reached"
+	// DLQReasonRetryOverflow means that there was no room for another retry
+	DLQReasonRetryOverflow = "retry_overflow"
+	// DLQReasonBackoffExhausted means that the backoff policy allowed no more retries, e.g. max elapsed time passed
+	DLQReasonBackoffExhausted = "backoff_exhausted"
+	// DLQReasonUndecodable means that the message payload can't be decoded, a poison message
+	DLQReasonUndecodable = "undecodable"
+)
+
+// Headers of a DLQ message, the value is the original message value
+const (
+	DLQHeaderReason            = "dlq-reason"
+	DLQHeaderError             = "dlq-error"
+	DLQHeaderAttempts          = "dlq-attempts"
+	DLQHeaderOriginalTopic     = "dlq-original-topic"
+	DLQHeaderOriginalPartition = "dlq-original-partition"
+	DLQHeaderOriginalOffset    = "dlq-original-offset"
+	DLQHeaderFailedAt          = "dlq-failed-at"
+)
+
+// NewDLQMessage creates a kafka.Message for the DLQ topic from the original one
+//
+// Key and value are kept as is, failure details are put in headers (check DLQHeaderReason and others)
+func NewDLQMessage(original kafka.Message, reason string, cause error, attempts int) kafka.Message {
+	errorText := ""
+	if cause != nil {
+		errorText = cause.Error()
+	}
+
+	headers := make([]kafka.Header, 0, len(original.Headers)+7)
+	headers = append(headers, original.Headers...)
+	headers = append(headers,
+		kafka.Header{Key: DLQHeaderReason, Value: []byte(reason)},
+		kafka.Header{Key: DLQHeaderError, Value: []byte(errorText)},
+		kafka.Header{Key: DLQHeaderAttempts, Value: []byte(strconv.Itoa(attempts))},
+		kafka.Header{Key: DLQHeaderOriginalTopic, Value: []byte(original.Topic)},
+		kafka.Header{Key: DLQHeaderOriginalPartition, Value: []byte(strconv.Itoa(original.Partition))},
+		kafka.Header{Key: DLQHeaderOriginalOffset, Value: []byte(strconv.FormatInt(original.Offset, 10))},
+		kafka.Header{Key: DLQHeaderFailedAt, Value: []byte(time.Now().UTC().Format(time.RFC3339Nano))},
+	)
+
+	return kafka.Message{
+		Key:     original.Key,
+		Value:   original.Value,
+		Headers: headers,
+	}
+}
+
+// DLQInfo is the failure details of a DLQ message, written by NewDLQMessage
+//
+// OriginalTopic is empty if the origin of the message is unknown
+type DLQInfo struct {
+	Reason            string
+	Error             string
+	Attempts          int
+	OriginalTopic     string
+	OriginalPartition int
+	OriginalOffset    int64
+	FailedAt          time.Time
+}
+
+// ParseDLQMessage reads failure details from headers of a message written with NewDLQMessage
+//
+// Missing or broken headers are left zero, FailedAt falls back to the message time.
+// The origin is known only if all of its headers are fine, OriginalTopic is empty otherwise
+func ParseDLQMessage(message kafka.Message) DLQInfo {
+	info := DLQInfo{
+		FailedAt: message.Time,
+	}
+
+	var hasPartition, hasOffset bool
+	for _, header := range message.Headers {
+		value := string(header.Value)
+		switch header.Key {
+		case DLQHeaderReason:
+			info.Reason = value
+		case DLQHeaderError:
+			info.Error = value
+		case DLQHeaderAttempts:
+			info.Attempts, _ = strconv.Atoi(value)
+		case DLQHeaderOriginalTopic:
+			info.OriginalTopic = value
+		case DLQHeaderOriginalPartition:
+			partition, err := strconv.Atoi(value)
+			info.OriginalPartition, hasPartition = partition, err == nil
+		case DLQHeaderOriginalOffset:
+			offset, err := strconv.ParseInt(value, 10, 64)
+			info.OriginalOffset, hasOffset = offset, err == nil
+		case DLQHeaderFailedAt:
+			if failedAt, err := time.Parse(time.RFC3339Nano, value); err == nil {
+				info.FailedAt = failedAt
+			}
+		}
+	}
+
+	if info.OriginalTopic == "" || !hasPartition || !hasOffset {
+		info.OriginalTopic, info.OriginalPartition, info.OriginalOffset = "", 0, 0
+	}
+	return info
+}
@@ -1,13 +1,18 @@
 package receiver
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/segmentio/kafka-go"
 	"go.uber.org/zap"
+	"io"
+	"order_service/pkg/delayqueue"
 	"order_service/pkg/logger"
 	"order_service/pkg/pkgports"
+	"sync"
 	"time"
 )
 
@@ -18,11 +23,15 @@ import (
 // stores the kafka.Message to commit success
 //
 // stores total tries, set to 0 if fresh message
+//
+// stores the first failure time and the last delay for the backoff policy
 type KafkaMessage[Value any] struct {
-	Value      Value
-	Message    kafka.Message
-	RetryAfter time.Time
-	TotalTries int
+	Value         Value
+	Message       kafka.Message
+	RetryAfter    time.Time
+	TotalTries    int
+	FirstFailedAt time.Time
+	LastDelay     time.Duration
 }
 
 // NewFreshMessage creates a new *KafkaMessage[Value] as if it's just from kafka
@@ -40,108 +49,311 @@ func NewFreshMessage[Value any](message kafka.Message, value Value) *KafkaMessag
 //
 // Automatically sets timestamp and tries count
 func NewRetriedMessage[Value any](message *KafkaMessage[Value], retryDelay time.Duration) *KafkaMessage[Value] {
+	now := time.Now()
+	firstFailedAt := message.FirstFailedAt
+	if firstFailedAt.IsZero() {
+		firstFailedAt = now
+	}
 	return &KafkaMessage[Value]{
-		Value:      message.Value,
-		Message:    message.Message,
-		TotalTries: message.TotalTries + 1,
-		RetryAfter: time.Now().Add(retryDelay),
+		Value:         message.Value,
+		Message:       message.Message,
+		TotalTries:    message.TotalTries + 1,
+		RetryAfter:    now.Add(retryDelay),
+		FirstFailedAt: firstFailedAt,
+		LastDelay:     retryDelay,
 	}
 }
 
 // KafkaReceiver is an implementation of pkgports.Receiver that uses Kafka
+//
+// Failed messages that can't be retried are written to the DLQ topic with dlqWriter,
+// the write is retried until it succeeds or ctx of OnFail is done.
+// Retried messages wait in a delay queue ordered by RetryAfter.
+//
+// Offsets are committed per partition only up to the highest contiguous processed message,
+// so messages that are still processed or retried are delivered again after a crash
 type KafkaReceiver[Value any] struct {
-	reader       *kafka.Reader
-	maxRetries   int
-	retryChan    chan *KafkaMessage[Value] // this is wrong, check comment in Consume method
-	fixedBackoff time.Duration
+	reader     *kafka.Reader
+	dlqWriter  MessageWriter
+	maxRetries int
+	retries    *delayqueue.DelayQueue[*KafkaMessage[Value]]
+	backoff    pkgports.BackoffPolicy
+
+	strictDecoding bool
+
+	offsets   *OffsetTracker
+	committed map[partitionKey]int64 // the last committed offset, commits never go back
+	commitMu  sync.Mutex
+}
+
+// MessageWriter writes messages to kafka, e.g. *kafka.Writer
+type MessageWriter interface {
+	WriteMessages(ctx context.Context, messages ...kafka.Message) error
 }
 
-// To our disappointment, I didn't create generic interfaces for retry and backoff
-// Skill issue
+// minDLQRetryDelay is the delay between DLQ writes when the backoff policy gives none
+const minDLQRetryDelay = time.Second
 
-// NewKafkaReceiver creates a new *KafkaReceiver, returning it as a pkgports.Receiver
+// NewKafkaReceiver creates a new *KafkaReceiver, returning it as a pkgports.BatchReceiver
+//
+// strictDecoding rejects payloads with unknown fields or trailing data
 func NewKafkaReceiver[ValueType any](
-	reader *kafka.Reader,
-	maxRetries int, retriesCapacity int, fixedBackoff time.Duration,
-) pkgports.Receiver[ValueType, *KafkaMessage[ValueType]] {
+	reader *kafka.Reader, dlqWriter MessageWriter,
+	maxRetries int, retriesCapacity int, backoff pkgports.BackoffPolicy, strictDecoding bool,
+) pkgports.BatchReceiver[ValueType, *KafkaMessage[ValueType]] {
 	return &KafkaReceiver[ValueType]{
-		reader:       reader,
-		maxRetries:   maxRetries,
-		retryChan:    make(chan *KafkaMessage[ValueType], retriesCapacity),
-		fixedBackoff: fixedBackoff,
+		reader:         reader,
+		dlqWriter:      dlqWriter,
+		maxRetries:     maxRetries,
+		retries:        delayqueue.NewDelayQueue[*KafkaMessage[ValueType]](retriesCapacity),
+		backoff:        backoff,
+		strictDecoding: strictDecoding,
+		offsets:        NewOffsetTracker(),
+		committed:      make(map[partitionKey]int64),
 	}
 }
 
 // Consume is supposed to be called continuously to read new messages
 //
-// # Messages that are put in ``retry`` have high priority
-//
-// If retried message's backoff isn't over yet, it's moved to the end. This is wrong but simple
+// Retried messages have high priority once their RetryAfter has passed.
+// While waiting for kafka, reading is interrupted exactly when the earliest retry is due
 func (k *KafkaReceiver[Value]) Consume(ctx context.Context) (Value, *KafkaMessage[Value], error) {
-	select {
-	case failedMessage := <-k.retryChan:
-		if time.Now().After(failedMessage.RetryAfter) {
-			return failedMessage.Value, failedMessage, nil
+	// step 1: ready retries go first
+	if failedMessage, ok := k.retries.PopReady(); ok {
+		return failedMessage.Value, failedMessage, nil
+	}
+
+	// step 2: read kafka until a message comes or a retry is due
+	readCtx, cancelRead := context.WithCancel(ctx)
+	defer cancelRead()
+
+	waitErr := make(chan error, 1)
+	go func() {
+		err := k.retries.WaitReady(readCtx)
+		if err == nil {
+			cancelRead()
 		}
+		waitErr <- err
+	}()
 
-		// This is HELL wrong, pushing message to the end is bad
-		// I could use a linked list instead
-		// But I don't care yet
-		k.retryChan <- failedMessage
-	default:
-		break // no retry messages
+	// messages are fetched without committing, offsets are committed in order by complete
+	msg, err := k.reader.FetchMessage(readCtx)
+	cancelRead()
+	retryIsDue := <-waitErr == nil
+	if err != nil {
+		if retryIsDue && ctx.Err() == nil {
+			// reading was interrupted by the retry, not by the caller
+			return k.Consume(ctx)
+		}
+		return *new(Value), nil, fmt.Errorf("error while reading from kafka: %w", err)
 	}
 
-	msg, err := k.reader.ReadMessage(ctx)
+	k.offsets.Track(msg)
+
+	value, err := DecodeJSON[Value](msg.Value, k.strictDecoding)
 	if err != nil {
-		return *new(Value), nil, fmt.Errorf("error while reading from kafka: %w", err)
+		// a poison message, it's returned so the caller can dead-letter and commit it
+		return *new(Value), NewFreshMessage[Value](msg, value),
+			fmt.Errorf("%w: %w", pkgports.ErrUndecodable, err)
 	}
+	return value, NewFreshMessage[Value](msg, value), nil
+}
+
+// DecodeJSON unmarshals the payload into Value, rejects unknown fields and trailing data if strict
+func DecodeJSON[Value any](payload []byte, strict bool) (Value, error) {
 	var value Value
-	err = json.Unmarshal(msg.Value, &value)
+	if !strict {
+		err := json.Unmarshal(payload, &value)
+		return value, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(payload))
+	decoder.DisallowUnknownFields()
+	err := decoder.Decode(&value)
 	if err != nil {
-		return *new(Value), nil, fmt.Errorf("error while unmarshalling message: %w", err)
+		return value, err
 	}
-	return value, NewFreshMessage[Value](msg, value), nil
+	// More() doesn't see a stray closing delimiter, e.g. {...}}, only the end of the payload is fine
+	if _, err = decoder.Token(); !errors.Is(err, io.EOF) {
+		return value, errors.New("unexpected data after the top-level value")
+	}
+	return value, nil
 }
 
 // OnSuccess must be called on every successful message processing
 func (k *KafkaReceiver[Value]) OnSuccess(ctx context.Context, givenMessage *KafkaMessage[Value]) error {
-	return k.reader.CommitMessages(ctx, givenMessage.Message)
+	return k.complete(ctx, givenMessage.Message)
 }
 
 // OnFail must be called on every unsuccessful message processing
-func (k *KafkaReceiver[Value]) OnFail(ctx context.Context, shouldRetry bool, givenMessage *KafkaMessage[Value]) error {
+//
+// The message is either put to retries or written to the DLQ and committed
+func (k *KafkaReceiver[Value]) OnFail(ctx context.Context, shouldRetry bool, givenMessage *KafkaMessage[Value], cause error) error {
+	if errors.Is(cause, pkgports.ErrUndecodable) {
+		return k.sendToDLQ(ctx, givenMessage, DLQReasonUndecodable, cause)
+	}
 	if shouldRetry {
-		k.sendToRetries(ctx, givenMessage)
-	} else {
-		k.sendToDLQ(ctx, givenMessage)
+		return k.sendToRetries(ctx, givenMessage, cause)
 	}
-	return nil
+	return k.sendToDLQ(ctx, givenMessage, DLQReasonRejected, cause)
 }
 
-func (k *KafkaReceiver[Value]) sendToRetries(ctx context.Context, givenMessage *KafkaMessage[Value]) {
+func (k *KafkaReceiver[Value]) sendToRetries(ctx context.Context, givenMessage *KafkaMessage[Value], cause error) error {
 	totalTries := givenMessage.TotalTries + 1
 	if totalTries > k.maxRetries {
 		logger.GetLoggerFromCtx(ctx).Info(ctx, "message sent to DLQ, max retries reached",
 			zap.Int("total tries", totalTries))
-		go k.sendToDLQ(ctx, givenMessage)
-		return
+		return k.sendToDLQ(ctx, givenMessage, DLQReasonMaxRetries, cause)
 	}
 
-	newMessage := NewRetriedMessage[Value](givenMessage, k.fixedBackoff)
-	select {
-	case k.retryChan <- newMessage:
-		logger.GetLoggerFromCtx(ctx).Info(ctx, "message sent to retry channel",
-			zap.Int("total tries", newMessage.TotalTries), zap.Time("retry after", newMessage.RetryAfter))
-	default:
-		go k.sendToDLQ(ctx, givenMessage)
+	var elapsed time.Duration
+	if !givenMessage.FirstFailedAt.IsZero() {
+		elapsed = time.Since(givenMessage.FirstFailedAt)
+	}
+	delay, ok := k.backoff.NextDelay(totalTries, givenMessage.LastDelay, elapsed)
+	if !ok {
+		logger.GetLoggerFromCtx(ctx).Info(ctx, "message sent to DLQ, backoff policy gave up",
+			zap.Int("total tries", totalTries), zap.Duration("elapsed", elapsed))
+		return k.sendToDLQ(ctx, givenMessage, DLQReasonBackoffExhausted, cause)
+	}
+
+	newMessage := NewRetriedMessage[Value](givenMessage, delay)
+	err := k.retries.Push(newMessage, newMessage.RetryAfter)
+	if err != nil {
+		// the consumer loop mustn't wait for free space
 		logger.GetLoggerFromCtx(ctx).Warn(ctx, "retry overflow! sending to DLQ",
-			zap.Int("total tries", newMessage.TotalTries), zap.Time("retry after", newMessage.RetryAfter))
+			zap.Int("total tries", newMessage.TotalTries), zap.Time("retry after", newMessage.RetryAfter),
+			zap.Int("retries depth", k.retries.Len()))
+		return k.sendToDLQ(ctx, givenMessage, DLQReasonRetryOverflow, cause)
+	}
+
+	logger.GetLoggerFromCtx(ctx).Info(ctx, "message sent to retries",
+		zap.Int("total tries", newMessage.TotalTries), zap.Time("retry after", newMessage.RetryAfter),
+		zap.Int("retries depth", k.retries.Len()))
+	return nil
+}
+
+// RetriesDepth returns the amount of messages waiting for retry
+func (k *KafkaReceiver[Value]) RetriesDepth() int {
+	return k.retries.Len()
+}
+
+// sendToDLQ writes the original message to the DLQ topic with failure details in headers
+//
+// The original message is committed only after the DLQ write succeeds. The write is retried with the backoff policy
+// until then: the partition can't be committed past an uncompleted message, so giving up would stall it.
+// It gives up only when ctx is done (e.g. the shutdown deadline), the message is delivered again after restart
+func (k *KafkaReceiver[Value]) sendToDLQ(ctx context.Context, givenMessage *KafkaMessage[Value], reason string, cause error) error {
+	dlqMessage := NewDLQMessage(givenMessage.Message, reason, cause, givenMessage.TotalTries+1)
+
+	err := k.writeDLQ(ctx, dlqMessage)
+	if err != nil {
+		return fmt.Errorf("error writing message to DLQ, original message isn't committed: %w", err)
+	}
+
+	err = k.complete(ctx, givenMessage.Message)
+	if err != nil {
+		return fmt.Errorf("error committing message after writing it to DLQ: %w", err)
+	}
+
+	logger.GetLoggerFromCtx(ctx).Info(ctx, "message sent to DLQ",
+		zap.String("reason", reason), zap.Error(cause),
+		zap.String("topic", givenMessage.Message.Topic),
+		zap.Int("partition", givenMessage.Message.Partition),
+		zap.Int64("offset", givenMessage.Message.Offset))
+	return nil
+}
+
+// writeDLQ writes the message to the DLQ topic, retrying until it succeeds or ctx is done
+//
+// Every failed write is logged as an error, a DLQ that's down blocks the caller until ctx is done
+func (k *KafkaReceiver[Value]) writeDLQ(ctx context.Context, dlqMessage kafka.Message) error {
+	var delay time.Duration
+	startedAt := time.Now()
+	for attempt := 1; ; attempt++ {
+		err := k.dlqWriter.WriteMessages(ctx, dlqMessage)
+		if err == nil {
+			return nil
+		}
+
+		nextDelay, ok := k.backoff.NextDelay(attempt, delay, time.Since(startedAt))
+		if ok && nextDelay > 0 {
+			delay = nextDelay
+		} else if delay <= 0 {
+			delay = minDLQRetryDelay
+		}
+		logger.GetLoggerFromCtx(ctx).Error(ctx, "error writing message to DLQ, retrying",
+			zap.Int("attempt", attempt), zap.Duration("retry after", delay), zap.Error(err))
+
+		select {
+		case <-ctx.Done():
+			return errors.Join(err, ctx.Err())
+		case <-time.After(delay):
+		}
+	}
+}
+
+// complete marks the message as processed and commits the highest contiguous processed offset of its partition
+func (k *KafkaReceiver[Value]) complete(ctx context.Context, message kafka.Message) error {
+	toCommit, ok := k.offsets.Complete(message)
+	if !ok {
+		return nil // an earlier message of the partition is still in-flight
+	}
+	return k.commit(ctx, toCommit)
+}
+
+// commit commits given messages, ones that are behind already committed offsets are skipped
+func (k *KafkaReceiver[Value]) commit(ctx context.Context, messages ...kafka.Message) error {
+	// concurrent completions might compute commit points out of order, an older one is skipped
+	k.commitMu.Lock()
+	defer k.commitMu.Unlock()
+
+	toCommit := make([]kafka.Message, 0, len(messages))
+	for _, message := range messages {
+		key := partitionKey{topic: message.Topic, partition: message.Partition}
+		if committed, found := k.committed[key]; found && committed >= message.Offset {
+			continue
+		}
+		toCommit = append(toCommit, message)
+	}
+	if len(toCommit) == 0 {
+		return nil
+	}
+
+	err := k.reader.CommitMessages(ctx, toCommit...)
+	if err != nil {
+		return fmt.Errorf("error committing offsets: %w", err)
+	}
+	for _, message := range toCommit {
+		k.committed[partitionKey{topic: message.Topic, partition: message.Partition}] = message.Offset
+	}
+	return nil
+}
+
+// OnSuccessBatch must be called on every successfully processed batch
+//
+// Every partition of the batch is committed once, up to its highest contiguous processed offset
+func (k *KafkaReceiver[Value]) OnSuccessBatch(ctx context.Context, givenMessages []*KafkaMessage[Value]) error {
+	commitPoints := make(map[partitionKey]kafka.Message)
+	for _, givenMessage := range givenMessages {
+		toCommit, ok := k.offsets.Complete(givenMessage.Message)
+		if !ok {
+			continue
+		}
+		key := partitionKey{topic: toCommit.Topic, partition: toCommit.Partition}
+		if point, found := commitPoints[key]; !found || point.Offset < toCommit.Offset {
+			commitPoints[key] = toCommit
+		}
+	}
+
+	toCommit := make([]kafka.Message, 0, len(commitPoints))
+	for _, point := range commitPoints {
+		toCommit = append(toCommit, point)
 	}
+	return k.commit(ctx, toCommit...)
 }
 
-func (k *KafkaReceiver[Value]) sendToDLQ(ctx context.Context, givenMessage *KafkaMessage[Value]) {
-	// TODO: maybe real DLQ?
-	logger.GetLoggerFromCtx(ctx).Info(ctx, "message sent to DLQ", zap.Any("message", givenMessage.Message),
-		zap.Any("value", givenMessage.Value))
+// InFlight returns the amount of fetched messages that aren't processed yet, including retries
+func (k *KafkaReceiver[Value]) InFlight() int {
+	return k.offsets.InFlight()
 }
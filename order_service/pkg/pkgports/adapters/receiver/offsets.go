@@ -0,0 +1,131 @@
+package receiver
+
+import (
+	"github.com/segmentio/kafka-go"
+	"sync"
+)
+
+// partitionKey identifies a partition of a topic
+type partitionKey struct {
+	topic     string
+	partition int
+}
+
+// trackedOffset is a fetched message that might be still in-flight
+type trackedOffset struct {
+	message   kafka.Message
+	completed bool
+}
+
+// partitionOffsets stores tracked offsets of one partition in fetch order, lastTracked is the last fetched one
+type partitionOffsets struct {
+	queue       []*trackedOffset
+	byIndex     map[int64]*trackedOffset
+	lastTracked int64
+}
+
+// newPartitionOffsets creates a new empty *partitionOffsets
+func newPartitionOffsets() *partitionOffsets {
+	return &partitionOffsets{byIndex: make(map[int64]*trackedOffset), lastTracked: -1}
+}
+
+// OffsetTracker tracks in-flight messages per partition and tells what offset can be committed
+//
+// Messages can be completed in any order (e.g. concurrent processing or retries),
+// but only the highest contiguous completed offset is committed.
+// So a message that's still being processed or retried is never committed past
+//
+// kafka-go has no rebalance callbacks, so a rebalance is noticed by its effect: a partition that's assigned again
+// is fetched from the committed offset, behind the tracked ones. Its tracked offsets are dropped then,
+// messages fetched before the rebalance are redelivered and tracked again
+type OffsetTracker struct {
+	partitions map[partitionKey]*partitionOffsets
+	inFlight   int
+	mu         sync.Mutex
+}
+
+// NewOffsetTracker creates a new empty *OffsetTracker
+func NewOffsetTracker() *OffsetTracker {
+	return &OffsetTracker{
+		partitions: make(map[partitionKey]*partitionOffsets),
+	}
+}
+
+// Track must be called on every fetched message, in the fetch order
+//
+// An offset that isn't after the last tracked one of its partition means a rebalance,
+// the partition is reset then, see OffsetTracker
+func (t *OffsetTracker) Track(message kafka.Message) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	key := partitionKey{topic: message.Topic, partition: message.Partition}
+	offsets, ok := t.partitions[key]
+	if !ok || message.Offset <= offsets.lastTracked {
+		if ok {
+			t.inFlight -= offsets.pending()
+		}
+		offsets = newPartitionOffsets()
+		t.partitions[key] = offsets
+	}
+
+	tracked := &trackedOffset{message: message}
+	offsets.queue = append(offsets.queue, tracked)
+	offsets.byIndex[message.Offset] = tracked
+	offsets.lastTracked = message.Offset
+	t.inFlight++
+}
+
+// pending returns the amount of tracked offsets that aren't completed
+func (o *partitionOffsets) pending() int {
+	pending := 0
+	for _, tracked := range o.queue {
+		if !tracked.completed {
+			pending++
+		}
+	}
+	return pending
+}
+
+// Complete marks given message as processed (successfully or not, e.g. written to the DLQ)
+//
+// Returns the message to commit if the contiguous completed prefix of the partition has grown,
+// committing it means committing every message before it. ok is false if nothing can be committed yet
+func (t *OffsetTracker) Complete(message kafka.Message) (toCommit kafka.Message, ok bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	offsets, found := t.partitions[partitionKey{topic: message.Topic, partition: message.Partition}]
+	if !found {
+		return toCommit, false
+	}
+	tracked, found := offsets.byIndex[message.Offset]
+	if !found || tracked.completed {
+		return toCommit, false
+	}
+	tracked.completed = true
+	t.inFlight--
+
+	// pop the completed prefix, its last message is the commit point
+	popped := 0
+	for popped < len(offsets.queue) && offsets.queue[popped].completed {
+		toCommit = offsets.queue[popped].message
+		delete(offsets.byIndex, toCommit.Offset)
+		popped++
+	}
+	if popped == 0 {
+		return toCommit, false
+	}
+
+	clear(offsets.queue[:popped]) // don't keep references to the messages
+	offsets.queue = offsets.queue[popped:]
+	return toCommit, true
+}
+
+// InFlight returns the amount of tracked messages that aren't completed yet
+func (t *OffsetTracker) InFlight() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.inFlight
+}
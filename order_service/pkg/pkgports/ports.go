@@ -2,29 +2,120 @@ package pkgports
 
 import (
 	"context"
+	"errors"
+	"time"
 )
 
 // Cache describes a cache that might be
 // implemented with different storages (e.g. in-memory, redis)
 // and mechanisms (e.g. N last saved)
 type Cache[Key comparable, Value any] interface {
-	// Set saves a value (invalidates first value)
+	// Set saves a value (invalidates first value), it expires after the default TTL of the cache if there is one
 	Set(ctx context.Context, key Key, value Value) error
 
-	// Get returns value, ok, err (idempotent)
+	// SetWithTTL is Set with the TTL of this value only, ttl <= 0 means the value doesn't expire
+	SetWithTTL(ctx context.Context, key Key, value Value, ttl time.Duration) error
+
+	// Get returns value, ok, err (idempotent), expired values aren't returned
 	Get(ctx context.Context, key Key) (Value, bool, error)
 
+	// Peek is Get that doesn't count as a use of the value, e.g. it doesn't save an LRU value from eviction
+	Peek(ctx context.Context, key Key) (Value, bool, error)
+
+	// Delete removes a value, ok is false if there was none
+	Delete(ctx context.Context, key Key) (bool, error)
+
+	// Clear removes all the values
+	Clear(ctx context.Context) error
+
 	// GetKeys returns a slice of all saved keys
 	GetKeys() []Key
 
 	// GetKeysAmount returns the amount of saved keys
 	GetKeysAmount() int
+
+	// Stats returns the counters of the cache since it was created
+	Stats() CacheStats
+}
+
+// CacheStats are the counters of a Cache, Size and Capacity are current values, the rest only grow
+//
+// Expired values are misses when they're read, they aren't counted as Evictions.
+// Size is 0 if the cache can't count its values cheaply, e.g. a shared redis one
+type CacheStats struct {
+	Hits      uint64
+	Misses    uint64
+	Sets      uint64
+	Evictions uint64
+	Expired   uint64
+	Size      int
+	// Capacity is 0 if the cache isn't limited by the amount of values
+	Capacity int
+}
+
+// TieredCache is a Cache made of several caches, e.g. an in-process one in front of a shared one
+type TieredCache[Key comparable, Value any] interface {
+	Cache[Key, Value]
+
+	// TierStats returns the Stats of every tier, from the closest one (L1) to the farthest
+	TierStats() []CacheStats
 }
 
+// RecencyCache is a Cache that knows the order of its values by use, e.g. LRU
+type RecencyCache[Key comparable, Value any] interface {
+	Cache[Key, Value]
+
+	// MostUsedKey returns the key that was set or read last, an error if there are none
+	MostUsedKey() (Key, error)
+
+	// LeastUsedKey returns the key that is evicted next, an error if there are none
+	LeastUsedKey() (Key, error)
+}
+
+// GenerationCache is a Cache that doesn't let a value read before an invalidation overwrite it, e.g. on read-through
+//
+// Every key has a generation that's bumped by Invalidate. A value loaded from a storage is set with SetIfGeneration
+// and the generation taken before the load, so it isn't cached if the key was invalidated while it was loaded
+type GenerationCache[Key comparable, Value any] interface {
+	Cache[Key, Value]
+
+	// Generation returns the current generation of the key
+	Generation(ctx context.Context, key Key) (uint64, error)
+
+	// Invalidate removes a value as Delete does and bumps the generation of the key
+	Invalidate(ctx context.Context, key Key) (bool, error)
+
+	// SetIfGeneration is Set that's skipped if the key isn't of given generation anymore, ok is false then
+	SetIfGeneration(ctx context.Context, key Key, value Value, generation uint64) (bool, error)
+}
+
+// CacheInvalidations broadcasts changed keys between instances of a cache, e.g. with redis pub/sub,
+// so they drop their own copies of the values
+//
+// Delivery is at most once: keys published while an instance isn't subscribed aren't received by it
+type CacheInvalidations[Key comparable] interface {
+	// Publish tells the other instances to drop the key
+	Publish(ctx context.Context, key Key) error
+
+	// PublishClear tells the other instances to drop every key
+	PublishClear(ctx context.Context) error
+
+	// Subscribe calls onKey and onClear with everything published by the other instances until ctx is done,
+	// the own publications are skipped
+	Subscribe(ctx context.Context, onKey func(ctx context.Context, key Key), onClear func(ctx context.Context)) error
+}
+
+// ErrUndecodable describes a received message that can't be decoded into a value, a poison message
+//
+// Consume returns it together with the message, so the message can be passed to OnFail
+var ErrUndecodable = errors.New("message can't be decoded")
+
 // Receiver port describes a message queue consumer that gets orders for save, e.g. kafka
 //
 // values are read with Consume method and must be commited with either OnSuccess or OnFail
 //
+// if Consume's error wraps ErrUndecodable, the message is returned and must be commited with OnFail too
+//
 // values are unmarshalled into generic ValueType
 //
 // incoming messages that are passed into commit methods are MessageType (e.g. kafka.Message)
@@ -32,6 +123,24 @@ type Receiver[ValueType, MessageType any] interface {
 	Consume(ctx context.Context) (ValueType, MessageType, error)
 	// OnSuccess must be called on every successful message processing
 	OnSuccess(ctx context.Context, givenMessage MessageType) error
-	// OnFail must be called on every unsuccessful message processing
-	OnFail(ctx context.Context, shouldRetry bool, givenMessage MessageType) error
+	// OnFail must be called on every unsuccessful message processing, cause is the processing error
+	OnFail(ctx context.Context, shouldRetry bool, givenMessage MessageType, cause error) error
+}
+
+// BatchReceiver port describes a Receiver that commits a batch of successfully processed messages at once
+type BatchReceiver[ValueType, MessageType any] interface {
+	Receiver[ValueType, MessageType]
+	// OnSuccessBatch is the same as OnSuccess for every given message, but commits once
+	OnSuccessBatch(ctx context.Context, givenMessages []MessageType) error
+}
+
+// BackoffPolicy describes how long to wait before the next retry, e.g. fixed or exponential delays
+//
+// attempt is the number of the upcoming retry starting with 1,
+// previous is the delay returned for the previous attempt (0 before the first retry),
+// elapsed is the time passed since the first failure
+//
+// ok is false when there must be no more retries
+type BackoffPolicy interface {
+	NextDelay(attempt int, previous, elapsed time.Duration) (delay time.Duration, ok bool)
 }
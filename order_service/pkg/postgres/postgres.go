@@ -20,6 +20,9 @@ type Config struct {
 
 	MaxConns int32 `yaml:"max_conn" env:"MAX_CONN" env-default:"10"`
 	MinConns int32 `yaml:"min_conn" env:"MIN_CONN" env-default:"5"`
+
+	// MigrationsURL is the golang-migrate source of migrations, applied in New
+	MigrationsURL string `yaml:"migrations_url" env:"MIGRATIONS_URL" env-default:"file:///app/db/migrations"`
 }
 
 // New creates a new postgres pool with given settings
@@ -47,7 +50,7 @@ func New(ctx context.Context, config Config) (*pgxpool.Pool, error) {
 	}
 
 	m, err := migrate.New(
-		"file:///app/db/migrations",
+		config.MigrationsURL,
 		connStringShort,
 	)
 	if err != nil {
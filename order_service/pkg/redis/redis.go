@@ -0,0 +1,44 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	goredis "github.com/redis/go-redis/v9"
+	"time"
+)
+
+// Config from redis package is supposed to be used with an env-prefix of "REDIS_"
+type Config struct {
+	Host     string `yaml:"host" env:"HOST" env-default:"redis"`
+	Port     uint16 `yaml:"port" env:"PORT" env-default:"6379"`
+	Password string `yaml:"password" env:"PASSWORD"`
+	DB       int    `yaml:"db" env:"DB" env-default:"0"`
+
+	// PoolSize is the max amount of connections, MinIdleConns are kept open when there's nothing to do
+	PoolSize     int `yaml:"pool_size" env:"POOL_SIZE" env-default:"10"`
+	MinIdleConns int `yaml:"min_idle_conns" env:"MIN_IDLE_CONNS" env-default:"2"`
+
+	DialTimeoutMs  int `yaml:"dial_timeout_ms" env:"DIAL_TIMEOUT_MS" env-default:"5000"`
+	ReadTimeoutMs  int `yaml:"read_timeout_ms" env:"READ_TIMEOUT_MS" env-default:"1000"`
+	WriteTimeoutMs int `yaml:"write_timeout_ms" env:"WRITE_TIMEOUT_MS" env-default:"1000"`
+}
+
+// New creates a new redis client with given settings and checks the connection
+func New(ctx context.Context, config Config) (*goredis.Client, error) {
+	client := goredis.NewClient(&goredis.Options{
+		Addr:         fmt.Sprintf("%s:%d", config.Host, config.Port),
+		Password:     config.Password,
+		DB:           config.DB,
+		PoolSize:     config.PoolSize,
+		MinIdleConns: config.MinIdleConns,
+		DialTimeout:  time.Duration(config.DialTimeoutMs) * time.Millisecond,
+		ReadTimeout:  time.Duration(config.ReadTimeoutMs) * time.Millisecond,
+		WriteTimeout: time.Duration(config.WriteTimeoutMs) * time.Millisecond,
+	})
+
+	if err := client.Ping(ctx).Err(); err != nil {
+		_ = client.Close()
+		return nil, fmt.Errorf("unable to connect to redis %s:%d: %w", config.Host, config.Port, err)
+	}
+	return client, nil
+}
@@ -0,0 +1,75 @@
+package tests
+
+import (
+	"order_service/pkg/pkgports/adapters/backoff"
+	"testing"
+	"time"
+)
+
+func TestExponentialBackoffIsCapped(t *testing.T) {
+	policy := backoff.NewExponential(100*time.Millisecond, time.Second, 2)
+
+	expected := []time.Duration{
+		100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond,
+		800 * time.Millisecond, time.Second, time.Second,
+	}
+	for i, want := range expected {
+		delay, ok := policy.NextDelay(i+1, 0, 0)
+		if !ok {
+			t.Fatalf("Expected attempt %d to be allowed", i+1)
+		}
+		if delay != want {
+			t.Errorf("Attempt %d: expected %v, got %v", i+1, want, delay)
+		}
+	}
+
+	// must not overflow on huge attempts
+	if delay, _ := policy.NextDelay(1000, 0, 0); delay != time.Second {
+		t.Errorf("Expected capped delay for a huge attempt, got %v", delay)
+	}
+}
+
+func TestJitterBackoffBounds(t *testing.T) {
+	fullJitter := backoff.NewFullJitter(100*time.Millisecond, time.Second, 2)
+	decorrelated := backoff.NewDecorrelatedJitter(100*time.Millisecond, time.Second)
+
+	var previous time.Duration
+	for attempt := 1; attempt <= 100; attempt++ {
+		delay, _ := fullJitter.NextDelay(attempt, 0, 0)
+		if delay < 0 || delay > time.Second {
+			t.Fatalf("Full jitter delay %v is out of [0, 1s]", delay)
+		}
+
+		delay, _ = decorrelated.NextDelay(attempt, previous, 0)
+		if delay < 100*time.Millisecond || delay > time.Second {
+			t.Fatalf("Decorrelated jitter delay %v is out of [100ms, 1s]", delay)
+		}
+		previous = delay
+	}
+}
+
+func TestMaxElapsedBackoffGivesUp(t *testing.T) {
+	policy := backoff.NewMaxElapsed(backoff.NewFixed(time.Second), 5*time.Second)
+
+	if _, ok := policy.NextDelay(1, 0, 3*time.Second); !ok {
+		t.Error("Expected retry at 3s+1s to be allowed")
+	}
+	if _, ok := policy.NextDelay(2, time.Second, 4500*time.Millisecond); ok {
+		t.Error("Expected retry at 4.5s+1s to be rejected")
+	}
+}
+
+func TestBackoffFromConfig(t *testing.T) {
+	for _, name := range []string{
+		backoff.PolicyFixed, backoff.PolicyExponential, backoff.PolicyFullJitter, backoff.PolicyDecorrelatedJitter,
+	} {
+		_, err := backoff.New(backoff.Config{Policy: name, BaseMs: 100, MaxMs: 1000, Multiplier: 2})
+		if err != nil {
+			t.Errorf("Policy %s: unexpected error %v", name, err)
+		}
+	}
+
+	if _, err := backoff.New(backoff.Config{Policy: "unknown"}); err == nil {
+		t.Error("Expected an error for unknown policy")
+	}
+}
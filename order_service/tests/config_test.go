@@ -0,0 +1,31 @@
+package tests
+
+import (
+	"order_service/internal/config"
+	"order_service/pkg/pkgports/adapters/backoff"
+	"testing"
+)
+
+func TestConfigDeprecatedSaveBackoffSeconds(t *testing.T) {
+	t.Setenv("ORDER_SERVICE_SAVE_BACKOFF_SECONDS", "7")
+
+	cfg, err := config.TryRead()
+	if err != nil {
+		t.Fatalf("Expected config to be read, got error: %v", err)
+	}
+	if cfg.OrderService.SaveBackoff.Policy != backoff.PolicyFixed || cfg.OrderService.SaveBackoff.BaseMs != 7000 {
+		t.Errorf("Expected a fixed delay of 7s, got %+v", cfg.OrderService.SaveBackoff)
+	}
+
+	// the new keys win
+	t.Setenv("ORDER_SERVICE_SAVE_BACKOFF_POLICY", backoff.PolicyExponential)
+	t.Setenv("ORDER_SERVICE_SAVE_BACKOFF_BASE_MS", "100")
+
+	cfg, err = config.TryRead()
+	if err != nil {
+		t.Fatalf("Expected config to be read, got error: %v", err)
+	}
+	if cfg.OrderService.SaveBackoff.Policy != backoff.PolicyExponential || cfg.OrderService.SaveBackoff.BaseMs != 100 {
+		t.Errorf("Expected the exponential policy with 100ms base, got %+v", cfg.OrderService.SaveBackoff)
+	}
+}
@@ -0,0 +1,202 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/segmentio/kafka-go"
+	"math"
+	"order_service/internal/models"
+	"order_service/internal/ports"
+	"order_service/internal/ports/adapters/storage"
+	"order_service/internal/service"
+	"order_service/pkg/logger"
+	"order_service/pkg/pkgports/adapters/backoff"
+	"order_service/pkg/pkgports/adapters/receiver"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestParseDLQMessageOrigin(t *testing.T) {
+	original := kafka.Message{Topic: "orders", Partition: 2, Offset: 42, Value: []byte("{}")}
+	info := receiver.ParseDLQMessage(receiver.NewDLQMessage(original, receiver.DLQReasonRejected, errors.New("boom"), 3))
+	if info.OriginalTopic != "orders" || info.OriginalPartition != 2 || info.OriginalOffset != 42 {
+		t.Errorf("Expected origin orders/2/42, got %s/%d/%d", info.OriginalTopic, info.OriginalPartition, info.OriginalOffset)
+	}
+	if info.Reason != receiver.DLQReasonRejected || info.Error != "boom" || info.Attempts != 3 {
+		t.Errorf("Expected failure details to be parsed, got %+v", info)
+	}
+
+	// a partial or broken origin is unknown, it mustn't look like partition 0 and offset 0
+	topic := kafka.Header{Key: receiver.DLQHeaderOriginalTopic, Value: []byte("orders")}
+	partition := kafka.Header{Key: receiver.DLQHeaderOriginalPartition, Value: []byte("1")}
+	offset := kafka.Header{Key: receiver.DLQHeaderOriginalOffset, Value: []byte("7")}
+	cases := map[string][]kafka.Header{
+		"no headers":       nil,
+		"no offset":        {topic, partition},
+		"broken partition": {topic, {Key: receiver.DLQHeaderOriginalPartition, Value: []byte("x")}, offset},
+		"no topic":         {partition, offset},
+	}
+	for name, headers := range cases {
+		info = receiver.ParseDLQMessage(kafka.Message{Headers: headers})
+		if info.OriginalTopic != "" || info.OriginalPartition != 0 || info.OriginalOffset != 0 {
+			t.Errorf("%s: expected unknown origin, got %s/%d/%d", name, info.OriginalTopic, info.OriginalPartition, info.OriginalOffset)
+		}
+	}
+}
+
+func TestStorageSaveDeadLettersUnknownOrigin(t *testing.T) {
+	_, pool := newTestStorage(t)
+	s := storage.NewDeadLettersStoragePostgres(pool)
+	ctx := context.Background()
+
+	orderUID := fmt.Sprintf("dlq%d", time.Now().UnixNano())
+	t.Cleanup(func() {
+		_, err := pool.Exec(context.Background(), "DELETE FROM order_service.dead_letters WHERE order_uid = $1", orderUID)
+		if err != nil {
+			t.Errorf("Couldn't delete test dead letters %s: %v", orderUID, err)
+		}
+	})
+
+	deadLetter := models.DeadLetter{
+		OrderUID: orderUID,
+		Reason:   receiver.DLQReasonRejected,
+		Attempts: 1,
+		FailedAt: time.Now(),
+		Payload:  []byte("{}"),
+	}
+	// messages without origin headers can't be told apart, every one is saved
+	for i := 0; i < 2; i++ {
+		if err := s.SaveDeadLetter(ctx, deadLetter); err != nil {
+			t.Fatalf("Expected dead letter to be saved, got error: %v", err)
+		}
+	}
+	// a redelivered message with a known origin is saved once
+	deadLetter.OriginalTopic = fmt.Sprintf("orders%d", time.Now().UnixNano())
+	deadLetter.OriginalOffset = 42
+	for i := 0; i < 2; i++ {
+		if err := s.SaveDeadLetter(ctx, deadLetter); err != nil {
+			t.Fatalf("Expected dead letter to be saved, got error: %v", err)
+		}
+	}
+
+	saved, err := s.ListDeadLetters(ctx, models.DeadLettersFilter{Limit: 10, OrderUID: orderUID})
+	if err != nil {
+		t.Fatalf("Expected dead letters to be listed, got error: %v", err)
+	}
+	if len(saved) != 3 {
+		t.Fatalf("Expected 2 dead letters of unknown origin and 1 of a known one, got %d", len(saved))
+	}
+	if saved[0].OriginalTopic != "" || saved[2].OriginalTopic != deadLetter.OriginalTopic || saved[2].OriginalOffset != 42 {
+		t.Errorf("Expected unknown origins to be empty and the known one to be kept, got %+v", saved)
+	}
+}
+
+// refusingBackoff is a pkgports.BackoffPolicy that gives up right away
+type refusingBackoff struct{}
+
+func (refusingBackoff) NextDelay(_ int, _, _ time.Duration) (time.Duration, bool) {
+	return 0, false
+}
+
+// fakeDeadLetterReceiver returns a single dead letter, then waits for ctx
+type fakeDeadLetterReceiver struct {
+	consumed bool
+}
+
+func (r *fakeDeadLetterReceiver) Consume(ctx context.Context) (models.DeadLetter, struct{}, error) {
+	if !r.consumed {
+		r.consumed = true
+		return models.DeadLetter{Reason: receiver.DLQReasonRejected}, struct{}{}, nil
+	}
+	<-ctx.Done()
+	return models.DeadLetter{}, struct{}{}, ctx.Err()
+}
+
+func (r *fakeDeadLetterReceiver) Commit(_ context.Context, _ struct{}) error {
+	return nil
+}
+
+// failingDeadLetterStorage fails every save, the other methods aren't used by tests and panic
+type failingDeadLetterStorage struct {
+	ports.DeadLetterStorage
+
+	saves atomic.Int64
+}
+
+func (s *failingDeadLetterStorage) SaveDeadLetter(_ context.Context, _ models.DeadLetter) error {
+	s.saves.Add(1)
+	return errors.New("postgres is down")
+}
+
+func TestDeadLetterCollectorDoesNotSpin(t *testing.T) {
+	ctx, err := logger.New(context.Background())
+	if err != nil {
+		t.Fatalf("Error creating logger for test: %v", err)
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	s := &failingDeadLetterStorage{}
+	collector := service.NewDeadLetterCollectorService[struct{}](&fakeDeadLetterReceiver{}, s, refusingBackoff{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		_ = collector.StartCollecting(ctx)
+	}()
+
+	// the policy has no delay to keep, the collector must still wait between attempts
+	time.Sleep(100 * time.Millisecond)
+	collector.StopCollecting(ctx)
+	<-stopped
+
+	if saves := s.saves.Load(); saves != 1 {
+		t.Errorf("Expected a single attempt to save in 100ms, got %d", saves)
+	}
+}
+
+// flakyDLQWriter is a receiver.MessageWriter that fails the first failures writes
+type flakyDLQWriter struct {
+	failures int32
+	writes   atomic.Int32
+}
+
+func (w *flakyDLQWriter) WriteMessages(_ context.Context, _ ...kafka.Message) error {
+	if w.writes.Add(1) <= w.failures {
+		return errors.New("kafka is down")
+	}
+	return nil
+}
+
+func TestKafkaReceiverRetriesDLQWrite(t *testing.T) {
+	ctx, err := logger.New(context.Background())
+	if err != nil {
+		t.Fatalf("Couldn't create logger: %v", err)
+	}
+
+	writer := &flakyDLQWriter{failures: 2}
+	kafkaReceiver := receiver.NewKafkaReceiver[models.Order](nil, writer, 0, 10, backoff.NewFixed(time.Millisecond), false)
+	message := receiver.NewFreshMessage(kafka.Message{Topic: "orders", Offset: 1}, models.Order{})
+
+	// the message isn't lost: the write is retried until it succeeds
+	if err = kafkaReceiver.OnFail(ctx, false, message, errors.New("rejected")); err != nil {
+		t.Fatalf("Expected the DLQ write to succeed after retries, got error: %v", err)
+	}
+	if writes := writer.writes.Load(); writes != 3 {
+		t.Errorf("Expected 3 DLQ writes, got %d", writes)
+	}
+
+	// a DLQ that's down only stops retrying on shutdown
+	writer = &flakyDLQWriter{failures: math.MaxInt32}
+	kafkaReceiver = receiver.NewKafkaReceiver[models.Order](nil, writer, 0, 10, refusingBackoff{}, false)
+	timeoutCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	if err = kafkaReceiver.OnFail(timeoutCtx, false, message, errors.New("rejected")); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected the DLQ write to be interrupted, got error: %v", err)
+	}
+	// the policy gives no delay, the minimal one is used
+	if writes := writer.writes.Load(); writes != 1 {
+		t.Errorf("Expected a single DLQ write, got %d", writes)
+	}
+}
@@ -0,0 +1,41 @@
+package tests
+
+import (
+	"order_service/internal/models"
+	"order_service/pkg/pkgports/adapters/receiver"
+	"testing"
+)
+
+func TestDecodeJSONLenientIgnoresUnknownFields(t *testing.T) {
+	order, err := receiver.DecodeJSON[models.Order]([]byte(`{"order_uid":"a","unknown":1}`), false)
+	if err != nil {
+		t.Fatalf("Expected lenient decoding to succeed, got %v", err)
+	}
+	if order.OrderUID != "a" {
+		t.Errorf("Expected order_uid a, got %q", order.OrderUID)
+	}
+}
+
+func TestDecodeJSONStrict(t *testing.T) {
+	cases := map[string]string{
+		"unknown field": `{"order_uid":"a","unknown":1}`,
+		"trailing data": `{"order_uid":"a"} {"order_uid":"b"}`,
+		"extra brace":   `{"order_uid":"a"}}`,
+		"extra bracket": `{"order_uid":"a"}]`,
+		"trailing junk": `{"order_uid":"a"} x`,
+		"not json":      `not json at all`,
+		"empty":         ``,
+	}
+	for name, payload := range cases {
+		t.Run(name, func(t *testing.T) {
+			if _, err := receiver.DecodeJSON[models.Order]([]byte(payload), true); err == nil {
+				t.Error("Expected strict decoding to fail")
+			}
+		})
+	}
+
+	// surrounding whitespace is fine
+	if _, err := receiver.DecodeJSON[models.Order]([]byte(` {"order_uid":"a"}`+"\n"), true); err != nil {
+		t.Errorf("Expected known fields to be decoded, got %v", err)
+	}
+}
@@ -0,0 +1,96 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"order_service/pkg/delayqueue"
+	"testing"
+	"time"
+)
+
+func TestDelayQueuePopsInReadyOrder(t *testing.T) {
+	queue := delayqueue.NewDelayQueue[int](10)
+	now := time.Now()
+
+	// pushed out of order, the same ready time keeps push order
+	_ = queue.Push(3, now.Add(-time.Millisecond))
+	_ = queue.Push(1, now.Add(-3*time.Millisecond))
+	_ = queue.Push(2, now.Add(-2*time.Millisecond))
+	_ = queue.Push(4, now.Add(-time.Millisecond))
+
+	for _, expected := range []int{1, 2, 3, 4} {
+		value, ok := queue.PopReady()
+		if !ok {
+			t.Fatalf("Expected %d to be ready", expected)
+		}
+		if value != expected {
+			t.Errorf("Expected %d, got %d", expected, value)
+		}
+	}
+	if queue.Len() != 0 {
+		t.Errorf("Expected empty queue, got depth %d", queue.Len())
+	}
+}
+
+func TestDelayQueueNotReadyValueStays(t *testing.T) {
+	queue := delayqueue.NewDelayQueue[string](10)
+
+	_ = queue.Push("later", time.Now().Add(time.Hour))
+
+	if _, ok := queue.PopReady(); ok {
+		t.Error("Expected value not to be ready")
+	}
+	if queue.Len() != 1 {
+		t.Errorf("Expected depth 1, got %d", queue.Len())
+	}
+}
+
+func TestDelayQueueFull(t *testing.T) {
+	queue := delayqueue.NewDelayQueue[int](1)
+
+	if err := queue.Push(1, time.Now()); err != nil {
+		t.Fatalf("Push failed: %v", err)
+	}
+	if err := queue.Push(2, time.Now()); !errors.Is(err, delayqueue.ErrFull) {
+		t.Errorf("Expected ErrFull, got %v", err)
+	}
+}
+
+func TestDelayQueueWaitReadyWakesOnEarlierPush(t *testing.T) {
+	queue := delayqueue.NewDelayQueue[int](10)
+	_ = queue.Push(1, time.Now().Add(time.Hour))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	waitErr := make(chan error, 1)
+	go func() {
+		waitErr <- queue.WaitReady(ctx)
+	}()
+
+	start := time.Now()
+	_ = queue.Push(2, start.Add(50*time.Millisecond))
+
+	if err := <-waitErr; err != nil {
+		t.Fatalf("WaitReady failed: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond || elapsed > time.Second {
+		t.Errorf("Expected to wake up in ~50ms, woke up in %v", elapsed)
+	}
+
+	value, ok := queue.PopReady()
+	if !ok || value != 2 {
+		t.Errorf("Expected 2 to be ready, got %d, %v", value, ok)
+	}
+}
+
+func TestDelayQueueWaitReadyCancelled(t *testing.T) {
+	queue := delayqueue.NewDelayQueue[int](10)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	if err := queue.WaitReady(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected context.DeadlineExceeded, got %v", err)
+	}
+}
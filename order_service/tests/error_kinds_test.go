@@ -0,0 +1,90 @@
+package tests
+
+import (
+	"errors"
+	"fmt"
+	"order_service/internal/custom_errors"
+	"order_service/internal/ports/adapters/storage"
+	"testing"
+)
+
+func TestErrorKinds(t *testing.T) {
+	cause := errors.New("boom")
+
+	cases := []struct {
+		name      string
+		err       error
+		kind      customerrors.Kind
+		retryable bool
+	}{
+		{"unknown", cause, customerrors.KindUnknown, true},
+		{"transient", customerrors.NewClassifiedError(customerrors.KindTransient, "40P01", cause), customerrors.KindTransient, true},
+		{"permanent", customerrors.NewClassifiedError(customerrors.KindPermanent, "23514", cause), customerrors.KindPermanent, false},
+		{"conflict", customerrors.NewClassifiedError(customerrors.KindConflict, "23505", cause), customerrors.KindConflict, false},
+		{"order conflict", fmt.Errorf("%w: uid", customerrors.ErrOrderConflict), customerrors.KindConflict, false},
+		{
+			"wrapped permanent",
+			fmt.Errorf("error saving order: %w", customerrors.NewClassifiedError(customerrors.KindPermanent, "22001", cause)),
+			customerrors.KindPermanent, false,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if kind := customerrors.KindOf(c.err); kind != c.kind {
+				t.Errorf("Expected kind %s, got %s", c.kind, kind)
+			}
+			if retryable := customerrors.IsRetryable(c.err); retryable != c.retryable {
+				t.Errorf("Expected retryable %v, got %v", c.retryable, retryable)
+			}
+		})
+	}
+
+	// the cause is still reachable
+	if !errors.Is(cases[2].err, cause) {
+		t.Error("Expected classified error to unwrap to its cause")
+	}
+}
+
+func TestClassifySQLState(t *testing.T) {
+	cases := []struct {
+		code string
+		kind customerrors.Kind
+	}{
+		// class 08, connection_exception
+		{"08000", customerrors.KindTransient},
+		{"08006", customerrors.KindTransient},
+		// class 22, data_exception
+		{"22001", customerrors.KindPermanent},
+		{"22003", customerrors.KindPermanent},
+		// class 23, integrity_constraint_violation, a duplicate is a conflict
+		{"23505", customerrors.KindConflict},
+		{"23502", customerrors.KindPermanent},
+		{"23514", customerrors.KindPermanent},
+		// class 40, transaction_rollback
+		{"40001", customerrors.KindTransient},
+		{"40P01", customerrors.KindTransient},
+		{"40002", customerrors.KindUnknown},
+		// class 42, syntax_error_or_access_rule_violation, undefined objects are expected during migrations
+		{"42P01", customerrors.KindTransient},
+		{"42703", customerrors.KindTransient},
+		{"42601", customerrors.KindPermanent},
+		{"42501", customerrors.KindPermanent},
+		// class 53, insufficient_resources
+		{"53300", customerrors.KindTransient},
+		// class 55, object_not_in_prerequisite_state
+		{"55P03", customerrors.KindTransient},
+		{"55000", customerrors.KindUnknown},
+		// class 57, operator_intervention
+		{"57P01", customerrors.KindTransient},
+		// other classes aren't known
+		{"XX000", customerrors.KindUnknown},
+		{"", customerrors.KindUnknown},
+	}
+
+	for _, c := range cases {
+		if kind := storage.ClassifySQLState(c.code); kind != c.kind {
+			t.Errorf("%q: expected kind %s, got %s", c.code, c.kind, kind)
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package tests
+
+import (
+	"encoding/base64"
+	"order_service/internal/api"
+	"order_service/internal/handlers/httphandlers"
+	"order_service/internal/models"
+	"testing"
+	"time"
+)
+
+func TestOrdersGetCursorRoundTrip(t *testing.T) {
+	cases := []struct {
+		name   string
+		cursor models.OrdersCursor
+	}{
+		{"nanoseconds", models.OrdersCursor{
+			CreatedAt: time.Date(2024, 5, 17, 10, 30, 15, 123456789, time.UTC),
+			OrderUID:  "b563feb7b2b84b6test",
+		}},
+		{"another time zone", models.OrdersCursor{
+			CreatedAt: time.Date(2024, 5, 17, 10, 30, 15, 0, time.FixedZone("MSK", 3*60*60)),
+			OrderUID:  "b563feb7b2b84b6test",
+		}},
+		{"separator in order_uid", models.OrdersCursor{
+			CreatedAt: time.Date(2024, 5, 17, 10, 30, 15, 1, time.UTC),
+			OrderUID:  "first|second",
+		}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ctx, orderService, storage, _ := newTestOrderService(t)
+			handler := httphandlers.NewOrderServiceHTTPHandler(orderService, nil)
+			storage.page = models.OrdersPage{NextCursor: &c.cursor}
+
+			res, err := handler.OrdersGet(ctx, api.OrdersGetParams{})
+			if err != nil {
+				t.Fatalf("Expected orders to be listed, got error: %v", err)
+			}
+			page, ok := res.(*api.OrderListResponse)
+			if !ok {
+				t.Fatalf("Expected a page of orders, got %T: %+v", res, res)
+			}
+			nextCursor, ok := page.NextCursor.Get()
+			if !ok {
+				t.Fatal("Expected the next cursor")
+			}
+
+			// the issued cursor is decoded into the same one
+			_, err = handler.OrdersGet(ctx, api.OrdersGetParams{Cursor: api.NewOptString(nextCursor)})
+			if err != nil {
+				t.Fatalf("Expected orders to be listed, got error: %v", err)
+			}
+			after := storage.listed[len(storage.listed)-1].After
+			if after == nil || !after.CreatedAt.Equal(c.cursor.CreatedAt) || after.OrderUID != c.cursor.OrderUID {
+				t.Errorf("Expected cursor %+v, got %+v", c.cursor, after)
+			}
+		})
+	}
+}
+
+func TestOrdersGetMalformedCursor(t *testing.T) {
+	encode := func(raw string) string {
+		return base64.RawURLEncoding.EncodeToString([]byte(raw))
+	}
+
+	cases := []struct {
+		name   string
+		cursor string
+	}{
+		{"not base64", "not a cursor!"},
+		{"no separator", encode("2024-05-17T10:30:15Z")},
+		{"no order_uid", encode("2024-05-17T10:30:15Z|")},
+		{"not a time", encode("yesterday|uid")},
+		{"empty", ""},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ctx, orderService, storage, _ := newTestOrderService(t)
+			handler := httphandlers.NewOrderServiceHTTPHandler(orderService, nil)
+
+			res, err := handler.OrdersGet(ctx, api.OrdersGetParams{Cursor: api.NewOptString(c.cursor)})
+			if err != nil {
+				t.Fatalf("Expected a bad request response, got error: %v", err)
+			}
+			if _, ok := res.(*api.BadRequestErrorResponse); !ok {
+				t.Errorf("Expected a bad request response, got %T: %+v", res, res)
+			}
+			if len(storage.listed) != 0 {
+				t.Errorf("Expected the storage not to be queried, got filters %+v", storage.listed)
+			}
+		})
+	}
+}
+
+func TestOrdersGetDateCreatedRange(t *testing.T) {
+	from := time.Date(2024, 5, 17, 0, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		name       string
+		to         time.Time
+		badRequest bool
+	}{
+		{"to after from", from.Add(time.Second), false},
+		{"to equals from", from, true},
+		{"to before from", from.Add(-time.Hour), true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ctx, orderService, storage, _ := newTestOrderService(t)
+			handler := httphandlers.NewOrderServiceHTTPHandler(orderService, nil)
+
+			res, err := handler.OrdersGet(ctx, api.OrdersGetParams{
+				DateCreatedFrom: api.NewOptDateTime(from),
+				DateCreatedTo:   api.NewOptDateTime(c.to),
+			})
+			if err != nil {
+				t.Fatalf("Expected a response, got error: %v", err)
+			}
+
+			_, badRequest := res.(*api.BadRequestErrorResponse)
+			if badRequest != c.badRequest {
+				t.Fatalf("Expected bad request %v, got %T: %+v", c.badRequest, res, res)
+			}
+			if badRequest {
+				if len(storage.listed) != 0 {
+					t.Errorf("Expected the storage not to be queried, got filters %+v", storage.listed)
+				}
+				return
+			}
+			filter := storage.listed[0]
+			if filter.DateCreatedFrom == nil || !filter.DateCreatedFrom.Equal(from) ||
+				filter.DateCreatedTo == nil || !filter.DateCreatedTo.Equal(c.to) {
+				t.Errorf("Expected range from %v to %v, got %+v", from, c.to, filter)
+			}
+		})
+	}
+}
@@ -0,0 +1,169 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	"order_service/pkg/linkedlist"
+	"order_service/pkg/logger"
+	"order_service/pkg/pkgports/adapters/cache/lru"
+	"testing"
+)
+
+// legacyCacheLRU is the previous CacheLRUInMemory: a map of values and a singly linked list of keys,
+// Get and Set look the key up in the list with a linear scan. It's kept for benchmarks only
+type legacyCacheLRU struct {
+	data     map[int]int
+	keysList linkedlist.LinkedList[int]
+	cap      int
+}
+
+func newLegacyCacheLRU(capacity int) *legacyCacheLRU {
+	return &legacyCacheLRU{
+		data:     make(map[int]int),
+		keysList: linkedlist.NewLinkedList[int](),
+		cap:      capacity,
+	}
+}
+
+func (c *legacyCacheLRU) Get(_ context.Context, key int) (int, bool, error) {
+	value, ok := c.data[key]
+	if !ok {
+		return 0, false, nil
+	}
+	index, err := c.keysList.GetIndex(key, func(a, b int) bool { return a == b })
+	if err != nil {
+		return 0, false, err
+	}
+	return value, true, c.keysList.MoveToFirst(index)
+}
+
+func (c *legacyCacheLRU) Set(_ context.Context, key int, value int) error {
+	index, _ := c.keysList.GetIndex(key, func(a, b int) bool { return a == b })
+	if index != -1 {
+		if err := c.keysList.RemoveAt(index); err != nil {
+			return err
+		}
+	}
+	if err := c.keysList.Insert(key, 0); err != nil {
+		return err
+	}
+	c.data[key] = value
+
+	if c.keysList.Len() > c.cap {
+		last, err := c.keysList.GetLast()
+		if err != nil {
+			return err
+		}
+		if err = c.keysList.RemoveLast(); err != nil {
+			return err
+		}
+		delete(c.data, last)
+	}
+	return nil
+}
+
+// fill puts keys 0..cap-1 without scanning, Set would take O(cap^2) at 1M
+func (c *legacyCacheLRU) fill() {
+	for key := 0; key < c.cap; key++ {
+		_ = c.keysList.Insert(key, 0)
+		c.data[key] = key
+	}
+}
+
+// benchCache is the part of both caches that is benchmarked
+type benchCache interface {
+	Get(ctx context.Context, key int) (int, bool, error)
+	Set(ctx context.Context, key int, value int) error
+}
+
+var benchCapacities = []int{1_000, 10_000, 100_000, 1_000_000}
+
+// benchCacheNames are the benchmarked implementations, see newBenchCache
+var benchCacheNames = []string{"legacy", "map+dll"}
+
+// newBenchCache returns a filled cache of given implementation and capacity, keys are 0..capacity-1
+func newBenchCache(ctx context.Context, b *testing.B, name string, capacity int) benchCache {
+	b.Helper()
+
+	if name == "legacy" {
+		legacy := newLegacyCacheLRU(capacity)
+		legacy.fill()
+		return legacy
+	}
+
+	current := lru.NewCacheLRUInMemory[int, int](capacity)
+	for key := 0; key < capacity; key++ {
+		if err := current.Set(ctx, key, key); err != nil {
+			b.Fatalf("Set failed: %v", err)
+		}
+	}
+	return current
+}
+
+// newBenchContext returns a context with a logger, so the caches don't create one on every call
+func newBenchContext(b *testing.B) context.Context {
+	b.Helper()
+
+	ctx, err := logger.New(context.Background())
+	if err != nil {
+		b.Fatalf("Error creating logger for benchmark: %v", err)
+	}
+	return ctx
+}
+
+// BenchmarkLRUGetHit reads stored keys spread over the whole list
+func BenchmarkLRUGetHit(b *testing.B) {
+	ctx := newBenchContext(b)
+	for _, capacity := range benchCapacities {
+		for _, name := range benchCacheNames {
+			b.Run(fmt.Sprintf("%s/cap=%d", name, capacity), func(b *testing.B) {
+				cache := newBenchCache(ctx, b, name, capacity)
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					// a big prime step visits keys far from the top
+					if _, _, err := cache.Get(ctx, (i*7919)%capacity); err != nil {
+						b.Fatalf("Get failed: %v", err)
+					}
+				}
+			})
+		}
+	}
+}
+
+// BenchmarkLRUSetEvict writes new keys, every Set evicts the least used one
+func BenchmarkLRUSetEvict(b *testing.B) {
+	ctx := newBenchContext(b)
+	for _, capacity := range benchCapacities {
+		for _, name := range benchCacheNames {
+			b.Run(fmt.Sprintf("%s/cap=%d", name, capacity), func(b *testing.B) {
+				cache := newBenchCache(ctx, b, name, capacity)
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					if err := cache.Set(ctx, capacity+i, i); err != nil {
+						b.Fatalf("Set failed: %v", err)
+					}
+				}
+			})
+		}
+	}
+}
+
+// BenchmarkLRUGetParallel reads stored keys from all the Ps at once, legacy isn't safe for it
+func BenchmarkLRUGetParallel(b *testing.B) {
+	ctx := newBenchContext(b)
+	for _, capacity := range benchCapacities {
+		b.Run(fmt.Sprintf("map+dll/cap=%d", capacity), func(b *testing.B) {
+			cache := newBenchCache(ctx, b, "map+dll", capacity)
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				i := 0
+				for pb.Next() {
+					if _, _, err := cache.Get(ctx, (i*7919)%capacity); err != nil {
+						b.Fatalf("Get failed: %v", err)
+					}
+					i++
+				}
+			})
+		})
+	}
+}
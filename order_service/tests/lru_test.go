@@ -2,9 +2,15 @@ package tests
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"order_service/pkg/logger"
+	"order_service/pkg/pkgports"
 	"order_service/pkg/pkgports/adapters/cache/lru"
+	"runtime"
+	"sync"
 	"testing"
+	"time"
 )
 
 func TestNewCacheLRUInMemory(t *testing.T) {
@@ -381,3 +387,417 @@ func TestKeysOrder(t *testing.T) {
 		t.Errorf("LeastUsedKey failed: incorrect order, expected key1, got: %v", key)
 	}
 }
+
+func TestCacheConcurrentStress(t *testing.T) {
+	const capacity = 64
+	const keysAmount = 256
+	const readers = 32
+	const writers = 4
+	const operations = 2000
+
+	cache := lru.NewCacheLRUInMemory[string, int](capacity)
+	ctx, err := logger.New(context.Background())
+	if err != nil {
+		t.Fatalf("Error creating logger for test: %v", err)
+	}
+
+	// the value of a key is always derived from it, so a torn or misplaced value is noticed
+	keys := make([]string, keysAmount)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("key%d", i)
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, readers+writers)
+
+	for w := 0; w < writers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < operations; i++ {
+				n := (i*31 + w*7) % keysAmount
+				if setErr := cache.Set(ctx, keys[n], n); setErr != nil {
+					errs <- fmt.Errorf("set failed: %w", setErr)
+					return
+				}
+			}
+		}()
+	}
+	for r := 0; r < readers; r++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < operations; i++ {
+				n := (i*17 + r) % keysAmount
+				value, found, getErr := cache.Get(ctx, keys[n])
+				if getErr != nil {
+					errs <- fmt.Errorf("get failed: %w", getErr)
+					return
+				}
+				if found && value != n {
+					errs <- fmt.Errorf("expected value %d for %s, got %d", n, keys[n], value)
+					return
+				}
+				// readers look at the order too
+				if i%100 == 0 {
+					_ = cache.GetKeys()
+					_, _ = cache.MostUsedKey()
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+	close(errs)
+	for err = range errs {
+		t.Error(err)
+	}
+
+	// the list and the map still agree
+	keysList := cache.GetKeys()
+	if len(keysList) != cache.GetKeysAmount() {
+		t.Errorf("Expected %d listed keys, got %d", cache.GetKeysAmount(), len(keysList))
+	}
+	if len(keysList) > capacity {
+		t.Errorf("Expected at most %d keys, got %d", capacity, len(keysList))
+	}
+	seen := make(map[string]struct{}, len(keysList))
+	for _, key := range keysList {
+		if _, ok := seen[key]; ok {
+			t.Errorf("Key %s is listed twice", key)
+		}
+		seen[key] = struct{}{}
+		if _, found, _ := cache.Get(ctx, key); !found {
+			t.Errorf("Listed key %s isn't stored", key)
+		}
+	}
+}
+
+func TestCacheReadBeforeEviction(t *testing.T) {
+	// a read must protect the key from the next eviction even if its promotion is buffered
+	cache := lru.NewCacheLRUInMemory[string, int](2)
+	ctx, err := logger.New(context.Background())
+	if err != nil {
+		t.Fatalf("Error creating logger for test: %v", err)
+	}
+
+	_ = cache.Set(ctx, "key1", 1)
+	_ = cache.Set(ctx, "key2", 2)
+	// many reads, more than the buffer holds
+	for i := 0; i < 1000; i++ {
+		_, _, _ = cache.Get(ctx, "key1")
+	}
+	_ = cache.Set(ctx, "key3", 3)
+
+	if _, found, _ := cache.Get(ctx, "key1"); !found {
+		t.Error("Key1 was read last, it should be in cache")
+	}
+	if _, found, _ := cache.Get(ctx, "key2"); found {
+		t.Error("Key2 should have been evicted")
+	}
+}
+
+func TestCacheTTLExpiresOnRead(t *testing.T) {
+	cache := lru.NewCacheLRUInMemoryWithTTL[string, int](3, 20*time.Millisecond, 0)
+	ctx, err := logger.New(context.Background())
+	if err != nil {
+		t.Fatalf("Error creating logger for test: %v", err)
+	}
+
+	_ = cache.Set(ctx, "key1", 1)
+	// per-entry TTL overrides the default one
+	_ = cache.SetWithTTL(ctx, "key2", 2, time.Hour)
+	_ = cache.SetWithTTL(ctx, "key3", 3, 0)
+
+	if _, found, _ := cache.Get(ctx, "key1"); !found {
+		t.Error("Key1 isn't expired yet, it should be in cache")
+	}
+
+	time.Sleep(40 * time.Millisecond)
+
+	if _, found, _ := cache.Get(ctx, "key1"); found {
+		t.Error("Key1 should have expired")
+	}
+	if value, found, _ := cache.Get(ctx, "key2"); !found || value != 2 {
+		t.Errorf("Expected key2 with its own TTL to be 2, got %d, found %v", value, found)
+	}
+	if value, found, _ := cache.Get(ctx, "key3"); !found || value != 3 {
+		t.Errorf("Expected key3 without TTL to be 3, got %d, found %v", value, found)
+	}
+	// the expired value is removed by the read
+	if cache.GetKeysAmount() != 2 {
+		t.Errorf("Expected 2 keys after expiry, got %d", cache.GetKeysAmount())
+	}
+}
+
+func TestCacheExpiredReadKeepsRenewedValue(t *testing.T) {
+	const readers = 4
+	const operations = 2000
+
+	cache := lru.NewCacheLRUInMemory[string, int](3)
+	ctx, err := logger.New(context.Background())
+	if err != nil {
+		t.Fatalf("Error creating logger for test: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for r := 0; r < readers; r++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < operations; i++ {
+				_, _, _ = cache.Get(ctx, "key")
+				runtime.Gosched()
+			}
+		}()
+	}
+	readersDone := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(readersDone)
+	}()
+
+	// readers find the value expired and remove it, unless it's renewed meanwhile
+	renewed, renewedRemoved := 0, 0
+	for {
+		select {
+		case <-readersDone:
+			if renewedRemoved > 0 {
+				t.Errorf("Expected renewed values to be kept, %d of %d were removed as expired",
+					renewedRemoved, renewed)
+			}
+			return
+		default:
+		}
+
+		_ = cache.SetWithTTL(ctx, "key", renewed, time.Nanosecond)
+		// let the readers find it expired
+		runtime.Gosched()
+		_ = cache.SetWithTTL(ctx, "key", renewed, time.Hour)
+		renewed++
+		// and let them finish the removal
+		runtime.Gosched()
+		if _, found, _ := cache.Peek(ctx, "key"); !found {
+			renewedRemoved++
+		}
+	}
+}
+
+func TestCacheTTLRenewedBySet(t *testing.T) {
+	cache := lru.NewCacheLRUInMemoryWithTTL[string, int](3, time.Hour, 0)
+	ctx, err := logger.New(context.Background())
+	if err != nil {
+		t.Fatalf("Error creating logger for test: %v", err)
+	}
+
+	_ = cache.SetWithTTL(ctx, "key1", 1, 20*time.Millisecond)
+	_ = cache.Set(ctx, "key1", 2)
+	time.Sleep(40 * time.Millisecond)
+
+	if value, found, _ := cache.Get(ctx, "key1"); !found || value != 2 {
+		t.Errorf("Expected key1 to be 2 with the default TTL, got %d, found %v", value, found)
+	}
+}
+
+func TestCacheExpirySweep(t *testing.T) {
+	cache := lru.NewCacheLRUInMemoryWithTTL[string, int](10, 10*time.Millisecond, 5*time.Millisecond)
+	ctx, err := logger.New(context.Background())
+	if err != nil {
+		t.Fatalf("Error creating logger for test: %v", err)
+	}
+
+	for i := 0; i < 5; i++ {
+		_ = cache.Set(ctx, fmt.Sprintf("key%d", i), i)
+	}
+	_ = cache.SetWithTTL(ctx, "kept", 5, 0)
+
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		_ = cache.StartExpiring(ctx)
+	}()
+
+	// nothing is read, so only the sweep removes the values
+	deadline := time.Now().Add(5 * time.Second)
+	for cache.GetKeysAmount() > 1 {
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected expired values to be removed, %d keys left", cache.GetKeysAmount())
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	cache.StopExpiring(ctx)
+	<-stopped
+
+	if keys := cache.GetKeys(); len(keys) != 1 || keys[0] != "kept" {
+		t.Errorf("Expected only the key without TTL, got %v", keys)
+	}
+}
+
+func TestCachePeekKeepsOrder(t *testing.T) {
+	cache := lru.NewCacheLRUInMemory[string, int](2)
+	ctx, err := logger.New(context.Background())
+	if err != nil {
+		t.Fatalf("Error creating logger for test: %v", err)
+	}
+
+	_ = cache.Set(ctx, "key1", 1)
+	_ = cache.Set(ctx, "key2", 2)
+
+	if value, found, _ := cache.Peek(ctx, "key1"); !found || value != 1 {
+		t.Errorf("Expected key1 to be 1, got %d, found %v", value, found)
+	}
+	if _, found, _ := cache.Peek(ctx, "key3"); found {
+		t.Error("Key3 isn't stored, it shouldn't be found")
+	}
+
+	// key1 is still the least used one
+	_ = cache.Set(ctx, "key3", 3)
+	if _, found, _ := cache.Peek(ctx, "key1"); found {
+		t.Error("Key1 was only peeked, it should have been evicted")
+	}
+}
+
+func TestCacheDelete(t *testing.T) {
+	cache := lru.NewCacheLRUInMemory[string, int](3)
+	ctx, err := logger.New(context.Background())
+	if err != nil {
+		t.Fatalf("Error creating logger for test: %v", err)
+	}
+
+	_ = cache.Set(ctx, "key1", 1)
+	_ = cache.Set(ctx, "key2", 2)
+	// a buffered read of a deleted key must not bring it back
+	_, _, _ = cache.Get(ctx, "key1")
+
+	if deleted, _ := cache.Delete(ctx, "key1"); !deleted {
+		t.Error("Expected key1 to be deleted")
+	}
+	if deleted, _ := cache.Delete(ctx, "key1"); deleted {
+		t.Error("Key1 is already deleted, nothing should be deleted twice")
+	}
+	if _, found, _ := cache.Get(ctx, "key1"); found {
+		t.Error("Key1 should be deleted")
+	}
+
+	keys := cache.GetKeys()
+	if len(keys) != 1 || keys[0] != "key2" || cache.GetKeysAmount() != 1 {
+		t.Errorf("Expected only key2 left, got %v", keys)
+	}
+}
+
+func TestCacheClear(t *testing.T) {
+	cache := lru.NewCacheLRUInMemory[string, int](3)
+	ctx, err := logger.New(context.Background())
+	if err != nil {
+		t.Fatalf("Error creating logger for test: %v", err)
+	}
+
+	_ = cache.Set(ctx, "key1", 1)
+	_ = cache.Set(ctx, "key2", 2)
+	_, _, _ = cache.Get(ctx, "key1")
+
+	if err = cache.Clear(ctx); err != nil {
+		t.Fatalf("Expected cache to be cleared, got error: %v", err)
+	}
+	if cache.GetKeysAmount() != 0 || len(cache.GetKeys()) != 0 {
+		t.Errorf("Expected empty cache, got %v", cache.GetKeys())
+	}
+	if _, err = cache.MostUsedKey(); !errors.Is(err, lru.ErrEmptyCache) {
+		t.Errorf("Expected empty cache error, got %v", err)
+	}
+
+	// it's usable after
+	_ = cache.Set(ctx, "key3", 3)
+	if value, found, _ := cache.Get(ctx, "key3"); !found || value != 3 {
+		t.Errorf("Expected key3 to be 3, got %d, found %v", value, found)
+	}
+}
+
+func TestCacheStats(t *testing.T) {
+	cache := lru.NewCacheLRUInMemoryWithTTL[string, int](2, 0, 0)
+	ctx, err := logger.New(context.Background())
+	if err != nil {
+		t.Fatalf("Error creating logger for test: %v", err)
+	}
+
+	_ = cache.Set(ctx, "key1", 1)
+	_ = cache.Set(ctx, "key2", 2)
+	_ = cache.Set(ctx, "key2", 22)
+	_ = cache.Set(ctx, "key3", 3)
+	_ = cache.SetWithTTL(ctx, "key4", 4, time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+
+	_, _, _ = cache.Get(ctx, "key3")
+	_, _, _ = cache.Get(ctx, "key1")
+	_, _, _ = cache.Get(ctx, "key4")
+	// peeks aren't counted
+	_, _, _ = cache.Peek(ctx, "key3")
+	_, _, _ = cache.Peek(ctx, "key1")
+
+	expected := pkgports.CacheStats{
+		Hits:      1,
+		Misses:    2,
+		Sets:      5,
+		Evictions: 2,
+		Expired:   1,
+		Size:      1,
+		Capacity:  2,
+	}
+	if stats := cache.Stats(); stats != expected {
+		t.Errorf("Expected stats %+v, got %+v", expected, stats)
+	}
+}
+
+// testGenerationCache checks that a value read before an invalidation of its key isn't set
+func testGenerationCache(t *testing.T, ctx context.Context, cache pkgports.GenerationCache[int, string]) {
+	t.Helper()
+
+	generation, err := cache.Generation(ctx, 1)
+	if err != nil {
+		t.Fatalf("Expected generation, got error: %v", err)
+	}
+	if set, err := cache.SetIfGeneration(ctx, 1, "first", generation); err != nil || !set {
+		t.Fatalf("Expected value of the current generation to be set, got set %v, error: %v", set, err)
+	}
+
+	// the value is loaded with this generation, the key is invalidated meanwhile
+	generation, _ = cache.Generation(ctx, 1)
+	if deleted, err := cache.Invalidate(ctx, 1); err != nil || !deleted {
+		t.Fatalf("Expected value to be invalidated, got deleted %v, error: %v", deleted, err)
+	}
+	if set, err := cache.SetIfGeneration(ctx, 1, "stale", generation); err != nil || set {
+		t.Fatalf("Expected value of the previous generation to be skipped, got set %v, error: %v", set, err)
+	}
+	if _, found, _ := cache.Peek(ctx, 1); found {
+		t.Fatal("Expected nothing to be cached after the invalidation")
+	}
+
+	// the next load is of the new generation
+	generation, _ = cache.Generation(ctx, 1)
+	if set, err := cache.SetIfGeneration(ctx, 1, "fresh", generation); err != nil || !set {
+		t.Fatalf("Expected value of the new generation to be set, got set %v, error: %v", set, err)
+	}
+	if value, found, _ := cache.Peek(ctx, 1); !found || value != "fresh" {
+		t.Errorf("Expected fresh value, got %q, found %v", value, found)
+	}
+
+	// invalidating a missing key bumps its generation too
+	generation, _ = cache.Generation(ctx, 2)
+	if deleted, _ := cache.Invalidate(ctx, 2); deleted {
+		t.Error("Expected nothing to be deleted")
+	}
+	if set, _ := cache.SetIfGeneration(ctx, 2, "stale", generation); set {
+		t.Error("Expected value of the previous generation to be skipped")
+	}
+}
+
+func TestCacheGenerations(t *testing.T) {
+	cache := lru.NewCacheLRUInMemory[int, string](10)
+	ctx, err := logger.New(context.Background())
+	if err != nil {
+		t.Fatalf("Error creating logger for test: %v", err)
+	}
+
+	testGenerationCache(t, ctx, cache)
+}
@@ -0,0 +1,130 @@
+package tests
+
+import (
+	"github.com/segmentio/kafka-go"
+	"order_service/pkg/pkgports/adapters/receiver"
+	"sync"
+	"testing"
+)
+
+func kafkaMessage(partition int, offset int64) kafka.Message {
+	return kafka.Message{Topic: "orders", Partition: partition, Offset: offset}
+}
+
+func TestOffsetTrackerCommitsContiguousPrefix(t *testing.T) {
+	tracker := receiver.NewOffsetTracker()
+	for offset := int64(0); offset < 4; offset++ {
+		tracker.Track(kafkaMessage(0, offset))
+	}
+
+	// offset 0 is still in-flight, nothing can be committed
+	if _, ok := tracker.Complete(kafkaMessage(0, 2)); ok {
+		t.Error("Expected no commit while offset 0 is in-flight")
+	}
+	if _, ok := tracker.Complete(kafkaMessage(0, 1)); ok {
+		t.Error("Expected no commit while offset 0 is in-flight")
+	}
+
+	toCommit, ok := tracker.Complete(kafkaMessage(0, 0))
+	if !ok || toCommit.Offset != 2 {
+		t.Errorf("Expected to commit offset 2, got %d, %v", toCommit.Offset, ok)
+	}
+
+	toCommit, ok = tracker.Complete(kafkaMessage(0, 3))
+	if !ok || toCommit.Offset != 3 {
+		t.Errorf("Expected to commit offset 3, got %d, %v", toCommit.Offset, ok)
+	}
+	if tracker.InFlight() != 0 {
+		t.Errorf("Expected nothing in-flight, got %d", tracker.InFlight())
+	}
+}
+
+func TestOffsetTrackerPartitionsAreIndependent(t *testing.T) {
+	tracker := receiver.NewOffsetTracker()
+	tracker.Track(kafkaMessage(0, 10))
+	tracker.Track(kafkaMessage(1, 20))
+	tracker.Track(kafkaMessage(1, 21))
+
+	// a stuck partition 0 doesn't block partition 1
+	toCommit, ok := tracker.Complete(kafkaMessage(1, 20))
+	if !ok || toCommit.Partition != 1 || toCommit.Offset != 20 {
+		t.Errorf("Expected to commit partition 1 offset 20, got %d/%d, %v", toCommit.Partition, toCommit.Offset, ok)
+	}
+	if tracker.InFlight() != 2 {
+		t.Errorf("Expected 2 in-flight, got %d", tracker.InFlight())
+	}
+}
+
+func TestOffsetTrackerIgnoresUnknownAndDuplicates(t *testing.T) {
+	tracker := receiver.NewOffsetTracker()
+	tracker.Track(kafkaMessage(0, 5))
+	tracker.Track(kafkaMessage(0, 5)) // redelivery after a rebalance
+
+	if _, ok := tracker.Complete(kafkaMessage(0, 6)); ok {
+		t.Error("Expected unknown offset not to be committed")
+	}
+	if _, ok := tracker.Complete(kafkaMessage(0, 5)); !ok {
+		t.Error("Expected offset 5 to be committed")
+	}
+	if _, ok := tracker.Complete(kafkaMessage(0, 5)); ok {
+		t.Error("Expected second completion to be ignored")
+	}
+}
+
+func TestOffsetTrackerResetsReassignedPartition(t *testing.T) {
+	tracker := receiver.NewOffsetTracker()
+	for offset := int64(0); offset < 4; offset++ {
+		tracker.Track(kafkaMessage(0, offset))
+	}
+	tracker.Track(kafkaMessage(1, 0))
+	_, _ = tracker.Complete(kafkaMessage(0, 2))
+
+	// partition 0 is assigned again and read from the committed offset 1, offset 0 isn't waited for anymore
+	tracker.Track(kafkaMessage(0, 1))
+	if tracker.InFlight() != 2 {
+		t.Errorf("Expected 2 in-flight, got %d", tracker.InFlight())
+	}
+	if _, ok := tracker.Complete(kafkaMessage(0, 0)); ok {
+		t.Error("Expected offset 0 of the old assignment to be ignored")
+	}
+	toCommit, ok := tracker.Complete(kafkaMessage(0, 1))
+	if !ok || toCommit.Offset != 1 {
+		t.Errorf("Expected to commit offset 1, got %d, %v", toCommit.Offset, ok)
+	}
+
+	// other partitions aren't touched
+	if _, ok = tracker.Complete(kafkaMessage(1, 0)); !ok {
+		t.Error("Expected partition 1 to be committed")
+	}
+}
+
+func TestOffsetTrackerConcurrentCompletion(t *testing.T) {
+	tracker := receiver.NewOffsetTracker()
+	const total = 1000
+	for offset := int64(0); offset < total; offset++ {
+		tracker.Track(kafkaMessage(0, offset))
+	}
+
+	var mu sync.Mutex
+	var highest int64 = -1
+	var wg sync.WaitGroup
+	for offset := int64(total - 1); offset >= 0; offset-- {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if toCommit, ok := tracker.Complete(kafkaMessage(0, offset)); ok {
+				mu.Lock()
+				highest = max(highest, toCommit.Offset)
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if highest != total-1 {
+		t.Errorf("Expected the last commit to be %d, got %d", total-1, highest)
+	}
+	if tracker.InFlight() != 0 {
+		t.Errorf("Expected nothing in-flight, got %d", tracker.InFlight())
+	}
+}
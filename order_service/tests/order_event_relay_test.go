@@ -0,0 +1,231 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"order_service/internal/models"
+	"order_service/internal/service"
+	"order_service/pkg/logger"
+	"order_service/pkg/pkgports/adapters/backoff"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeOrderEventStorage is an in-memory outbox, markFailures first MarkOrderEventsSent calls fail
+type fakeOrderEventStorage struct {
+	mu           sync.Mutex
+	events       []models.OrderEvent
+	lockedUntil  map[int64]time.Time
+	markFailures int
+}
+
+func (s *fakeOrderEventStorage) ClaimUnsentOrderEvents(_ context.Context, limit int, lease time.Duration) ([]models.OrderEvent, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.lockedUntil == nil {
+		s.lockedUntil = make(map[int64]time.Time)
+	}
+	now := time.Now()
+	claimed := make([]models.OrderEvent, 0, limit)
+	for _, event := range s.events {
+		if event.SentAt == nil && now.After(s.lockedUntil[event.ID]) && len(claimed) < limit {
+			s.lockedUntil[event.ID] = now.Add(lease)
+			claimed = append(claimed, event)
+		}
+	}
+	return claimed, nil
+}
+
+func (s *fakeOrderEventStorage) MarkOrderEventsSent(_ context.Context, ids []int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.markFailures > 0 {
+		s.markFailures--
+		return errors.New("mark failed")
+	}
+	now := time.Now()
+	for _, id := range ids {
+		for i := range s.events {
+			if s.events[i].ID == id {
+				s.events[i].SentAt = &now
+			}
+		}
+	}
+	return nil
+}
+
+func (s *fakeOrderEventStorage) unsent() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	count := 0
+	for _, event := range s.events {
+		if event.SentAt == nil {
+			count++
+		}
+	}
+	return count
+}
+
+// fakeOrderEventPublisher records published event IDs, publishFailures first calls fail
+type fakeOrderEventPublisher struct {
+	mu              sync.Mutex
+	published       []int64
+	publishFailures int
+}
+
+func (p *fakeOrderEventPublisher) PublishOrderEvents(_ context.Context, events []models.OrderEvent) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.publishFailures > 0 {
+		p.publishFailures--
+		return errors.New("publish failed")
+	}
+	for _, event := range events {
+		p.published = append(p.published, event.ID)
+	}
+	return nil
+}
+
+func (p *fakeOrderEventPublisher) publishedIDs() []int64 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return append([]int64(nil), p.published...)
+}
+
+// runRelay runs relays until all the events are sent, then stops them
+func runRelay(t *testing.T, storage *fakeOrderEventStorage, publisher *fakeOrderEventPublisher, batchSize, relays int) {
+	t.Helper()
+
+	ctx, err := logger.New(context.Background())
+	if err != nil {
+		t.Fatalf("Error creating logger for test: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	started := make([]*service.OrderEventRelayService, relays)
+	for i := range started {
+		started[i] = service.NewOrderEventRelayService(storage, publisher, batchSize, time.Millisecond,
+			10*time.Millisecond, backoff.NewFixed(time.Millisecond))
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_ = started[i].StartRelaying(ctx)
+		}()
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for storage.unsent() > 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected all events to be sent, %d left", storage.unsent())
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	for _, relay := range started {
+		relay.StopRelaying(ctx)
+	}
+	wg.Wait()
+}
+
+func newFakeOrderEvents(t *testing.T, count int) []models.OrderEvent {
+	t.Helper()
+
+	events := make([]models.OrderEvent, count)
+	for i := range events {
+		event, err := models.NewOrderEvent(models.OrderEventTypeSaved, newValidOrder(fmt.Sprintf("order-%d", i)))
+		if err != nil {
+			t.Fatalf("Error creating order event: %v", err)
+		}
+		event.ID = int64(i + 1)
+		events[i] = event
+	}
+	return events
+}
+
+func TestOrderEventRelayPublishesInOrder(t *testing.T) {
+	storage := &fakeOrderEventStorage{events: newFakeOrderEvents(t, 25)}
+	publisher := &fakeOrderEventPublisher{}
+
+	runRelay(t, storage, publisher, 10, 1)
+
+	published := publisher.publishedIDs()
+	if len(published) != 25 {
+		t.Fatalf("Expected 25 published events, got %d", len(published))
+	}
+	for i, id := range published {
+		if id != int64(i+1) {
+			t.Fatalf("Expected event %d at %d, got %d", i+1, i, id)
+		}
+	}
+}
+
+func TestOrderEventRelayAtLeastOnce(t *testing.T) {
+	storage := &fakeOrderEventStorage{events: newFakeOrderEvents(t, 5), markFailures: 1}
+	publisher := &fakeOrderEventPublisher{publishFailures: 2}
+
+	runRelay(t, storage, publisher, 10, 1)
+
+	// the first successful publish isn't marked, so the events are published twice after the lease
+	published := publisher.publishedIDs()
+	if len(published) != 10 {
+		t.Fatalf("Expected 10 published events, got %d", len(published))
+	}
+	for i, id := range published {
+		if id != int64(i%5+1) {
+			t.Fatalf("Expected event %d at %d, got %d", i%5+1, i, id)
+		}
+	}
+}
+
+func TestOrderEventRelayReplicasPublishOnce(t *testing.T) {
+	storage := &fakeOrderEventStorage{events: newFakeOrderEvents(t, 100)}
+	publisher := &fakeOrderEventPublisher{}
+
+	runRelay(t, storage, publisher, 7, 3)
+
+	// claimed events aren't published by other replicas
+	published := publisher.publishedIDs()
+	slices.Sort(published)
+	if len(published) != 100 {
+		t.Fatalf("Expected 100 published events, got %d", len(published))
+	}
+	for i, id := range published {
+		if id != int64(i+1) {
+			t.Fatalf("Expected every event to be published once, got %v", published)
+		}
+	}
+}
+
+func TestOrderEventPayload(t *testing.T) {
+	order := newValidOrder("b563feb7b2b84b6test")
+	event, err := models.NewOrderEvent(models.OrderEventTypeSaved, order)
+	if err != nil {
+		t.Fatalf("Error creating order event: %v", err)
+	}
+
+	var payload models.OrderEventPayload
+	if err = json.Unmarshal(event.Payload, &payload); err != nil {
+		t.Fatalf("Expected payload to be JSON, got error: %v", err)
+	}
+	if payload.SchemaVersion != models.OrderEventSchemaVersion {
+		t.Errorf("Expected schema version %d, got %d", models.OrderEventSchemaVersion, payload.SchemaVersion)
+	}
+	if payload.Type != models.OrderEventTypeSaved || event.Type != models.OrderEventTypeSaved {
+		t.Errorf("Expected event type %s, got %s and %s", models.OrderEventTypeSaved, payload.Type, event.Type)
+	}
+	if payload.OrderUID != order.OrderUID || event.OrderUID != order.OrderUID {
+		t.Errorf("Expected order_uid %s, got %s and %s", order.OrderUID, payload.OrderUID, event.OrderUID)
+	}
+	if payload.Order.OrderUID != order.OrderUID || len(payload.Order.Items) != len(order.Items) {
+		t.Errorf("Expected order snapshot %+v, got %+v", order, payload.Order)
+	}
+}
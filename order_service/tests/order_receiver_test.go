@@ -0,0 +1,300 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	"order_service/internal/custom_errors"
+	"order_service/internal/models"
+	"order_service/internal/service"
+	"order_service/pkg/logger"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeOrderReceiver hands out a fixed amount of orders, then blocks until ctx is done
+type fakeOrderReceiver struct {
+	mu       sync.Mutex
+	left     int
+	consumed atomic.Int64
+
+	succeeded atomic.Int64
+	failed    atomic.Int64
+}
+
+func (r *fakeOrderReceiver) Consume(ctx context.Context) (models.Order, int, error) {
+	r.mu.Lock()
+	if r.left == 0 {
+		r.mu.Unlock()
+		<-ctx.Done()
+		return models.Order{}, 0, ctx.Err()
+	}
+	r.left--
+	r.mu.Unlock()
+
+	n := int(r.consumed.Add(1))
+	return newValidOrder(fmt.Sprintf("order-%d", n)), n, nil
+}
+
+func (r *fakeOrderReceiver) OnSuccess(_ context.Context, _ int) error {
+	r.succeeded.Add(1)
+	return nil
+}
+
+func (r *fakeOrderReceiver) OnFail(_ context.Context, _ bool, _ int, _ error) error {
+	r.failed.Add(1)
+	return nil
+}
+
+func TestOrderReceiverBoundedWorkersAndDrain(t *testing.T) {
+	ctx, err := logger.New(context.Background())
+	if err != nil {
+		t.Fatalf("Error creating logger for test: %v", err)
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	const workers = 3
+	const orders = 30
+
+	// workers are blocked until the backpressure is checked
+	release := make(chan struct{})
+	var blocked, inFlight, maxInFlight atomic.Int64
+	process := func(_ context.Context, _ models.Order) error {
+		blocked.Add(1)
+		<-release
+		current := inFlight.Add(1)
+		for {
+			seen := maxInFlight.Load()
+			if current <= seen || maxInFlight.CompareAndSwap(seen, current) {
+				break
+			}
+		}
+		time.Sleep(time.Millisecond)
+		inFlight.Add(-1)
+		return nil
+	}
+
+	receiver := &fakeOrderReceiver{left: orders}
+	receiverService := service.NewOrderReceiverService[int](receiver, process, workers)
+
+	go func() {
+		_ = receiverService.StartReceivingOrders(ctx)
+	}()
+
+	// backpressure: no more than workers + the one being handed over are consumed ahead,
+	// however long every worker is busy
+	deadline := time.Now().Add(5 * time.Second)
+	for blocked.Load() < workers && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if blocked.Load() != workers {
+		t.Fatalf("Expected all %d workers to be busy, got %d", workers, blocked.Load())
+	}
+	time.Sleep(20 * time.Millisecond)
+	if consumed := receiver.consumed.Load(); consumed > workers+1 {
+		t.Errorf("Expected at most %d consumed orders while workers are busy, got %d", workers+1, consumed)
+	}
+	close(release)
+
+	deadline = time.Now().Add(5 * time.Second)
+	for receiver.succeeded.Load() < orders && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+
+	stopCtx, stopCancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+	defer stopCancel()
+	cancel()
+	receiverService.StopReceivingOrders(stopCtx)
+
+	if maxInFlight.Load() > workers {
+		t.Errorf("Expected at most %d concurrent orders, got %d", workers, maxInFlight.Load())
+	}
+	if receiver.succeeded.Load() != orders {
+		t.Errorf("Expected %d committed orders, got %d", orders, receiver.succeeded.Load())
+	}
+
+	var processed uint64
+	for _, stats := range receiverService.WorkersStats() {
+		processed += stats.Processed
+		if stats.Busy {
+			t.Errorf("Worker %d is busy after drain", stats.ID)
+		}
+	}
+	if processed != orders {
+		t.Errorf("Expected workers stats to count %d orders, got %d", orders, processed)
+	}
+}
+
+func TestOrderReceiverDrainsInFlightOnStop(t *testing.T) {
+	ctx, err := logger.New(context.Background())
+	if err != nil {
+		t.Fatalf("Error creating logger for test: %v", err)
+	}
+
+	started := make(chan struct{})
+	var finished atomic.Bool
+	process := func(ctx context.Context, _ models.Order) error {
+		close(started)
+		time.Sleep(50 * time.Millisecond)
+		// the processing ctx isn't cancelled by the shutdown
+		if ctx.Err() == nil {
+			finished.Store(true)
+		}
+		return nil
+	}
+
+	receiver := &fakeOrderReceiver{left: 1}
+	receiverService := service.NewOrderReceiverService[int](receiver, process, 1)
+
+	loopCtx, cancel := context.WithCancel(ctx)
+	go func() {
+		_ = receiverService.StartReceivingOrders(loopCtx)
+	}()
+
+	<-started
+	cancel()
+	stopCtx, stopCancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+	defer stopCancel()
+	receiverService.StopReceivingOrders(stopCtx)
+
+	if !finished.Load() {
+		t.Error("Expected in-flight order to finish before StopReceivingOrders returns")
+	}
+	if receiver.succeeded.Load() != 1 {
+		t.Errorf("Expected in-flight order to be committed, got %d", receiver.succeeded.Load())
+	}
+}
+
+// blockingFailOrderReceiver is a fakeOrderReceiver whose OnFail blocks until ctx is done, like a DLQ that's down
+type blockingFailOrderReceiver struct {
+	fakeOrderReceiver
+	failCancelled chan struct{}
+}
+
+func (r *blockingFailOrderReceiver) OnFail(ctx context.Context, _ bool, _ int, _ error) error {
+	<-ctx.Done()
+	close(r.failCancelled)
+	return ctx.Err()
+}
+
+func TestOrderReceiverCancelsInFlightWhenStopGivesUp(t *testing.T) {
+	ctx, err := logger.New(context.Background())
+	if err != nil {
+		t.Fatalf("Error creating logger for test: %v", err)
+	}
+
+	started := make(chan struct{})
+	process := func(_ context.Context, _ models.Order) error {
+		close(started)
+		return customerrors.ErrOrderConflict
+	}
+
+	receiver := &blockingFailOrderReceiver{fakeOrderReceiver: fakeOrderReceiver{left: 1}, failCancelled: make(chan struct{})}
+	receiverService := service.NewOrderReceiverService[int](receiver, process, 1)
+
+	loopCtx, cancel := context.WithCancel(ctx)
+	go func() {
+		_ = receiverService.StartReceivingOrders(loopCtx)
+	}()
+
+	<-started
+	cancel()
+	stopCtx, stopCancel := context.WithTimeout(context.WithoutCancel(ctx), 50*time.Millisecond)
+	defer stopCancel()
+	receiverService.StopReceivingOrders(stopCtx)
+
+	select {
+	case <-receiver.failCancelled:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected the blocked OnFail to be cancelled after StopReceivingOrders gave up")
+	}
+	if receiver.succeeded.Load() != 0 {
+		t.Errorf("Expected the failed message not to be committed, got %d", receiver.succeeded.Load())
+	}
+}
+
+// fakeBatchOrderReceiver is a fakeOrderReceiver that records committed batches
+type fakeBatchOrderReceiver struct {
+	fakeOrderReceiver
+	batchesMu sync.Mutex
+	batches   [][]int
+}
+
+func (r *fakeBatchOrderReceiver) OnSuccessBatch(_ context.Context, msgs []int) error {
+	r.batchesMu.Lock()
+	defer r.batchesMu.Unlock()
+	r.batches = append(r.batches, msgs)
+	r.succeeded.Add(int64(len(msgs)))
+	return nil
+}
+
+func TestOrderReceiverBatchModeIsolatesFailures(t *testing.T) {
+	ctx, err := logger.New(context.Background())
+	if err != nil {
+		t.Fatalf("Error creating logger for test: %v", err)
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	const orders = 10
+	var batchSizes []int
+	var batchSizesMu sync.Mutex
+	process := func(_ context.Context, batch []models.Order) []error {
+		batchSizesMu.Lock()
+		batchSizes = append(batchSizes, len(batch))
+		batchSizesMu.Unlock()
+
+		results := make([]error, len(batch))
+		for i, order := range batch {
+			// every order with an even number fails
+			if order.OrderUID[len(order.OrderUID)-1]%2 == 0 {
+				results[i] = fmt.Errorf("boom: %s", order.OrderUID)
+			}
+		}
+		return results
+	}
+
+	receiver := &fakeBatchOrderReceiver{fakeOrderReceiver: fakeOrderReceiver{left: orders}}
+	receiverService := service.NewBatchOrderReceiverService[int](receiver, process, 1, 4, 20*time.Millisecond)
+
+	go func() {
+		_ = receiverService.StartReceivingOrders(ctx)
+	}()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for receiver.succeeded.Load()+receiver.failed.Load() < orders && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	stopCtx, stopCancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+	defer stopCancel()
+	receiverService.StopReceivingOrders(stopCtx)
+
+	// batches are full or flushed by time, how orders are split depends on the scheduling
+	batchSizesMu.Lock()
+	defer batchSizesMu.Unlock()
+	total := 0
+	for _, size := range batchSizes {
+		if size < 1 || size > 4 {
+			t.Errorf("Expected batches of 1 to 4 orders, got %v", batchSizes)
+		}
+		total += size
+	}
+	if total != orders {
+		t.Errorf("Expected %d orders in batches, got %v", orders, batchSizes)
+	}
+
+	if receiver.succeeded.Load() != orders/2 || receiver.failed.Load() != orders/2 {
+		t.Errorf("Expected %d succeeded and %d failed, got %d and %d",
+			orders/2, orders/2, receiver.succeeded.Load(), receiver.failed.Load())
+	}
+
+	// one commit per batch
+	receiver.batchesMu.Lock()
+	defer receiver.batchesMu.Unlock()
+	if len(receiver.batches) != len(batchSizes) {
+		t.Errorf("Expected %d committed batches, got %d", len(batchSizes), len(receiver.batches))
+	}
+}
@@ -0,0 +1,417 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"order_service/internal/custom_errors"
+	"order_service/internal/models"
+	"order_service/internal/ports"
+	"order_service/internal/service"
+	"order_service/pkg/logger"
+	"order_service/pkg/pkgports/adapters/cache/lru"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeOrderStorage is an in-memory ports.OrderStorage, the methods that aren't used by tests panic
+//
+// If release isn't nil, GetOrderByID waits for it to be closed. If afterRead isn't nil, GetOrderByID calls it
+// after the order is read, e.g. to change it before the read one is returned.
+// ListOrders returns page whatever the filter is, the filters are saved in listed
+type fakeOrderStorage struct {
+	ports.OrderStorage
+
+	mu        sync.Mutex
+	orders    map[string]models.Order
+	reads     int
+	release   chan struct{}
+	afterRead func()
+	page      models.OrdersPage
+	listed    []models.OrdersFilter
+}
+
+func newFakeOrderStorage() *fakeOrderStorage {
+	return &fakeOrderStorage{orders: make(map[string]models.Order)}
+}
+
+func (s *fakeOrderStorage) GetOrderByID(_ context.Context, orderID string) (models.Order, error) {
+	s.mu.Lock()
+	s.reads++
+	release := s.release
+	s.mu.Unlock()
+
+	if release != nil {
+		<-release
+	}
+
+	s.mu.Lock()
+	order, ok := s.orders[orderID]
+	afterRead := s.afterRead
+	s.mu.Unlock()
+
+	if afterRead != nil {
+		afterRead()
+	}
+	if !ok {
+		return models.Order{}, fmt.Errorf("%w: %s", customerrors.ErrOrderNotFound, orderID)
+	}
+	return order, nil
+}
+
+func (s *fakeOrderStorage) UpsertOrder(_ context.Context, order models.Order) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if stored, ok := s.orders[order.OrderUID]; ok && stored.Version >= order.Version {
+		return customerrors.ErrOrderOutdated
+	}
+	s.orders[order.OrderUID] = order
+	return nil
+}
+
+func (s *fakeOrderStorage) DeleteOrder(_ context.Context, orderUID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.orders[orderUID]; !ok {
+		return fmt.Errorf("%w: %s", customerrors.ErrOrderNotFound, orderUID)
+	}
+	delete(s.orders, orderUID)
+	return nil
+}
+
+func (s *fakeOrderStorage) ChangeOrderStatus(
+	_ context.Context, orderUID string, nmID int, status models.OrderStatus, comment string,
+) (models.OrderStatusEvent, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	order, ok := s.orders[orderUID]
+	if !ok {
+		return models.OrderStatusEvent{}, fmt.Errorf("%w: %s", customerrors.ErrOrderNotFound, orderUID)
+	}
+	event := models.OrderStatusEvent{OrderUID: orderUID, NmID: nmID, Status: status, Comment: comment}
+	order.AttachStatusTimeline(append(slices.Clone(order.StatusTimeline), event))
+	s.orders[orderUID] = order
+	return event, nil
+}
+
+func (s *fakeOrderStorage) ListOrders(_ context.Context, filter models.OrdersFilter) (models.OrdersPage, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.listed = append(s.listed, filter)
+	return s.page, nil
+}
+
+func (s *fakeOrderStorage) readsAmount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.reads
+}
+
+// newTestOrderService returns a service over a fake storage and in-memory caches
+func newTestOrderService(t *testing.T) (context.Context, *service.OrderService, *fakeOrderStorage, ports.OrderCache) {
+	t.Helper()
+
+	ctx, err := logger.New(context.Background())
+	if err != nil {
+		t.Fatalf("Error creating logger for test: %v", err)
+	}
+	storage := newFakeOrderStorage()
+	cache := lru.NewCacheLRUInMemory[string, models.Order](10)
+	notFound := lru.NewCacheLRUInMemoryWithTTL[string, struct{}](10, time.Minute, 0)
+	return ctx, service.NewOrderService(storage, cache, notFound), storage, cache
+}
+
+// waitCached waits for an order to be cached, GetOrder caches it synchronously, so it's found right away
+func waitCached(t *testing.T, ctx context.Context, cache ports.OrderCache, orderUID string) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if _, found, _ := cache.Peek(ctx, orderUID); found {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected order %s to be cached", orderUID)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestOrderServiceUpsertInvalidatesCache(t *testing.T) {
+	ctx, orderService, storage, cache := newTestOrderService(t)
+
+	order := newValidOrder("b563feb7b2b84b6test")
+	order.Version = 1
+	if err := orderService.UpsertOrder(ctx, order); err != nil {
+		t.Fatalf("Expected order to be upserted, got error: %v", err)
+	}
+	if _, err := orderService.GetOrder(ctx, order.OrderUID); err != nil {
+		t.Fatalf("Expected order to be found, got error: %v", err)
+	}
+	waitCached(t, ctx, cache, order.OrderUID)
+
+	order.Version = 2
+	order.TrackNumber = "UPDATED"
+	if err := orderService.UpsertOrder(ctx, order); err != nil {
+		t.Fatalf("Expected order to be upserted, got error: %v", err)
+	}
+	if _, found, _ := cache.Peek(ctx, order.OrderUID); found {
+		t.Fatal("Expected the previous version to be invalidated")
+	}
+
+	got, err := orderService.GetOrder(ctx, order.OrderUID)
+	if err != nil {
+		t.Fatalf("Expected order to be found, got error: %v", err)
+	}
+	if got.Version != 2 || got.TrackNumber != "UPDATED" {
+		t.Errorf("Expected the new version, got version %d with track number %s", got.Version, got.TrackNumber)
+	}
+	if storage.readsAmount() != 2 {
+		t.Errorf("Expected both versions to be read from storage once, got %d reads", storage.readsAmount())
+	}
+}
+
+func TestOrderServiceDoesNotCacheOrderInvalidatedWhileRead(t *testing.T) {
+	ctx, orderService, storage, cache := newTestOrderService(t)
+
+	order := newValidOrder("b563feb7b2b84b6test")
+	order.Version = 1
+	if err := orderService.UpsertOrder(ctx, order); err != nil {
+		t.Fatalf("Expected order to be upserted, got error: %v", err)
+	}
+
+	// the next version is upserted after the miss has read the first one, but before it's cached
+	updated := order
+	updated.Version = 2
+	storage.mu.Lock()
+	storage.afterRead = func() {
+		storage.mu.Lock()
+		storage.afterRead = nil
+		storage.mu.Unlock()
+
+		if err := orderService.UpsertOrder(ctx, updated); err != nil {
+			t.Errorf("Expected order to be upserted, got error: %v", err)
+		}
+	}
+	storage.mu.Unlock()
+
+	got, err := orderService.GetOrder(ctx, order.OrderUID)
+	if err != nil {
+		t.Fatalf("Expected order to be found, got error: %v", err)
+	}
+	if got.Version != 1 {
+		t.Errorf("Expected the version that was read, got %d", got.Version)
+	}
+	if _, found, _ := cache.Peek(ctx, order.OrderUID); found {
+		t.Fatal("Expected the version read before the invalidation not to be cached")
+	}
+
+	got, err = orderService.GetOrder(ctx, order.OrderUID)
+	if err != nil {
+		t.Fatalf("Expected order to be found, got error: %v", err)
+	}
+	if got.Version != 2 {
+		t.Errorf("Expected the new version, got %d", got.Version)
+	}
+	if cached, found, _ := cache.Peek(ctx, order.OrderUID); !found || cached.Version != 2 {
+		t.Errorf("Expected the new version to be cached, got %d (found: %t)", cached.Version, found)
+	}
+}
+
+func TestOrderServiceDeleteInvalidatesCache(t *testing.T) {
+	ctx, orderService, _, cache := newTestOrderService(t)
+
+	order := newValidOrder("b563feb7b2b84b6test")
+	if err := orderService.UpsertOrder(ctx, order); err != nil {
+		t.Fatalf("Expected order to be saved, got error: %v", err)
+	}
+	if _, err := orderService.GetOrder(ctx, order.OrderUID); err != nil {
+		t.Fatalf("Expected order to be found, got error: %v", err)
+	}
+	waitCached(t, ctx, cache, order.OrderUID)
+
+	if err := orderService.DeleteOrder(ctx, order.OrderUID); err != nil {
+		t.Fatalf("Expected order to be deleted, got error: %v", err)
+	}
+	if _, found, _ := cache.Peek(ctx, order.OrderUID); found {
+		t.Error("Expected the deleted order to be invalidated")
+	}
+	if _, err := orderService.GetOrder(ctx, order.OrderUID); !errors.Is(err, customerrors.ErrOrderNotFound) {
+		t.Errorf("Expected not found error, got: %v", err)
+	}
+
+	// a stale cached order is invalidated even if it isn't stored
+	_ = cache.Set(ctx, "stale", order)
+	if err := orderService.DeleteOrder(ctx, "stale"); !errors.Is(err, customerrors.ErrOrderNotFound) {
+		t.Errorf("Expected not found error, got: %v", err)
+	}
+	if _, found, _ := cache.Peek(ctx, "stale"); found {
+		t.Error("Expected the stale order to be invalidated")
+	}
+}
+
+func TestOrderServiceChangeStatusInvalidatesCache(t *testing.T) {
+	ctx, orderService, storage, cache := newTestOrderService(t)
+
+	order := newValidOrder("b563feb7b2b84b6test")
+	if err := orderService.UpsertOrder(ctx, order); err != nil {
+		t.Fatalf("Expected order to be saved, got error: %v", err)
+	}
+	if _, err := orderService.GetOrder(ctx, order.OrderUID); err != nil {
+		t.Fatalf("Expected order to be found, got error: %v", err)
+	}
+	waitCached(t, ctx, cache, order.OrderUID)
+
+	// the timeline is cached with the order, a cache hit doesn't read the storage
+	if _, err := orderService.GetOrder(ctx, order.OrderUID); err != nil {
+		t.Fatalf("Expected order to be found, got error: %v", err)
+	}
+	if storage.readsAmount() != 1 {
+		t.Fatalf("Expected a single storage read, got %d", storage.readsAmount())
+	}
+
+	if _, err := orderService.ChangeOrderStatus(ctx, order.OrderUID, 0, models.OrderStatusPaid, ""); err != nil {
+		t.Fatalf("Expected status to be changed, got error: %v", err)
+	}
+	if _, found, _ := cache.Peek(ctx, order.OrderUID); found {
+		t.Fatal("Expected the order with the previous status to be invalidated")
+	}
+	got, err := orderService.GetOrder(ctx, order.OrderUID)
+	if err != nil {
+		t.Fatalf("Expected order to be found, got error: %v", err)
+	}
+	if status := models.CurrentOrderStatus(got.StatusTimeline); status != models.OrderStatusPaid {
+		t.Errorf("Expected %s status, got %s", models.OrderStatusPaid, status)
+	}
+}
+
+func TestOrderServiceGetCacheStats(t *testing.T) {
+	ctx, orderService, _, cache := newTestOrderService(t)
+
+	if stats := orderService.GetCacheStats(ctx); stats.MostUsedKey != "" || stats.LeastUsedKey != "" || stats.HitRatio() != 0 {
+		t.Errorf("Expected no keys and no hit ratio in empty cache, got %+v", stats)
+	}
+
+	_ = cache.Set(ctx, "first", newValidOrder("first"))
+	_ = cache.Set(ctx, "second", newValidOrder("second"))
+	for _, orderUID := range []string{"first", "first", "first", "unknown"} {
+		_, _ = orderService.GetOrder(ctx, orderUID)
+	}
+
+	stats := orderService.GetCacheStats(ctx)
+	if stats.MostUsedKey != "first" || stats.LeastUsedKey != "second" {
+		t.Errorf("Expected first to be the most used and second the least, got %s and %s",
+			stats.MostUsedKey, stats.LeastUsedKey)
+	}
+	if stats.Hits != 3 || stats.Misses != 1 || stats.HitRatio() != 0.75 {
+		t.Errorf("Expected 3 hits of 4 reads, got %+v", stats)
+	}
+	if stats.Size != 2 || stats.Capacity != 10 {
+		t.Errorf("Expected 2 of 10 values, got %d of %d", stats.Size, stats.Capacity)
+	}
+}
+
+func TestOrderServiceCoalescesMisses(t *testing.T) {
+	const callers = 20
+
+	ctx, orderService, storage, cache := newTestOrderService(t)
+	order := newValidOrder("b563feb7b2b84b6test")
+	storage.orders[order.OrderUID] = order
+	storage.release = make(chan struct{})
+
+	var wg sync.WaitGroup
+	errs := make(chan error, callers)
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			got, err := orderService.GetOrder(ctx, order.OrderUID)
+			if err != nil {
+				errs <- err
+				return
+			}
+			if got.OrderUID != order.OrderUID {
+				errs <- fmt.Errorf("expected order %s, got %s", order.OrderUID, got.OrderUID)
+			}
+		}()
+	}
+
+	// the callers join the first read while it's blocked
+	time.Sleep(50 * time.Millisecond)
+	close(storage.release)
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+
+	// a slow caller might miss the shared read, but every read is either made or coalesced
+	stats := orderService.GetCacheStats(ctx)
+	if reads := storage.readsAmount(); reads+int(stats.Coalesced) != callers || stats.Coalesced == 0 {
+		t.Errorf("Expected %d calls to be made or coalesced, got %d reads and %d coalesced", callers, reads, stats.Coalesced)
+	}
+	if stats.Sets != uint64(storage.readsAmount()) {
+		t.Errorf("Expected every storage read to be cached once, got %d sets of %d reads", stats.Sets, storage.readsAmount())
+	}
+	if _, found, _ := cache.Peek(ctx, order.OrderUID); !found {
+		t.Error("Expected the order to be cached")
+	}
+}
+
+func TestOrderServiceCoalescedCallCancelled(t *testing.T) {
+	ctx, orderService, storage, _ := newTestOrderService(t)
+	order := newValidOrder("b563feb7b2b84b6test")
+	storage.orders[order.OrderUID] = order
+	storage.release = make(chan struct{})
+
+	// the first caller waits for the read
+	waiting := make(chan error, 1)
+	go func() {
+		_, err := orderService.GetOrder(ctx, order.OrderUID)
+		waiting <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+
+	// the second one gives up, it must neither wait for the read nor cancel it
+	cancelCtx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	if _, err := orderService.GetOrder(cancelCtx, order.OrderUID); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected deadline exceeded error, got: %v", err)
+	}
+
+	close(storage.release)
+	if err := <-waiting; err != nil {
+		t.Fatalf("Expected the waiting caller to get the order, got error: %v", err)
+	}
+	if storage.readsAmount() != 1 {
+		t.Errorf("Expected a single storage read, got %d", storage.readsAmount())
+	}
+}
+
+func TestOrderServiceCachesNotFound(t *testing.T) {
+	ctx, orderService, storage, _ := newTestOrderService(t)
+
+	for i := 0; i < 3; i++ {
+		if _, err := orderService.GetOrder(ctx, "unknown"); !errors.Is(err, customerrors.ErrOrderNotFound) {
+			t.Fatalf("Expected not found error, got: %v", err)
+		}
+	}
+	if storage.readsAmount() != 1 {
+		t.Errorf("Expected unknown order to be read from storage once, got %d reads", storage.readsAmount())
+	}
+
+	// saving the order invalidates it
+	if err := orderService.UpsertOrder(ctx, newValidOrder("unknown")); err != nil {
+		t.Fatalf("Expected order to be saved, got error: %v", err)
+	}
+	if _, err := orderService.GetOrder(ctx, "unknown"); err != nil {
+		t.Fatalf("Expected saved order to be found, got error: %v", err)
+	}
+}
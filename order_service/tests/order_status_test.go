@@ -0,0 +1,91 @@
+package tests
+
+import (
+	"order_service/internal/models"
+	"testing"
+)
+
+func TestOrderStatusTransitions(t *testing.T) {
+	cases := []struct {
+		from, to models.OrderStatus
+		allowed  bool
+	}{
+		{models.OrderStatusCreated, models.OrderStatusPaid, true},
+		{models.OrderStatusCreated, models.OrderStatusCancelled, true},
+		{models.OrderStatusCreated, models.OrderStatusShipped, false},
+		{models.OrderStatusPaid, models.OrderStatusAssembled, true},
+		{models.OrderStatusPaid, models.OrderStatusCancelled, true},
+		{models.OrderStatusPaid, models.OrderStatusCreated, false},
+		{models.OrderStatusAssembled, models.OrderStatusShipped, true},
+		{models.OrderStatusAssembled, models.OrderStatusCancelled, true},
+		{models.OrderStatusShipped, models.OrderStatusDelivered, true},
+		{models.OrderStatusShipped, models.OrderStatusCancelled, false},
+		{models.OrderStatusDelivered, models.OrderStatusReturned, true},
+		{models.OrderStatusDelivered, models.OrderStatusCancelled, false},
+		{models.OrderStatusCancelled, models.OrderStatusPaid, false},
+		{models.OrderStatusReturned, models.OrderStatusDelivered, false},
+		{models.OrderStatusPaid, models.OrderStatusPaid, false},
+		{models.OrderStatusCreated, models.OrderStatus("lost"), false},
+		{models.OrderStatus("lost"), models.OrderStatusPaid, false},
+	}
+
+	for _, c := range cases {
+		if allowed := c.from.CanTransitionTo(c.to); allowed != c.allowed {
+			t.Errorf("Expected %s -> %s allowed: %v, got %v", c.from, c.to, c.allowed, allowed)
+		}
+	}
+}
+
+func TestOrderStatusIsValid(t *testing.T) {
+	for _, status := range []models.OrderStatus{
+		models.OrderStatusCreated, models.OrderStatusPaid, models.OrderStatusAssembled, models.OrderStatusShipped,
+		models.OrderStatusDelivered, models.OrderStatusCancelled, models.OrderStatusReturned,
+	} {
+		if !status.IsValid() {
+			t.Errorf("Expected %s to be valid", status)
+		}
+	}
+	if models.OrderStatus("202").IsValid() {
+		t.Error("Expected 202 to be invalid")
+	}
+}
+
+func TestCurrentOrderStatus(t *testing.T) {
+	if status := models.CurrentOrderStatus(nil); status != models.OrderStatusCreated {
+		t.Errorf("Expected empty timeline to be %s, got %s", models.OrderStatusCreated, status)
+	}
+
+	timeline := []models.OrderStatusEvent{
+		{ID: 1, Status: models.OrderStatusCreated},
+		{ID: 2, Status: models.OrderStatusPaid},
+	}
+	if status := models.CurrentOrderStatus(timeline); status != models.OrderStatusPaid {
+		t.Errorf("Expected %s, got %s", models.OrderStatusPaid, status)
+	}
+}
+
+func TestOrderAttachStatusTimeline(t *testing.T) {
+	order := newValidOrder("b563feb7b2b84b6test")
+	nmID := order.Items[0].NmID
+
+	order.AttachStatusTimeline([]models.OrderStatusEvent{
+		{ID: 1, Status: models.OrderStatusCreated},
+		{ID: 2, NmID: nmID, Status: models.OrderStatusCancelled},
+		{ID: 3, Status: models.OrderStatusPaid},
+		{ID: 4, NmID: nmID + 1, Status: models.OrderStatusPaid}, // an item of another version
+	})
+
+	if len(order.StatusTimeline) != 2 || models.CurrentOrderStatus(order.StatusTimeline) != models.OrderStatusPaid {
+		t.Errorf("Expected created -> paid order timeline, got %+v", order.StatusTimeline)
+	}
+	itemTimeline := order.Items[0].StatusTimeline
+	if len(itemTimeline) != 1 || models.CurrentOrderStatus(itemTimeline) != models.OrderStatusCancelled {
+		t.Errorf("Expected cancelled item timeline, got %+v", itemTimeline)
+	}
+
+	// the timelines are set by the storage, the content is the same
+	hash, _ := newValidOrder(order.OrderUID).ContentHash()
+	if withTimeline, _ := order.ContentHash(); withTimeline != hash {
+		t.Error("Expected status timelines to be ignored by ContentHash")
+	}
+}
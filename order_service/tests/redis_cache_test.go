@@ -0,0 +1,240 @@
+package tests
+
+import (
+	"context"
+	"github.com/alicebob/miniredis/v2"
+	goredis "github.com/redis/go-redis/v9"
+	"order_service/internal/models"
+	"order_service/internal/service"
+	"order_service/pkg/logger"
+	"order_service/pkg/pkgports"
+	"order_service/pkg/pkgports/adapters/cache/lru"
+	"order_service/pkg/pkgports/adapters/cache/rediscache"
+	"slices"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// newTestRedis starts an in-process redis stand-in and returns it with a client
+func newTestRedis(t *testing.T) (context.Context, *miniredis.Miniredis, *goredis.Client) {
+	t.Helper()
+
+	ctx, err := logger.New(context.Background())
+	if err != nil {
+		t.Fatalf("Error creating logger for test: %v", err)
+	}
+	server := miniredis.RunT(t)
+	client := goredis.NewClient(&goredis.Options{Addr: server.Addr()})
+	t.Cleanup(func() {
+		_ = client.Close()
+	})
+	return ctx, server, client
+}
+
+// intKeyCodec formats int keys in decimal, it shows that keys don't have to be strings
+type intKeyCodec struct{}
+
+func (intKeyCodec) Format(key int) string {
+	return strconv.Itoa(key)
+}
+
+func (intKeyCodec) Parse(s string) (int, error) {
+	return strconv.Atoi(s)
+}
+
+// rawCodec saves strings as is, it shows that values don't have to be JSON
+type rawCodec struct{}
+
+func (rawCodec) Marshal(value string) ([]byte, error) {
+	return []byte(value), nil
+}
+
+func (rawCodec) Unmarshal(data []byte) (string, error) {
+	return string(data), nil
+}
+
+func TestRedisCacheRoundTrip(t *testing.T) {
+	ctx, server, client := newTestRedis(t)
+	cache := rediscache.NewCacheRedis[string, models.Order](
+		client, rediscache.StringKeyCodec{}, rediscache.JSONCodec[models.Order]{}, "orders:", time.Minute,
+	)
+
+	order := newValidOrder("b563feb7b2b84b6test")
+	if err := cache.Set(ctx, order.OrderUID, order); err != nil {
+		t.Fatalf("Expected order to be set, got error: %v", err)
+	}
+	if !server.Exists("orders:" + order.OrderUID) {
+		t.Fatalf("Expected the key to be prefixed, got keys %v", server.Keys())
+	}
+
+	got, found, err := cache.Get(ctx, order.OrderUID)
+	if err != nil || !found {
+		t.Fatalf("Expected order to be found, got found %v, error: %v", found, err)
+	}
+	if got.OrderUID != order.OrderUID || len(got.Items) != len(order.Items) || got.Payment != order.Payment {
+		t.Errorf("Expected order %+v, got %+v", order, got)
+	}
+
+	if _, found, _ = cache.Get(ctx, "unknown"); found {
+		t.Error("Unknown order shouldn't be found")
+	}
+}
+
+func TestRedisCacheTTL(t *testing.T) {
+	ctx, server, client := newTestRedis(t)
+	cache := rediscache.NewCacheRedis[int, string](client, intKeyCodec{}, rawCodec{}, "ttl:", time.Minute)
+
+	_ = cache.Set(ctx, 1, "default")
+	_ = cache.SetWithTTL(ctx, 2, "own", time.Hour)
+	_ = cache.SetWithTTL(ctx, 3, "forever", 0)
+
+	if ttl := server.TTL("ttl:1"); ttl != time.Minute {
+		t.Errorf("Expected default TTL of a minute, got %v", ttl)
+	}
+	if ttl := server.TTL("ttl:3"); ttl != 0 {
+		t.Errorf("Expected no TTL, got %v", ttl)
+	}
+
+	server.FastForward(2 * time.Minute)
+
+	if _, found, _ := cache.Get(ctx, 1); found {
+		t.Error("Key 1 should have expired")
+	}
+	if value, found, _ := cache.Get(ctx, 2); !found || value != "own" {
+		t.Errorf("Expected key 2 with its own TTL to be own, got %q, found %v", value, found)
+	}
+	if value, found, _ := cache.Get(ctx, 3); !found || value != "forever" {
+		t.Errorf("Expected key 3 without TTL to be forever, got %q, found %v", value, found)
+	}
+}
+
+func TestRedisCacheKeysDeleteClear(t *testing.T) {
+	ctx, server, client := newTestRedis(t)
+	// the prefix has glob characters, they must be matched as is
+	cache := rediscache.NewCacheRedis[int, string](client, intKeyCodec{}, rawCodec{}, "c[1]*:", 0)
+
+	for key := 0; key < 250; key++ {
+		_ = cache.Set(ctx, key, strconv.Itoa(key))
+	}
+	// keys of others aren't touched
+	_ = server.Set("c[1]x:1", "other")
+	_ = server.Set("other", "other")
+
+	if amount := cache.GetKeysAmount(); amount != 250 {
+		t.Errorf("Expected 250 keys, got %d", amount)
+	}
+	keys := cache.GetKeys()
+	slices.Sort(keys)
+	if len(keys) != 250 || keys[0] != 0 || keys[249] != 249 {
+		t.Errorf("Expected keys 0..249, got %d keys", len(keys))
+	}
+
+	if deleted, _ := cache.Delete(ctx, 7); !deleted {
+		t.Error("Expected key 7 to be deleted")
+	}
+	if deleted, _ := cache.Delete(ctx, 7); deleted {
+		t.Error("Key 7 is already deleted, nothing should be deleted twice")
+	}
+
+	if err := cache.Clear(ctx); err != nil {
+		t.Fatalf("Expected cache to be cleared, got error: %v", err)
+	}
+	if amount := cache.GetKeysAmount(); amount != 0 {
+		t.Errorf("Expected no keys after clear, got %d", amount)
+	}
+	if !server.Exists("c[1]x:1") || !server.Exists("other") {
+		t.Errorf("Expected keys without the prefix to be kept, got %v", server.Keys())
+	}
+}
+
+func TestRedisCacheUndecodableValue(t *testing.T) {
+	ctx, server, client := newTestRedis(t)
+	cache := rediscache.NewCacheRedis[string, models.Order](
+		client, rediscache.StringKeyCodec{}, rediscache.JSONCodec[models.Order]{}, "orders:", 0,
+	)
+
+	_ = server.Set("orders:broken", "{not json")
+
+	_, found, err := cache.Get(ctx, "broken")
+	if err != nil || found {
+		t.Fatalf("Expected undecodable value to be a miss, got found %v, error: %v", found, err)
+	}
+	if server.Exists("orders:broken") {
+		t.Error("Expected undecodable value to be deleted")
+	}
+}
+
+func TestRedisCacheStats(t *testing.T) {
+	ctx, _, client := newTestRedis(t)
+	cache := rediscache.NewCacheRedis[int, string](client, intKeyCodec{}, rawCodec{}, "stats:", 0)
+
+	_ = cache.Set(ctx, 1, "one")
+	_ = cache.Set(ctx, 2, "two")
+	_, _, _ = cache.Get(ctx, 1)
+	_, _, _ = cache.Get(ctx, 3)
+	// peeks aren't counted
+	_, _, _ = cache.Peek(ctx, 1)
+	if value, found, _ := cache.Peek(ctx, 2); !found || value != "two" {
+		t.Errorf("Expected key 2 to be two, got %q, found %v", value, found)
+	}
+
+	// the size isn't counted, it would SCAN every key
+	expected := pkgports.CacheStats{Hits: 1, Misses: 1, Sets: 2}
+	if stats := cache.Stats(); stats != expected {
+		t.Errorf("Expected stats %+v, got %+v", expected, stats)
+	}
+}
+
+func TestRedisCacheGenerations(t *testing.T) {
+	ctx, server, client := newTestRedis(t)
+	// without a prefix generation keys match the scans too
+	cache := rediscache.NewCacheRedis[int, string](client, intKeyCodec{}, rawCodec{}, "", time.Minute)
+
+	testGenerationCache(t, ctx, cache)
+
+	if ttl := server.TTL("1"); ttl != time.Minute {
+		t.Errorf("Expected default TTL of a minute, got %v", ttl)
+	}
+	// generations aren't keys of the cache
+	if amount := cache.GetKeysAmount(); amount != 1 {
+		t.Errorf("Expected a single key, got %d of %v", amount, server.Keys())
+	}
+}
+
+func TestOrderServiceTreatsRedisErrorsAsMisses(t *testing.T) {
+	ctx, server, client := newTestRedis(t)
+	cache := rediscache.NewCacheRedis[string, models.Order](
+		client, rediscache.StringKeyCodec{}, rediscache.JSONCodec[models.Order]{}, "orders:", time.Minute,
+	)
+	storage := newFakeOrderStorage()
+	notFound := lru.NewCacheLRUInMemoryWithTTL[string, struct{}](10, time.Minute, 0)
+	orderService := service.NewOrderService(storage, cache, notFound)
+
+	order := newValidOrder("b563feb7b2b84b6test")
+	if err := orderService.UpsertOrder(ctx, order); err != nil {
+		t.Fatalf("Expected order to be upserted, got error: %v", err)
+	}
+
+	// redis is down, the order is read from storage and isn't cached
+	server.SetError("LOADING redis is loading the dataset in memory")
+	got, err := orderService.GetOrder(ctx, order.OrderUID)
+	if err != nil {
+		t.Fatalf("Expected order to be read from storage, got error: %v", err)
+	}
+	if got.OrderUID != order.OrderUID {
+		t.Errorf("Expected order %s, got %s", order.OrderUID, got.OrderUID)
+	}
+
+	// redis is back, the order is cached by the next read
+	server.SetError("")
+	if _, err = orderService.GetOrder(ctx, order.OrderUID); err != nil {
+		t.Fatalf("Expected order to be found, got error: %v", err)
+	}
+	if _, err = orderService.GetOrder(ctx, order.OrderUID); err != nil {
+		t.Fatalf("Expected order to be found, got error: %v", err)
+	}
+	if storage.readsAmount() != 2 {
+		t.Errorf("Expected reads while redis is down and on the first miss after, got %d reads", storage.readsAmount())
+	}
+}
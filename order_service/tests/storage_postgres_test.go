@@ -0,0 +1,514 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/ilyakaznacheev/cleanenv"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"order_service/internal/custom_errors"
+	"order_service/internal/models"
+	"order_service/internal/ports/adapters/storage"
+	"order_service/internal/validators"
+	"order_service/pkg/postgres"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// storageTestConfig is read with an env-prefix of "TEST_POSTGRES_", storage tests are skipped without TEST_POSTGRES_HOST
+type storageTestConfig struct {
+	Postgres postgres.Config `env-prefix:"TEST_POSTGRES_"`
+}
+
+// newTestStorage connects to the test database, applies migrations from db/migrations
+// and returns the storage with the pool
+func newTestStorage(t *testing.T) (*storage.OrdersStoragePostgres, *pgxpool.Pool) {
+	t.Helper()
+
+	var cfg storageTestConfig
+	if err := cleanenv.ReadEnv(&cfg); err != nil {
+		t.Fatalf("Couldn't read test postgres config: %v", err)
+	}
+	if strings.TrimSpace(os.Getenv("TEST_POSTGRES_HOST")) == "" {
+		t.Skip("TEST_POSTGRES_HOST isn't set, skipping postgres storage tests")
+	}
+	if os.Getenv("TEST_POSTGRES_MIGRATIONS_URL") == "" {
+		migrations, err := filepath.Abs("../db/migrations")
+		if err != nil {
+			t.Fatalf("Couldn't resolve migrations path: %v", err)
+		}
+		cfg.Postgres.MigrationsURL = "file://" + filepath.ToSlash(migrations)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	pool, err := postgres.New(ctx, cfg.Postgres)
+	if err != nil {
+		t.Fatalf("Couldn't connect to test postgres: %v", err)
+	}
+	t.Cleanup(pool.Close)
+
+	return storage.NewOrdersStoragePostgres(pool), pool
+}
+
+// newTestOrderUID returns an order_uid that isn't used by other tests, the order is deleted on cleanup
+func newTestOrderUID(t *testing.T, pool *pgxpool.Pool) string {
+	t.Helper()
+
+	orderUID := fmt.Sprintf("test%d", time.Now().UnixNano())
+	t.Cleanup(func() {
+		// payments, deliveries, items and statuses are deleted by cascade, history and outbox events aren't
+		_, err := pool.Exec(context.Background(), "DELETE FROM order_service.orders WHERE order_uid = $1", orderUID)
+		if err != nil {
+			t.Errorf("Couldn't delete test order %s: %v", orderUID, err)
+		}
+		_, err = pool.Exec(context.Background(), "DELETE FROM order_service.order_history WHERE order_uid = $1", orderUID)
+		if err != nil {
+			t.Errorf("Couldn't delete test order history %s: %v", orderUID, err)
+		}
+		_, err = pool.Exec(context.Background(), "DELETE FROM order_service.order_events WHERE order_uid = $1", orderUID)
+		if err != nil {
+			t.Errorf("Couldn't delete test order events %s: %v", orderUID, err)
+		}
+	})
+	return orderUID
+}
+
+// requireSameItems fails if stored items differ from saved ones, the order of items doesn't matter
+func requireSameItems(t *testing.T, saved, stored []models.OrderItem) {
+	t.Helper()
+
+	if len(saved) != len(stored) {
+		t.Fatalf("Expected %d items, got %d", len(saved), len(stored))
+	}
+	byNmID := make(map[int]models.OrderItem, len(stored))
+	for _, item := range stored {
+		item.OrderID = ""
+		item.StatusTimeline = nil
+		byNmID[item.NmID] = item
+	}
+	for _, item := range saved {
+		item.OrderID = ""
+		if got, ok := byNmID[item.NmID]; !ok || !reflect.DeepEqual(got, item) {
+			t.Errorf("Expected item %+v, got %+v", item, got)
+		}
+	}
+}
+
+// requireNotStored fails if an order with given uid is stored
+func requireNotStored(t *testing.T, s *storage.OrdersStoragePostgres, orderUID string) {
+	t.Helper()
+
+	_, err := s.GetOrderByID(context.Background(), orderUID)
+	if !errors.Is(err, customerrors.ErrOrderNotFound) {
+		t.Fatalf("Expected order %s not to be stored, got error: %v", orderUID, err)
+	}
+}
+
+func TestStorageSaveItemsRoundTrip(t *testing.T) {
+	s, pool := newTestStorage(t)
+
+	cases := []struct {
+		name  string
+		items []models.OrderItem
+	}{
+		{"quotes", []models.OrderItem{{
+			Name:  `Robert'); DROP TABLE order_service.orders;--`,
+			Brand: "L'Oreal",
+			RID:   `rid "quoted" \ backslash`,
+		}}},
+		{"unicode", []models.OrderItem{{
+			Name:  "Тушь для ресниц 💄",
+			Brand: "資生堂",
+			RID:   "ä-ö-ü-ß",
+		}}},
+		{"longest strings", []models.OrderItem{{
+			Name:  strings.Repeat("я", 100),
+			Brand: strings.Repeat("😀", 100),
+			RID:   strings.Repeat("r", 50),
+		}}},
+		{"several items", []models.OrderItem{
+			{Name: "first", Brand: "$1", RID: "$2"},
+			{Name: "second", Brand: "?", RID: "%s"},
+			{Name: "third", Brand: "NULL", RID: "''"},
+		}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			order := newValidOrder(newTestOrderUID(t, pool))
+			base := order.Items[0]
+			order.Items = make([]models.OrderItem, len(c.items))
+			for i, item := range c.items {
+				order.Items[i] = base
+				order.Items[i].NmID = base.NmID + i
+				order.Items[i].Name = item.Name
+				order.Items[i].Brand = item.Brand
+				order.Items[i].RID = item.RID
+			}
+
+			if err := s.UpsertOrder(context.Background(), order); err != nil {
+				t.Fatalf("Expected order to be saved, got error: %v", err)
+			}
+			stored, err := s.GetOrderByID(context.Background(), order.OrderUID)
+			if err != nil {
+				t.Fatalf("Expected order to be found, got error: %v", err)
+			}
+			requireSameItems(t, order.Items, stored.Items)
+		})
+	}
+}
+
+func TestStorageSaveItemsTooLong(t *testing.T) {
+	s, pool := newTestStorage(t)
+
+	order := newValidOrder(newTestOrderUID(t, pool))
+	order.Items[0].Name = strings.Repeat("я", 101)
+
+	err := s.UpsertOrder(context.Background(), order)
+	if kind := customerrors.KindOf(err); kind != customerrors.KindPermanent {
+		t.Fatalf("Expected permanent error, got %s: %v", kind, err)
+	}
+	requireNotStored(t, s, order.OrderUID)
+}
+
+func TestStorageSaveItemsDuplicateNmID(t *testing.T) {
+	s, pool := newTestStorage(t)
+
+	order := newValidOrder(newTestOrderUID(t, pool))
+	duplicate := order.Items[0]
+	duplicate.ChrtID++
+	order.Items = append(order.Items, duplicate)
+
+	err := s.UpsertOrder(context.Background(), order)
+	if err == nil || customerrors.IsRetryable(err) {
+		t.Fatalf("Expected non-retryable error, got: %v", err)
+	}
+	requireNotStored(t, s, order.OrderUID)
+}
+
+func TestStorageUpsertOrdersIsolatesTooLong(t *testing.T) {
+	s, pool := newTestStorage(t)
+
+	good := newValidOrder(newTestOrderUID(t, pool))
+	bad := newValidOrder(newTestOrderUID(t, pool))
+	bad.Items[0].Brand = strings.Repeat("b", 101)
+
+	results := s.UpsertOrders(context.Background(), []models.Order{good, bad})
+	if results[0] != nil {
+		t.Errorf("Expected good order to be upserted, got error: %v", results[0])
+	}
+	if kind := customerrors.KindOf(results[1]); kind != customerrors.KindPermanent {
+		t.Errorf("Expected permanent error for bad order, got %s: %v", kind, results[1])
+	}
+
+	stored, err := s.GetOrderByID(context.Background(), good.OrderUID)
+	if err != nil {
+		t.Fatalf("Expected good order to be found, got error: %v", err)
+	}
+	requireSameItems(t, good.Items, stored.Items)
+	requireNotStored(t, s, bad.OrderUID)
+}
+
+func TestStorageUpsertOrdersAppliesVersionsInOrder(t *testing.T) {
+	s, pool := newTestStorage(t)
+	ctx := context.Background()
+
+	first := newValidOrder(newTestOrderUID(t, pool))
+	first.Version = 1
+	second := first
+	second.Version = 2
+	second.TrackNumber = first.TrackNumber + "2"
+
+	// a new order: the older version is inserted, then replaced by the newer one in the same transaction.
+	// One by one, the older version would be outdated
+	results := s.UpsertOrders(ctx, []models.Order{second, first})
+	for i, result := range results {
+		if result != nil {
+			t.Fatalf("Expected order %d of the batch to be upserted, got error: %v", i, result)
+		}
+	}
+	stored, err := s.GetOrderByID(ctx, first.OrderUID)
+	if err != nil {
+		t.Fatalf("Expected order to be found, got error: %v", err)
+	}
+	if stored.Version != 2 || stored.TrackNumber != second.TrackNumber {
+		t.Fatalf("Expected version 2 to be stored, got version %d with track number %s",
+			stored.Version, stored.TrackNumber)
+	}
+
+	// a stored order: a redelivery of the same version in the batch writes nothing
+	third := second
+	third.Version = 3
+	third.TrackNumber = first.TrackNumber + "3"
+	results = s.UpsertOrders(ctx, []models.Order{third, third})
+	if results[0] != nil || !errors.Is(results[1], customerrors.ErrOrderAlreadySaved) {
+		t.Fatalf("Expected version 3 to be upserted once and then already saved, got: %v", results)
+	}
+
+	var archivedVersions int
+	err = pool.QueryRow(ctx, "SELECT count(*) FROM order_service.order_history WHERE order_uid = $1",
+		first.OrderUID).Scan(&archivedVersions)
+	if err != nil {
+		t.Fatalf("Couldn't query order history: %v", err)
+	}
+	if archivedVersions != 2 {
+		t.Errorf("Expected versions 1 and 2 to be archived, got %d versions", archivedVersions)
+	}
+}
+
+func TestStorageUpsertNewOrderWritesEvent(t *testing.T) {
+	s, pool := newTestStorage(t)
+
+	order := newValidOrder(newTestOrderUID(t, pool))
+	if err := s.UpsertOrder(context.Background(), order); err != nil {
+		t.Fatalf("Expected order to be saved, got error: %v", err)
+	}
+	// a redelivery writes nothing
+	if err := s.UpsertOrder(context.Background(), order); !errors.Is(err, customerrors.ErrOrderAlreadySaved) {
+		t.Fatalf("Expected already saved error, got: %v", err)
+	}
+
+	var eventTypes []string
+	rows, err := pool.Query(context.Background(),
+		"SELECT event_type FROM order_service.order_events WHERE order_uid = $1 AND sent_at IS NULL", order.OrderUID)
+	if err != nil {
+		t.Fatalf("Couldn't query order events: %v", err)
+	}
+	for rows.Next() {
+		var eventType string
+		if err = rows.Scan(&eventType); err != nil {
+			t.Fatalf("Couldn't scan order event: %v", err)
+		}
+		eventTypes = append(eventTypes, eventType)
+	}
+	rows.Close()
+
+	if len(eventTypes) != 1 || eventTypes[0] != models.OrderEventTypeSaved {
+		t.Errorf("Expected a single %s event, got %v", models.OrderEventTypeSaved, eventTypes)
+	}
+}
+
+func TestStorageUpsertNewOrderConcurrentVersions(t *testing.T) {
+	s, pool := newTestStorage(t)
+
+	// a single round may not hit the race, the insert conflict needs both upserts to see no stored order
+	for round := 0; round < 20; round++ {
+		first := newValidOrder(newTestOrderUID(t, pool))
+		first.Version = 1
+		second := first
+		second.Version = 2
+		second.TrackNumber = first.TrackNumber + "2"
+
+		var wg sync.WaitGroup
+		results := make([]error, 2)
+		for i, order := range []models.Order{first, second} {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				results[i] = s.UpsertOrder(context.Background(), order)
+			}()
+		}
+		wg.Wait()
+
+		if results[1] != nil {
+			t.Fatalf("Expected version 2 to be upserted, got error: %v", results[1])
+		}
+		// version 1 is either replaced by version 2 or is outdated by it, it never conflicts
+		if results[0] != nil && !errors.Is(results[0], customerrors.ErrOrderOutdated) {
+			t.Fatalf("Expected version 1 to be upserted or outdated, got error: %v", results[0])
+		}
+		stored, err := s.GetOrderByID(context.Background(), first.OrderUID)
+		if err != nil {
+			t.Fatalf("Expected order to be found, got error: %v", err)
+		}
+		if stored.Version != 2 || stored.TrackNumber != second.TrackNumber {
+			t.Fatalf("Expected version 2 to be stored, got version %d with track number %s",
+				stored.Version, stored.TrackNumber)
+		}
+	}
+}
+
+func TestStorageChangeOrderStatus(t *testing.T) {
+	s, pool := newTestStorage(t)
+	ctx := context.Background()
+
+	order := newValidOrder(newTestOrderUID(t, pool))
+	if err := s.UpsertOrder(ctx, order); err != nil {
+		t.Fatalf("Expected order to be saved, got error: %v", err)
+	}
+
+	if _, err := s.ChangeOrderStatus(ctx, order.OrderUID, 0, models.OrderStatusPaid, "paid with card"); err != nil {
+		t.Fatalf("Expected status to be changed, got error: %v", err)
+	}
+	_, err := s.ChangeOrderStatus(ctx, order.OrderUID, 0, models.OrderStatusDelivered, "")
+	if !errors.Is(err, customerrors.ErrIllegalStatusTransition) {
+		t.Fatalf("Expected illegal transition error, got: %v", err)
+	}
+	_, err = s.ChangeOrderStatus(ctx, "no-such-order", 0, models.OrderStatusPaid, "")
+	if !errors.Is(err, customerrors.ErrOrderNotFound) {
+		t.Fatalf("Expected not found error, got: %v", err)
+	}
+
+	// an item has its own lifecycle, it starts as created whatever the order status is
+	nmID := order.Items[0].NmID
+	if _, err = s.ChangeOrderStatus(ctx, order.OrderUID, nmID, models.OrderStatusCancelled, "out of stock"); err != nil {
+		t.Fatalf("Expected item status to be changed, got error: %v", err)
+	}
+	_, err = s.ChangeOrderStatus(ctx, order.OrderUID, nmID, models.OrderStatusPaid, "")
+	if !errors.Is(err, customerrors.ErrIllegalStatusTransition) {
+		t.Fatalf("Expected illegal transition error of the cancelled item, got: %v", err)
+	}
+	_, err = s.ChangeOrderStatus(ctx, order.OrderUID, nmID+1, models.OrderStatusPaid, "")
+	if !errors.Is(err, customerrors.ErrOrderItemNotFound) {
+		t.Fatalf("Expected item not found error, got: %v", err)
+	}
+
+	stored, err := s.GetOrderByID(ctx, order.OrderUID)
+	if err != nil {
+		t.Fatalf("Expected order to be read, got error: %v", err)
+	}
+	timeline := stored.StatusTimeline
+	if len(timeline) != 2 || timeline[0].Status != models.OrderStatusCreated || timeline[1].Status != models.OrderStatusPaid {
+		t.Fatalf("Expected created -> paid timeline, got %+v", timeline)
+	}
+	if timeline[1].Comment != "paid with card" {
+		t.Errorf("Expected comment to be stored, got %q", timeline[1].Comment)
+	}
+	itemTimeline := stored.Items[0].StatusTimeline
+	if len(itemTimeline) != 1 || itemTimeline[0].Status != models.OrderStatusCancelled || itemTimeline[0].NmID != nmID {
+		t.Errorf("Expected cancelled item timeline, got %+v", itemTimeline)
+	}
+
+	// every change is published
+	var changedEvents int
+	err = pool.QueryRow(ctx, "SELECT count(*) FROM order_service.order_events WHERE order_uid = $1 AND event_type = $2",
+		order.OrderUID, models.OrderEventTypeStatusChanged).Scan(&changedEvents)
+	if err != nil {
+		t.Fatalf("Couldn't query order events: %v", err)
+	}
+	if changedEvents != 2 {
+		t.Errorf("Expected 2 %s events, got %d", models.OrderEventTypeStatusChanged, changedEvents)
+	}
+}
+
+func TestValidateOrderDuplicateNmID(t *testing.T) {
+	order := newValidOrder("b563feb7b2b84b6test")
+	duplicate := order.Items[0]
+	duplicate.ChrtID++
+	order.Items = append(order.Items, duplicate)
+
+	err := validators.ValidateOrder(order)
+	if err == nil || !strings.Contains(err.Error(), "nm_id") {
+		t.Fatalf("Expected duplicate nm_id error, got: %v", err)
+	}
+}
+
+func TestStorageDeleteOrder(t *testing.T) {
+	s, pool := newTestStorage(t)
+	ctx := context.Background()
+
+	order := newValidOrder(newTestOrderUID(t, pool))
+	if err := s.UpsertOrder(ctx, order); err != nil {
+		t.Fatalf("Expected order to be saved, got error: %v", err)
+	}
+	if _, err := s.ChangeOrderStatus(ctx, order.OrderUID, 0, models.OrderStatusPaid, ""); err != nil {
+		t.Fatalf("Expected status to be changed, got error: %v", err)
+	}
+	// the first version is archived
+	order.Version++
+	if err := s.UpsertOrder(ctx, order); err != nil {
+		t.Fatalf("Expected order to be replaced, got error: %v", err)
+	}
+
+	if err := s.DeleteOrder(ctx, order.OrderUID); err != nil {
+		t.Fatalf("Expected order to be deleted, got error: %v", err)
+	}
+	requireNotStored(t, s, order.OrderUID)
+	if err := s.DeleteOrder(ctx, order.OrderUID); !errors.Is(err, customerrors.ErrOrderNotFound) {
+		t.Fatalf("Expected not found error, got: %v", err)
+	}
+
+	var statusEvents int
+	err := pool.QueryRow(ctx, "SELECT count(*) FROM order_service.order_status_events WHERE order_uid = $1",
+		order.OrderUID).Scan(&statusEvents)
+	if err != nil {
+		t.Fatalf("Couldn't query order status events: %v", err)
+	}
+	if statusEvents != 0 {
+		t.Errorf("Expected the timeline to be deleted, got %d events", statusEvents)
+	}
+
+	var archivedVersions int
+	err = pool.QueryRow(ctx, "SELECT count(*) FROM order_service.order_history WHERE order_uid = $1",
+		order.OrderUID).Scan(&archivedVersions)
+	if err != nil {
+		t.Fatalf("Couldn't query order history: %v", err)
+	}
+	if archivedVersions != 1 {
+		t.Errorf("Expected the archived version to outlive the order, got %d versions", archivedVersions)
+	}
+
+	var deletedEvents int
+	err = pool.QueryRow(ctx, "SELECT count(*) FROM order_service.order_events WHERE order_uid = $1 AND event_type = $2",
+		order.OrderUID, models.OrderEventTypeDeleted).Scan(&deletedEvents)
+	if err != nil {
+		t.Fatalf("Couldn't query order events: %v", err)
+	}
+	if deletedEvents != 1 {
+		t.Errorf("Expected a single %s event, got %d", models.OrderEventTypeDeleted, deletedEvents)
+	}
+}
+
+func TestStorageClaimOrderEvents(t *testing.T) {
+	s, pool := newTestStorage(t)
+	events := storage.NewOrderEventsStoragePostgres(pool)
+	ctx := context.Background()
+
+	order := newValidOrder(newTestOrderUID(t, pool))
+	t.Cleanup(func() {
+		_, err := pool.Exec(context.Background(),
+			"UPDATE order_service.order_events SET sent_at = CURRENT_TIMESTAMP WHERE order_uid = $1", order.OrderUID)
+		if err != nil {
+			t.Errorf("Couldn't mark test order events sent %s: %v", order.OrderUID, err)
+		}
+	})
+	if err := s.UpsertOrder(ctx, order); err != nil {
+		t.Fatalf("Expected order to be saved, got error: %v", err)
+	}
+
+	// other tests might have left unsent events, all of them are claimed
+	first, err := events.ClaimUnsentOrderEvents(ctx, 100000, time.Minute)
+	if err != nil {
+		t.Fatalf("Expected events to be claimed, got error: %v", err)
+	}
+	claimed := make(map[int64]bool, len(first))
+	for _, event := range first {
+		claimed[event.ID] = true
+	}
+
+	// a new version of the order is written after its first event, it waits for the leased one
+	order.Version++
+	order.TrackNumber = "CLAIMED"
+	if err = s.UpsertOrder(ctx, order); err != nil {
+		t.Fatalf("Expected order to be updated, got error: %v", err)
+	}
+
+	second, err := events.ClaimUnsentOrderEvents(ctx, 100000, time.Minute)
+	if err != nil {
+		t.Fatalf("Expected events to be claimed, got error: %v", err)
+	}
+	for _, event := range second {
+		if claimed[event.ID] {
+			t.Errorf("Expected leased event %d not to be claimed again", event.ID)
+		}
+		if event.OrderUID == order.OrderUID {
+			t.Errorf("Expected event %d to wait for the leased event of its order", event.ID)
+		}
+	}
+}
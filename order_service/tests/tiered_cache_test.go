@@ -0,0 +1,416 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"github.com/alicebob/miniredis/v2"
+	"order_service/internal/models"
+	"order_service/internal/service"
+	"order_service/pkg/pkgports"
+	"order_service/pkg/pkgports/adapters/cache/lru"
+	"order_service/pkg/pkgports/adapters/cache/rediscache"
+	"order_service/pkg/pkgports/adapters/cache/tiered"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// newTestTwoTierCache returns an LRU of l1Capacity values over miniredis, with both tiers
+func newTestTwoTierCache(t *testing.T, l1Capacity int, l1TTL time.Duration) (
+	context.Context, *miniredis.Miniredis, *tiered.CacheTwoTier[int, string],
+	*lru.CacheLRUInMemory[int, string], *rediscache.CacheRedis[int, string],
+) {
+	t.Helper()
+
+	ctx, server, client := newTestRedis(t)
+	l1 := lru.NewCacheLRUInMemory[int, string](l1Capacity)
+	l2 := rediscache.NewCacheRedis[int, string](client, intKeyCodec{}, rawCodec{}, "tiered:", time.Minute)
+	return ctx, server, tiered.NewCacheTwoTier[int, string](l1, l2, nil, l1TTL), l1, l2
+}
+
+// failingL1 is an L1 whose Delete and Clear fail, the rest is of the LRU
+type failingL1 struct {
+	*lru.CacheLRUInMemory[int, string]
+}
+
+var errFailingL1 = errors.New("L1 is broken")
+
+func (failingL1) Delete(context.Context, int) (bool, error) {
+	return false, errFailingL1
+}
+
+func (failingL1) Clear(context.Context) error {
+	return errFailingL1
+}
+
+func TestTwoTierCacheReadThrough(t *testing.T) {
+	ctx, _, cache, l1, l2 := newTestTwoTierCache(t, 10, time.Minute)
+
+	// a value set by another instance is only in L2
+	_ = l2.Set(ctx, 1, "one")
+
+	for i := 0; i < 3; i++ {
+		if value, found, err := cache.Get(ctx, 1); err != nil || !found || value != "one" {
+			t.Fatalf("Expected key 1 to be one, got %q, found %v, error: %v", value, found, err)
+		}
+	}
+	if _, found, _ := l1.Peek(ctx, 1); !found {
+		t.Error("Expected key 1 to be promoted into L1")
+	}
+	if _, found, _ := cache.Get(ctx, 2); found {
+		t.Error("Unknown key shouldn't be found")
+	}
+
+	// the first read is served by L2, the others by L1
+	tiers := cache.TierStats()
+	if tiers[0].Hits != 2 || tiers[0].Misses != 2 || tiers[0].Sets != 1 {
+		t.Errorf("Expected 2 hits, 2 misses and a promotion in L1, got %+v", tiers[0])
+	}
+	if tiers[1].Hits != 1 || tiers[1].Misses != 1 {
+		t.Errorf("Expected a hit and a miss in L2, got %+v", tiers[1])
+	}
+	if stats := cache.Stats(); stats.Hits != 3 || stats.Misses != 1 {
+		t.Errorf("Expected 3 hits and a miss overall, got %+v", stats)
+	}
+}
+
+func TestTwoTierCacheWriteThroughTTL(t *testing.T) {
+	ctx, server, cache, l1, _ := newTestTwoTierCache(t, 10, 20*time.Millisecond)
+
+	_ = cache.Set(ctx, 1, "default")
+	_ = cache.SetWithTTL(ctx, 2, "own", time.Hour)
+	_ = cache.SetWithTTL(ctx, 3, "short", 5*time.Millisecond)
+
+	// L2 has its own TTLs
+	if ttl := server.TTL("tiered:1"); ttl != time.Minute {
+		t.Errorf("Expected default L2 TTL of a minute, got %v", ttl)
+	}
+	if ttl := server.TTL("tiered:2"); ttl != time.Hour {
+		t.Errorf("Expected L2 TTL of an hour, got %v", ttl)
+	}
+	if _, found, _ := l1.Peek(ctx, 1); !found {
+		t.Fatal("Expected key 1 to be written into L1")
+	}
+
+	// L1 keeps values for its own TTL at most, 3 expires even sooner
+	time.Sleep(10 * time.Millisecond)
+	if _, found, _ := l1.Peek(ctx, 3); found {
+		t.Error("Expected key 3 to expire in L1 with its own TTL")
+	}
+	time.Sleep(20 * time.Millisecond)
+	for _, key := range []int{1, 2} {
+		if _, found, _ := l1.Peek(ctx, key); found {
+			t.Errorf("Expected key %d to expire in L1", key)
+		}
+		if _, found, _ := cache.Get(ctx, key); !found {
+			t.Errorf("Expected key %d to be read from L2", key)
+		}
+	}
+}
+
+func TestTwoTierCacheCapacities(t *testing.T) {
+	ctx, _, cache, l1, l2 := newTestTwoTierCache(t, 2, time.Minute)
+
+	for key := 0; key < 5; key++ {
+		_ = cache.Set(ctx, key, "value")
+	}
+
+	if l1.GetKeysAmount() != 2 || l2.GetKeysAmount() != 5 {
+		t.Errorf("Expected 2 keys in L1 and 5 in L2, got %d and %d", l1.GetKeysAmount(), l2.GetKeysAmount())
+	}
+	keys := cache.GetKeys()
+	slices.Sort(keys)
+	if !slices.Equal(keys, []int{0, 1, 2, 3, 4}) {
+		t.Errorf("Expected keys 0..4 without duplicates, got %v", keys)
+	}
+
+	// redis L2 doesn't count its size
+	expected := pkgports.CacheStats{Sets: 5, Evictions: 3}
+	if stats := cache.Stats(); stats != expected {
+		t.Errorf("Expected stats %+v, got %+v", expected, stats)
+	}
+	if tiers := cache.TierStats(); tiers[0].Size != 2 || tiers[0].Capacity != 2 || tiers[1].Sets != 5 {
+		t.Errorf("Expected 2 of 2 values in L1 and 5 sets of L2, got %+v", tiers)
+	}
+}
+
+func TestTwoTierCacheDeleteClear(t *testing.T) {
+	ctx, _, cache, l1, l2 := newTestTwoTierCache(t, 10, time.Minute)
+
+	_ = cache.Set(ctx, 1, "one")
+	_ = cache.Set(ctx, 2, "two")
+	// L2 might evict a value L1 still has
+	_, _ = l2.Delete(ctx, 2)
+
+	if deleted, err := cache.Delete(ctx, 1); err != nil || !deleted {
+		t.Errorf("Expected key 1 to be deleted, got deleted %v, error: %v", deleted, err)
+	}
+	if deleted, _ := cache.Delete(ctx, 2); !deleted {
+		t.Error("Expected key 2 to be deleted from L1")
+	}
+	if _, found, _ := cache.Peek(ctx, 1); found {
+		t.Error("Expected key 1 to be deleted from both tiers")
+	}
+
+	_ = cache.Set(ctx, 3, "three")
+	if err := cache.Clear(ctx); err != nil {
+		t.Fatalf("Expected cache to be cleared, got error: %v", err)
+	}
+	if l1.GetKeysAmount() != 0 || l2.GetKeysAmount() != 0 {
+		t.Errorf("Expected both tiers to be empty, got %d and %d keys", l1.GetKeysAmount(), l2.GetKeysAmount())
+	}
+}
+
+func TestTwoTierCacheDeleteClearTryBothTiers(t *testing.T) {
+	ctx, server, client := newTestRedis(t)
+	l2 := rediscache.NewCacheRedis[int, string](client, intKeyCodec{}, rawCodec{}, "tiered:", time.Minute)
+	cache := tiered.NewCacheTwoTier[int, string](
+		failingL1{lru.NewCacheLRUInMemory[int, string](10)}, l2, nil, time.Minute,
+	)
+
+	_ = cache.Set(ctx, 1, "one")
+	if _, err := cache.Delete(ctx, 1); !errors.Is(err, errFailingL1) {
+		t.Errorf("Expected L1 error, got %v", err)
+	}
+	if _, found, _ := l2.Peek(ctx, 1); found {
+		t.Error("Expected key 1 to be deleted from L2 despite the L1 error")
+	}
+
+	_ = cache.Set(ctx, 2, "two")
+	if _, err := cache.Invalidate(ctx, 2); !errors.Is(err, errFailingL1) {
+		t.Errorf("Expected L1 error, got %v", err)
+	}
+	if _, found, _ := l2.Peek(ctx, 2); found {
+		t.Error("Expected key 2 to be invalidated in L2 despite the L1 error")
+	}
+
+	_ = cache.Set(ctx, 3, "three")
+	if err := cache.Clear(ctx); !errors.Is(err, errFailingL1) {
+		t.Errorf("Expected L1 error, got %v", err)
+	}
+	if l2.GetKeysAmount() != 0 {
+		t.Errorf("Expected L2 to be cleared despite the L1 error, got %d keys", l2.GetKeysAmount())
+	}
+
+	// errors of both tiers are returned
+	server.SetError("redis is down")
+	_, err := cache.Delete(ctx, 3)
+	if !errors.Is(err, errFailingL1) || err == nil || !strings.Contains(err.Error(), "L2") {
+		t.Errorf("Expected both L1 and L2 errors, got %v", err)
+	}
+}
+
+func TestTwoTierCacheGenerations(t *testing.T) {
+	ctx, _, cache, l1, _ := newTestTwoTierCache(t, 10, time.Minute)
+
+	testGenerationCache(t, ctx, cache)
+
+	if value, found, _ := l1.Peek(ctx, 1); !found || value != "fresh" {
+		t.Errorf("Expected the value of the new generation in L1, got %q, found %v", value, found)
+	}
+	if _, found, _ := l1.Peek(ctx, 2); found {
+		t.Error("Expected the value of the previous generation not to be in L1")
+	}
+}
+
+func TestTwoTierCacheInvalidationsBetweenInstances(t *testing.T) {
+	ctx, server, client := newTestRedis(t)
+	newInstance := func() (*tiered.CacheTwoTier[int, string], *lru.CacheLRUInMemory[int, string]) {
+		l1 := lru.NewCacheLRUInMemory[int, string](10)
+		l2 := rediscache.NewCacheRedis[int, string](client, intKeyCodec{}, rawCodec{}, "tiered:", time.Minute)
+		invalidations := rediscache.NewInvalidations[int](client, intKeyCodec{}, "tiered:invalidations")
+		return tiered.NewCacheTwoTier[int, string](l1, l2, invalidations, time.Minute), l1
+	}
+	first, _ := newInstance()
+	second, secondL1 := newInstance()
+	// values set straight in L2 aren't published, so they don't drop promotions made after them
+	l2 := rediscache.NewCacheRedis[int, string](client, intKeyCodec{}, rawCodec{}, "tiered:", time.Minute)
+
+	ctx, cancel := context.WithCancel(ctx)
+	var wg sync.WaitGroup
+	for _, instance := range []*tiered.CacheTwoTier[int, string]{first, second} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := instance.StartInvalidations(ctx); err != nil {
+				t.Errorf("Expected invalidations to be received, got error: %v", err)
+			}
+		}()
+	}
+	defer func() {
+		cancel()
+		wg.Wait()
+	}()
+	waitSubscribers(t, server, "tiered:invalidations", 2)
+
+	// waitDropped waits for the second instance to drop the key from its L1
+	waitDropped := func(key int) {
+		t.Helper()
+
+		deadline := time.After(5 * time.Second)
+		for {
+			if _, found, _ := secondL1.Peek(ctx, key); !found {
+				return
+			}
+			select {
+			case <-deadline:
+				t.Fatalf("Expected key %d to be dropped from L1 of the other instance", key)
+			case <-time.After(time.Millisecond):
+			}
+		}
+	}
+
+	for key, change := range map[int]func(key int){
+		1: func(key int) { _ = first.Set(ctx, key, "new") },
+		2: func(key int) { _, _ = first.Delete(ctx, key) },
+		3: func(key int) { _, _ = first.Invalidate(ctx, key) },
+	} {
+		// the second instance has the value in L1
+		_ = l2.Set(ctx, key, "old")
+		if _, found, _ := second.Get(ctx, key); !found {
+			t.Fatalf("Expected key %d to be found", key)
+		}
+		if _, found, _ := secondL1.Peek(ctx, key); !found {
+			t.Fatalf("Expected key %d to be promoted into L1", key)
+		}
+
+		change(key)
+		waitDropped(key)
+	}
+	if value, _, _ := second.Get(ctx, 1); value != "new" {
+		t.Errorf("Expected the new value of key 1, got %q", value)
+	}
+
+	_ = l2.Set(ctx, 4, "four")
+	if _, found, _ := second.Get(ctx, 4); !found {
+		t.Fatal("Expected key 4 to be found")
+	}
+	_ = first.Clear(ctx)
+	waitDropped(4)
+}
+
+// waitSubscribers waits for the amount of subscribers of a miniredis channel
+func waitSubscribers(t *testing.T, server *miniredis.Miniredis, channel string, amount int) {
+	t.Helper()
+
+	deadline := time.After(5 * time.Second)
+	for server.PubSubNumSub(channel)[channel] < amount {
+		select {
+		case <-deadline:
+			t.Fatalf("Expected %d subscribers of %s", amount, channel)
+		case <-time.After(time.Millisecond):
+		}
+	}
+}
+
+func TestTwoTierCacheL2Error(t *testing.T) {
+	ctx, server, cache, l1, _ := newTestTwoTierCache(t, 10, time.Minute)
+
+	_ = cache.Set(ctx, 1, "old")
+	server.SetError("redis is down")
+
+	// L1 mustn't keep a value L2 doesn't have
+	if err := cache.Set(ctx, 1, "new"); err == nil {
+		t.Fatal("Expected L2 error")
+	}
+	if _, found, _ := l1.Peek(ctx, 1); found {
+		t.Error("Expected the old value to be deleted from L1")
+	}
+	if _, _, err := cache.Get(ctx, 1); err == nil {
+		t.Error("Expected L2 error on L1 miss")
+	}
+
+	// L1 hits don't need L2
+	_ = l1.Set(ctx, 2, "two")
+	if value, found, err := cache.Get(ctx, 2); err != nil || !found || value != "two" {
+		t.Errorf("Expected key 2 to be read from L1, got %q, found %v, error: %v", value, found, err)
+	}
+}
+
+// invalidatedOnSetL2 is an L2 that calls afterSet after every Set, e.g. a concurrent invalidation of the key
+type invalidatedOnSetL2 struct {
+	*rediscache.CacheRedis[int, string]
+	afterSet func(ctx context.Context, key int)
+}
+
+func (l2 invalidatedOnSetL2) Set(ctx context.Context, key int, value string) error {
+	err := l2.CacheRedis.Set(ctx, key, value)
+	l2.afterSet(ctx, key)
+	return err
+}
+
+func TestTwoTierCacheWriteSkipsL1InvalidatedMeanwhile(t *testing.T) {
+	ctx, _, client := newTestRedis(t)
+	l1 := lru.NewCacheLRUInMemory[int, string](10)
+	var cache *tiered.CacheTwoTier[int, string]
+	l2 := invalidatedOnSetL2{
+		CacheRedis: rediscache.NewCacheRedis[int, string](client, intKeyCodec{}, rawCodec{}, "tiered:", time.Minute),
+		// the key is invalidated between the L2 write and the L1 one
+		afterSet: func(ctx context.Context, key int) {
+			_, _ = cache.Invalidate(ctx, key)
+		},
+	}
+	cache = tiered.NewCacheTwoTier[int, string](l1, l2, nil, time.Minute)
+
+	if err := cache.Set(ctx, 1, "one"); err != nil {
+		t.Fatalf("Expected set to succeed, got error: %v", err)
+	}
+	// L1 mustn't keep a value that's invalidated in L2
+	if _, found, _ := l1.Peek(ctx, 1); found {
+		t.Error("Expected the invalidated value not to be written into L1")
+	}
+}
+
+func TestOrderServiceGetCacheStatsTiers(t *testing.T) {
+	ctx, _, client := newTestRedis(t)
+	cache := tiered.NewCacheTwoTier[string, models.Order](
+		lru.NewCacheLRUInMemory[string, models.Order](10),
+		rediscache.NewCacheRedis[string, models.Order](
+			client, rediscache.StringKeyCodec{}, rediscache.JSONCodec[models.Order]{}, "orders:", time.Minute,
+		),
+		nil,
+		time.Minute,
+	)
+	notFound := lru.NewCacheLRUInMemoryWithTTL[string, struct{}](10, time.Minute, 0)
+	orderService := service.NewOrderService(newFakeOrderStorage(), cache, notFound)
+
+	_ = cache.Set(ctx, "first", newValidOrder("first"))
+	_ = cache.Set(ctx, "second", newValidOrder("second"))
+	_, _ = orderService.GetOrder(ctx, "first")
+
+	stats := orderService.GetCacheStats(ctx)
+	if len(stats.Tiers) != 2 {
+		t.Fatalf("Expected stats of 2 tiers, got %+v", stats.Tiers)
+	}
+	if stats.Hits != 1 || stats.Tiers[0].Hits != 1 || stats.Tiers[1].Hits != 0 {
+		t.Errorf("Expected an L1 hit, got %+v", stats)
+	}
+	if stats.Tiers[0].Capacity != 10 || stats.Tiers[0].Size != 2 || stats.Tiers[1].Sets != 2 {
+		t.Errorf("Expected L1 with 2 of 10 values and 2 sets of L2, got %+v", stats.Tiers)
+	}
+	// the order of L1
+	if stats.MostUsedKey != "first" || stats.LeastUsedKey != "second" {
+		t.Errorf("Expected first to be the most used key and second the least used one, got %q and %q",
+			stats.MostUsedKey, stats.LeastUsedKey)
+	}
+}
+
+func TestTwoTierCacheRecencyWithoutRecencyL1(t *testing.T) {
+	ctx, _, client := newTestRedis(t)
+	l2 := rediscache.NewCacheRedis[int, string](client, intKeyCodec{}, rawCodec{}, "tiered:", time.Minute)
+	// redis doesn't know the order of its values
+	cache := tiered.NewCacheTwoTier[int, string](
+		rediscache.NewCacheRedis[int, string](client, intKeyCodec{}, rawCodec{}, "l1:", time.Minute),
+		l2, nil, time.Minute,
+	)
+
+	_ = cache.Set(ctx, 1, "one")
+	if _, err := cache.MostUsedKey(); !errors.Is(err, tiered.ErrNoRecency) {
+		t.Errorf("Expected ErrNoRecency for most used key, got: %v", err)
+	}
+	if _, err := cache.LeastUsedKey(); !errors.Is(err, tiered.ErrNoRecency) {
+		t.Errorf("Expected ErrNoRecency for least used key, got: %v", err)
+	}
+}